@@ -0,0 +1,255 @@
+// Package backup 管理汉化前的文件备份。
+//
+// 每次汉化在备份根目录下创建一个以时间和分组命名的子目录 (如
+// 2026-01-30_14-30-00_antigravity)，其中保存原始文件和 backup_record.json 备份记录。
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RecordFileName 备份记录文件名
+const RecordFileName = "backup_record.json"
+
+// Record 备份记录
+type Record struct {
+	Timestamp   string            `json:"timestamp"`
+	InstallPath string            `json:"install_path"`
+	BackupType  string            `json:"backup_type"` // 备份分组: "antigravity" 或 "continue"
+	Files       map[string]string `json:"files"`       // 原始路径 -> 备份文件名
+	Reverted    []RevertedRule    `json:"reverted,omitempty"`
+	Bilingual   *Bilingual        `json:"bilingual,omitempty"` // 文件中译文的双语显示设置，旧版本的记录中没有
+}
+
+// Bilingual 汉化时使用的双语显示设置 (见 translator.BilingualOptions)，Style 为 "off" 表示只显示译文
+// 汉化的备份记下本次写入的译文的设置；untranslate、revert 的备份记下备份中已汉化的文件的设置
+type Bilingual struct {
+	Style      string   `json:"style"`
+	MaxLength  int      `json:"max_length,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+}
+
+// RevertedRule 汉化后用 revert 撤销的规则 (备份中的原始文件不变，restore 仍还原为原版)
+type RevertedRule struct {
+	Rule      string `json:"rule"` // 规则 ID，如 "chat/normal/3fa2c1d0"
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Path      string `json:"path"`
+	Count     int    `json:"count"` // 撤销的替换次数
+	Timestamp string `json:"timestamp"`
+}
+
+// Store 备份根目录
+type Store struct {
+	Root string
+}
+
+// Backup 一次备份 (备份根目录下的一个子目录)
+type Backup struct {
+	ID     string // 子目录名
+	Dir    string
+	Record Record
+}
+
+// Create 为某个分组的根目录创建新的备份目录，同一秒内多次备份时追加序号
+// 备份记录需要调用 Save 才会写入磁盘
+func (s Store) Create(group, installPath string) (*Backup, error) {
+	s.MigrateLegacy()
+	if err := os.MkdirAll(s.Root, 0755); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	timestamp := now.Format("2006-01-02_15-04-05")
+	name := fmt.Sprintf("%s_%s", timestamp, group)
+	for i := 2; ; i++ {
+		dir := filepath.Join(s.Root, name)
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return &Backup{
+				ID:  name,
+				Dir: dir,
+				Record: Record{
+					Timestamp:   now.Format("2006-01-02 15:04:05"),
+					InstallPath: installPath,
+					BackupType:  group,
+					Files:       make(map[string]string),
+				},
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		name = fmt.Sprintf("%s_%s_%d", timestamp, group, i)
+	}
+}
+
+// uniqueName 使用原始文件名，不同目标的文件同名时追加序号
+func (b *Backup) uniqueName(filePath string) string {
+	base := filepath.Base(filePath)
+	fileName := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(b.Dir, fileName)); os.IsNotExist(err) {
+			return fileName
+		}
+		ext := filepath.Ext(base)
+		fileName = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(base, ext), i, ext)
+	}
+}
+
+// AddContent 将已读入内存的原文写入备份目录并登记到记录中，返回备份文件名
+func (b *Backup) AddContent(filePath string, content []byte) (string, error) {
+	fileName := b.uniqueName(filePath)
+	if err := os.WriteFile(filepath.Join(b.Dir, fileName), content, 0644); err != nil {
+		return "", err
+	}
+	b.Record.Files[filePath] = fileName
+	return fileName, nil
+}
+
+// AddFile 备份磁盘上的文件
+func (b *Backup) AddFile(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return b.AddContent(filePath, content)
+}
+
+// Save 写入备份记录
+func (b *Backup) Save() error {
+	data, err := json.MarshalIndent(b.Record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(b.Dir, RecordFileName), data, 0644)
+}
+
+// Discard 删除整个备份目录
+func (b *Backup) Discard() error {
+	return os.RemoveAll(b.Dir)
+}
+
+// OriginalContent 返回备份中某个文件的原始内容，备份中没有该文件时 ok 为 false
+func (b *Backup) OriginalContent(filePath string) (content []byte, ok bool, err error) {
+	name, found := b.Record.Files[filePath]
+	if !found {
+		abs, _ := filepath.Abs(filePath)
+		for originalPath, n := range b.Record.Files {
+			if p, _ := filepath.Abs(originalPath); p == abs {
+				name, found = n, true
+				break
+			}
+		}
+	}
+	if !found {
+		return nil, false, nil
+	}
+	content, err = os.ReadFile(filepath.Join(b.Dir, name))
+	return content, true, err
+}
+
+// Open 读取备份目录中的备份记录
+func Open(dir string) (*Backup, error) {
+	content, err := os.ReadFile(filepath.Join(dir, RecordFileName))
+	if err != nil {
+		return nil, err
+	}
+	var record Record
+	if err := json.Unmarshal(content, &record); err != nil {
+		return nil, err
+	}
+	return &Backup{ID: filepath.Base(dir), Dir: dir, Record: record}, nil
+}
+
+// List 列出备份根目录中所有带备份记录的备份，按时间倒序排列
+// 根目录不存在时返回空列表
+func (s Store) List(ctx context.Context) ([]*Backup, error) {
+	entries, err := os.ReadDir(s.Root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []*Backup
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !entry.IsDir() {
+			continue
+		}
+
+		b, err := Open(filepath.Join(s.Root, entry.Name()))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].ID > backups[j].ID
+	})
+	return backups, nil
+}
+
+// ========================================
+// 还原
+// ========================================
+
+// 还原失败的原因
+var (
+	ErrBackupFileMissing = errors.New("备份文件不存在")
+	ErrReadBackup        = errors.New("读取备份失败")
+	ErrWriteOriginal     = errors.New("还原失败")
+)
+
+// RestoredFile 单个文件的还原结果，Err 可用 errors.Is 与上面的错误比较
+type RestoredFile struct {
+	Path   string
+	Backup string
+	Err    error
+}
+
+// Restore 将备份中的文件写回原始位置 (按原始路径排序)
+func (b *Backup) Restore(ctx context.Context) ([]RestoredFile, error) {
+	paths := make([]string, 0, len(b.Record.Files))
+	for originalPath := range b.Record.Files {
+		paths = append(paths, originalPath)
+	}
+	sort.Strings(paths)
+
+	var results []RestoredFile
+	for _, originalPath := range paths {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		backupFileName := b.Record.Files[originalPath]
+		rf := RestoredFile{Path: originalPath, Backup: backupFileName}
+		backupFilePath := filepath.Join(b.Dir, backupFileName)
+
+		content, err := os.ReadFile(backupFilePath)
+		switch {
+		case os.IsNotExist(err):
+			rf.Err = fmt.Errorf("%w: %s", ErrBackupFileMissing, backupFileName)
+		case err != nil:
+			rf.Err = fmt.Errorf("%w: %v", ErrReadBackup, err)
+		default:
+			if err := os.WriteFile(originalPath, content, 0644); err != nil {
+				rf.Err = fmt.Errorf("%w: %v", ErrWriteOriginal, err)
+			}
+		}
+		results = append(results, rf)
+	}
+	return results, nil
+}
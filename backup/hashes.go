@@ -0,0 +1,114 @@
+package backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ========================================
+// 原版文件哈希记录
+// ========================================
+//
+// 汉化时把原版文件的 SHA-256 记录在配置目录下 (与备份目录分开保存)，备份丢失后用反向规则恢复的文件
+// 可以与记录对比，确认是否与原版完全一致。同一路径只保留最近一次汉化前的原版哈希。
+// 同时记录最近一次汉化结果的哈希，再次汉化同一文件时不必重新判断它是否为原版。
+
+// FileHash 一个原版文件的哈希
+type FileHash struct {
+	SHA256    string `json:"sha256"`
+	Size      int    `json:"size"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Hashes 原版文件哈希记录: 文件的绝对路径 -> 哈希
+type Hashes struct {
+	Files      map[string]FileHash `json:"files"`
+	Translated map[string]string   `json:"translated,omitempty"` // 文件的绝对路径 -> 最近一次汉化结果的 SHA-256
+}
+
+// HashContent 返回内容的 SHA-256 (十六进制)
+func HashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadHashes 读取哈希记录，文件不存在时返回空记录
+func LoadHashes(path string) (*Hashes, error) {
+	h := &Hashes{Files: make(map[string]FileHash)}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(content, h); err != nil {
+		return &Hashes{Files: make(map[string]FileHash)}, err
+	}
+	if h.Files == nil {
+		h.Files = make(map[string]FileHash)
+	}
+	return h, nil
+}
+
+// Save 写入哈希记录
+func (h *Hashes) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Record 记录文件的原版内容
+func (h *Hashes) Record(filePath string, content []byte) {
+	h.Files[hashKey(filePath)] = FileHash{SHA256: HashContent(content), Size: len(content), Timestamp: time.Now().Format("2006-01-02 15:04:05")}
+}
+
+// RecordTranslated 记录文件最近一次汉化的结果
+func (h *Hashes) RecordTranslated(filePath string, content []byte) {
+	if h.Translated == nil {
+		h.Translated = make(map[string]string)
+	}
+	h.Translated[hashKey(filePath)] = HashContent(content)
+}
+
+// Known 判断内容是否为记录中该文件的原版 (pristine 为 true) 或最近一次的汉化结果 (pristine 为 false)，
+// 两者都不是时 ok 为 false；hash 为内容的 SHA-256
+func (h *Hashes) Known(filePath, hash string) (pristine, ok bool) {
+	if h == nil {
+		return false, false
+	}
+	key := hashKey(filePath)
+	if fh, found := h.Files[key]; found && fh.SHA256 == hash {
+		return true, true
+	}
+	if h.Translated[key] == hash {
+		return false, true
+	}
+	return false, false
+}
+
+// Lookup 返回文件记录的原版哈希
+func (h *Hashes) Lookup(filePath string) (FileHash, bool) {
+	if h == nil {
+		return FileHash{}, false
+	}
+	fh, ok := h.Files[hashKey(filePath)]
+	return fh, ok
+}
+
+// hashKey 记录中使用的路径 (绝对路径)
+func hashKey(filePath string) string {
+	if abs, err := filepath.Abs(filePath); err == nil {
+		return abs
+	}
+	return filePath
+}
@@ -0,0 +1,154 @@
+package backup
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// appDirName 本工具在各平台数据目录下使用的目录名
+const appDirName = "antigravity_translator"
+
+// legacyDirName 旧版本的备份目录名 (位于程序目录下)
+const legacyDirName = "antigravity_backup"
+
+// DefaultRoot 返回平台约定的默认备份目录
+//   - Windows: %LOCALAPPDATA%\antigravity_translator\backups
+//   - Linux:   $XDG_STATE_HOME/antigravity_translator/backups (默认 ~/.local/state)
+//   - macOS:   ~/Library/Application Support/antigravity_translator/backups
+func DefaultRoot() (string, error) {
+	var base string
+	switch runtime.GOOS {
+	case "windows":
+		base = os.Getenv("LOCALAPPDATA")
+		if base == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			base = filepath.Join(homeDir, "AppData", "Local")
+		}
+	case "darwin":
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(homeDir, "Library", "Application Support")
+	default:
+		base = os.Getenv("XDG_STATE_HOME")
+		if base == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			base = filepath.Join(homeDir, ".local", "state")
+		}
+	}
+	return filepath.Join(base, appDirName, "backups"), nil
+}
+
+// LegacyRoot 返回旧版本使用的备份目录 (程序所在目录下的 antigravity_backup)
+func LegacyRoot() (string, error) {
+	programDir, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(programDir), legacyDirName), nil
+}
+
+// MigrateLegacy 将旧版本程序目录下的备份迁移到备份根目录，返回迁移的备份数
+func (s Store) MigrateLegacy() (int, error) {
+	backupRoot := s.Root
+	legacyRoot, err := LegacyRoot()
+	if err != nil {
+		return 0, nil
+	}
+	if filepath.Clean(legacyRoot) == filepath.Clean(backupRoot) {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(legacyRoot)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(backupRoot, 0755); err != nil {
+		return 0, err
+	}
+
+	migrated := 0
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		src := filepath.Join(legacyRoot, entry.Name())
+		if _, err := os.Stat(filepath.Join(src, RecordFileName)); err != nil {
+			continue
+		}
+		dst := filepath.Join(backupRoot, entry.Name())
+		if _, err := os.Stat(dst); err == nil {
+			continue // 已迁移过
+		}
+		if err := moveDir(src, dst); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		migrated++
+	}
+
+	// 旧目录已清空时一并删除
+	os.Remove(legacyRoot)
+
+	return migrated, errors.Join(errs...)
+}
+
+// moveDir 移动目录，跨磁盘时退化为复制后删除
+func moveDir(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	err := filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return copyFile(path, target)
+	})
+	if err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+	"antigravity_translator/translator"
+)
+
+// ========================================
+// 替换引擎基准测试
+// ========================================
+
+// benchInput 一份基准测试输入
+type benchInput struct {
+	path    string // 真实文件路径，合成数据为空
+	target  string
+	packs   []string
+	content string
+}
+
+// collectBenchInputs 收集真实的目标文件，找不到时为每个分组生成合成数据
+func collectBenchInputs(ctx context.Context, opts cliOptions, size int) []benchInput {
+	var inputs []benchInput
+	groups := []string{"antigravity", "continue"}
+	if opts.target != "" {
+		groups = []string{opts.target}
+	}
+
+	for _, group := range groups {
+		var roots []string
+		if group == "antigravity" {
+			roots = installPathsFor(ctx, opts)
+		} else if roots = continuePathsFor(ctx, opts); len(roots) == 0 {
+			if ext := findContinueExtension(ctx); ext != nil {
+				roots = []string{ext.Path}
+			}
+		}
+
+		found := false
+		for _, root := range roots {
+			files, _ := targets.Locate(ctx, group, root)
+			for _, f := range files {
+				content, err := os.ReadFile(f.Path)
+				if err != nil {
+					continue
+				}
+				inputs = append(inputs, benchInput{path: f.Path, target: f.Target.ID, packs: f.Target.RulePacks, content: string(content)})
+				found = true
+			}
+		}
+		if found {
+			continue
+		}
+		for _, t := range targets.InGroup(group) {
+			inputs = append(inputs, benchInput{target: t.ID, packs: t.RulePacks, content: engine.SyntheticBundle(size, t.RuleSets()...)})
+		}
+	}
+	return inputs
+}
+
+// timeIt 运行 n 次，返回最短耗时 (毫秒)
+func timeIt(n int, fn func()) float64 {
+	best := time.Duration(0)
+	for i := 0; i < n; i++ {
+		start := time.Now()
+		fn()
+		if d := time.Since(start); i == 0 || d < best {
+			best = d
+		}
+	}
+	return float64(best.Microseconds()) / 1000
+}
+
+// runBench 对每份输入分别用旧的逐条替换和新引擎各运行 iterations 次
+func runBench(ctx context.Context, opts cliOptions, iterations, size int) *BenchResult {
+	result := &BenchResult{Operation: "bench", Iterations: iterations, Files: []BenchFile{}, Warnings: []translator.Problem{}, Errors: []translator.Problem{}}
+
+	for _, in := range collectBenchInputs(ctx, opts, size) {
+		var sets []*engine.RuleSet
+		for _, name := range in.packs {
+			set := rules.Lookup(name)
+			for _, p := range set.Phases {
+				p.Replacer() // 编译不计入耗时
+			}
+			sets = append(sets, set)
+		}
+
+		var legacyOut, engineOut string
+		var legacyHits int
+		var stats engine.Stats
+		legacyMs := timeIt(iterations, func() { legacyOut, legacyHits = engine.ApplyLegacy(in.target, in.content, sets...) })
+		engineMs := timeIt(iterations, func() { engineOut, stats, _ = engine.ApplyTarget(ctx, in.target, in.content, sets...) })
+		engineHits := stats.Hits()
+		file := BenchFile{
+			Path:       in.path,
+			Target:     in.target,
+			Synthetic:  in.path == "",
+			Size:       len(in.content),
+			LegacyMs:   legacyMs,
+			EngineMs:   engineMs,
+			LegacyHits: legacyHits,
+			EngineHits: engineHits,
+			Identical:  legacyOut == engineOut,
+		}
+		if engineMs > 0 {
+			file.Speedup = legacyMs / engineMs
+		}
+		if !file.Identical {
+			// 规则之间互相重叠时，逐条替换的结果取决于规则顺序，新引擎按最左最长匹配
+			result.Warnings = append(result.Warnings, translator.NewProblem(translator.CodeBenchMismatch, in.path, "%s: 两种实现的输出不一致 (规则之间存在重叠)", in.target))
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	if len(result.Files) == 0 {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeNoTargetFiles, "", "没有可用于基准测试的目标"))
+	}
+	return result
+}
+
+// cliBench 执行 bench 子命令
+func cliBench(ctx context.Context, opts cliOptions, iterations, size int) int {
+	if iterations < 1 || size < 1 {
+		fmt.Fprintln(os.Stderr, "--iterations 和 --size 必须大于 0")
+		return 2
+	}
+	result := runBench(ctx, opts, iterations, size<<20)
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printBenchResult(result)
+	}
+	return exitCode(result.Errors)
+}
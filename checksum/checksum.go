@@ -0,0 +1,160 @@
+// Package checksum 处理 Antigravity product.json 中的文件校验和。
+//
+// Antigravity 启动时会校验 product.json 中登记的文件哈希，被修改的文件需要移除对应的
+// 校验和，否则会提示"安装似乎损坏"。
+package checksum
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action 某个校验和的处理结果
+type Action struct {
+	Key    string `json:"key"`
+	Action string `json:"action"` // "removed"、"absent" (apply)，"present"、"absent" (status) 或 "restored"、"present"、"absent" (revert)
+}
+
+// ProductJSONPath 返回安装目录下 product.json 的路径
+func ProductJSONPath(installPath string) string {
+	return filepath.Join(installPath, "resources", "app", "product.json")
+}
+
+// quote 返回 product.json 中出现的带引号的键
+func quote(key string) string { return `"` + key + `"` }
+
+// Remove 从 product.json 中移除给定文件 (相对 resources/app/out 的路径，如 "jetskiAgent/main.js") 的校验和
+// product.json 不存在时返回 os.IsNotExist 可识别的错误
+func Remove(ctx context.Context, installPath string, keys []string) ([]Action, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	productJsonPath := ProductJSONPath(installPath)
+
+	content, err := os.ReadFile(productJsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	var actions []Action
+	removed := make(map[string]bool)
+	var newLines []string
+
+	for _, line := range lines {
+		skip := false
+		for _, key := range keys {
+			if strings.Contains(line, quote(key)) {
+				removed[key] = true
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			newLines = append(newLines, line)
+		}
+	}
+
+	for _, key := range keys {
+		action := "absent"
+		if removed[key] {
+			action = "removed"
+		}
+		actions = append(actions, Action{Key: key, Action: action})
+	}
+
+	if len(removed) == 0 {
+		return actions, nil
+	}
+
+	// 保存修改后的内容
+	newContent := strings.Join(newLines, "\n")
+	// 修复尾随逗号问题
+	newContent = strings.ReplaceAll(newContent, ",\n}", "\n}")
+	newContent = strings.ReplaceAll(newContent, ",\n]", "\n]")
+
+	if err := os.WriteFile(productJsonPath, []byte(newContent), 0644); err != nil {
+		return actions, err
+	}
+	return actions, nil
+}
+
+// Restore 把给定文件的校验和从原版 product.json (original，如备份中的副本) 加回 product.json
+// 只处理每个校验和单独占一行的格式；原版中找不到 (或不是单独一行) 的校验和报告为 "absent"
+func Restore(ctx context.Context, installPath string, original []byte, keys []string) ([]Action, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	productJsonPath := ProductJSONPath(installPath)
+	content, err := os.ReadFile(productJsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	object := -1 // "checksums": { 所在的行
+	for i, line := range lines {
+		if strings.Contains(line, `"checksums"`) && strings.HasSuffix(strings.TrimSpace(line), "{") {
+			object = i
+			break
+		}
+	}
+
+	var actions []Action
+	restored := 0
+	for _, key := range keys {
+		if strings.Contains(string(content), quote(key)) {
+			actions = append(actions, Action{Key: key, Action: "present"})
+			continue
+		}
+		entry := ""
+		for _, line := range strings.Split(string(original), "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), quote(key)) {
+				entry = strings.TrimRight(line, ",\r")
+				break
+			}
+		}
+		if entry == "" || object < 0 {
+			actions = append(actions, Action{Key: key, Action: "absent"})
+			continue
+		}
+		// 插入到对象的第一项，对象中还有其他项时加上逗号
+		if object+1 < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[object+1]), "}") {
+			entry += ","
+		}
+		lines = append(lines[:object+1], append([]string{entry}, lines[object+1:]...)...)
+		actions = append(actions, Action{Key: key, Action: "restored"})
+		restored++
+	}
+
+	if restored == 0 {
+		return actions, nil
+	}
+	if err := os.WriteFile(productJsonPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return actions, err
+	}
+	return actions, nil
+}
+
+// Status 检查 product.json 中给定文件的校验和是否仍然存在
+func Status(ctx context.Context, installPath string, keys []string) ([]Action, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(ProductJSONPath(installPath))
+	if err != nil {
+		return nil, err
+	}
+	var actions []Action
+	for _, key := range keys {
+		action := "absent"
+		if strings.Contains(string(content), quote(key)) {
+			action = "present"
+		}
+		actions = append(actions, Action{Key: key, Action: action})
+	}
+	return actions, nil
+}
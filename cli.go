@@ -0,0 +1,1030 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+	"antigravity_translator/translator"
+)
+
+// ========================================
+// 命令行模式
+// ========================================
+
+const cliUsage = `用法:
+  antigravity_translator                      进入交互菜单
+  antigravity_translator apply   [选项]       汉化 (--target antigravity|continue)
+  antigravity_translator restore [选项]       还原备份 (--backup <ID>，默认最近一次)
+  antigravity_translator list    [选项]       查看备份列表
+  antigravity_translator status  [选项]       查看汉化状态
+  antigravity_translator untranslate [选项]   没有备份时用反向规则恢复原版 (--target antigravity|continue)
+  antigravity_translator explain <文本> [选项] 查找界面上的一段中文是哪条规则产生的
+  antigravity_translator revert  [选项]       只撤销个别规则的替换 (--rule <ID>、--pattern <正则>、--category <阶段>)
+  antigravity_translator installs [选项]      列出检测到的 Antigravity 安装和 Continue 扩展
+  antigravity_translator bench   [选项]       对比替换引擎与旧的逐条替换的耗时
+  antigravity_translator lint    [选项]       检查内置规则 (正则语法、占位符引用、重复原文)
+  antigravity_translator rules stale [选项]   查找不再命中的规则，在 bundle 中建议相近的新原文
+  antigravity_translator rules extract --original <原版> --edited <修改后>
+                                              对比手工修改的 bundle，为每个改动的字面量生成规则
+  antigravity_translator strings diff <旧> <新> [选项]
+                                              比较两个版本的界面字符串 (bundle 文件、备份 ID 或安装目录)
+  antigravity_translator config get [选项] [配置项]
+                                              查看配置
+  antigravity_translator config set [选项] <配置项> <值>
+                                              修改配置 (值为空字符串时清除)
+
+通用选项:
+  --output text|json   输出格式 (默认 text)
+  --backup-dir <目录>  备份根目录 (也可用环境变量 ANTIGRAVITY_BACKUP_DIR 或配置文件指定)
+
+多安装选项 (apply/restore/status):
+  --path <路径>        指定安装路径，可重复使用
+  --all                对检测到的所有 Antigravity 安装 (或所有编辑器中的 Continue 扩展) 执行
+
+汉化选项 (apply):
+  --jobs <N>           并行翻译的最大文件数 (默认 CPU 核数)；JSON 模式下进度以 JSON Lines 写到标准错误
+  --strict             规则命中次数不符合预期 (如恰好 1 次) 时报告错误并且不修改任何文件，默认只报告警告
+  --dry-run            只预览每条规则的替换 (正则等模式规则列出实际替换样例)，不备份也不写回
+  --trace              在每个汉化的文件旁写入替换记录 (<文件名>.trace.json)，供 explain 准确定位规则
+  --bilingual paren|suffix|off
+                       双语显示: 译文后带上原文 ("译文 (原文)" 或 "译文 · 原文")，off 只显示译文；
+                       默认使用配置项 bilingual，生效的规则类别和原文最大长度见 bilingual_categories、bilingual_max_length
+
+反向翻译选项 (untranslate):
+  --target antigravity|continue  恢复的分组 (默认 antigravity)；--path、--all 同 apply
+  --dry-run                      只报告无法恢复的字面量和哈希校验结果，不备份也不写回
+  --bilingual paren|suffix|off   按替换记录或备份记录中汉化时的双语显示设置匹配译文；只有没有记下设置的文件
+                                 (旧版本汉化) 使用该格式 (默认使用配置项 bilingual)，explain、revert 同样适用
+
+译文反查选项 (explain):
+  --target antigravity|continue  查找的分组 (默认两者都查找)；--path 同 apply
+                                 文件有替换记录 (apply --trace) 时报告实际产生该文字的规则，否则列出译文包含该文字的规则
+
+撤销规则选项 (revert):
+  --rule <ID>                    撤销的规则 ID (如 chat/normal/3fa2c1d0，见 explain 的输出) 或位置 (如 chat/normal/12)，可重复
+  --pattern <正则>               撤销原文或译文匹配该正则表达式的规则
+  --category <阶段>              撤销该阶段的所有规则 (normal、template、variable、anchored、regex、quoted、raw、user)，可重复
+  --target antigravity|continue  处理的分组 (默认 antigravity)；--path、--all 同 apply
+  --dry-run                      只报告会撤销的替换，不修改文件
+                                 文件有替换记录 (apply --trace) 时按记录准确撤销，否则使用反向规则
+
+失效规则选项 (rules stale):
+  --target antigravity|continue  检查的分组 (默认 antigravity)；--path 同 apply
+  --min-similarity <0-1>         候选字面量的最低相似度 (默认 0.8)
+  --accept all|<编号,...>        把选中的建议写入用户规则文件 (配置目录下的 user_rules.json)，之后汉化时生效
+
+生成规则选项 (rules extract):
+  --original <文件>              原版 bundle (如备份中的 chat.js)
+  --edited <文件>                手工修改后的 bundle
+  --target <目标 ID>             文件所属的目标 (默认按文件名推断)
+
+字符串比较选项 (strings diff):
+  --target <目标 ID>             只比较该目标 (如 antigravity.chat)；比较单个文件时默认按文件名推断
+  --min-similarity <0-1>         删除与新增的字面量配对为修改的最低相似度 (默认 0.6)
+  --all                          比较所有字面量 (默认只比较像界面文字的字面量)
+
+基准测试选项 (bench):
+  --iterations <N>     每种实现运行的次数，取最短耗时 (默认 3)
+  --size <MB>          找不到目标文件时生成的合成数据大小 (默认 8)
+`
+
+// stringList 可重复使用的字符串参数
+type stringList []string
+
+func (l *stringList) String() string { return fmt.Sprint(*l) }
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// cliOptions 命令行选项
+type cliOptions struct {
+	output string
+	target string
+	paths  stringList
+	all    bool
+	backup string
+	dryRun bool
+	translatorOptions
+}
+
+// cliSubcommands 带二级子命令的命令
+var cliSubcommands = map[string][]string{
+	"rules":   {"stale", "extract"},
+	"strings": {"diff"},
+}
+
+// runCLI 执行命令行子命令，返回进程退出码
+func runCLI(args []string) int {
+	cmd := args[0]
+	if cmd == "help" || cmd == "-h" || cmd == "--help" {
+		fmt.Print(cliUsage)
+		return 0
+	}
+
+	if cmd == "config" {
+		return cliConfig(args[1:])
+	}
+	if subcommands, ok := cliSubcommands[cmd]; ok {
+		if len(args) < 2 || !containsString(subcommands, args[1]) {
+			fmt.Fprint(os.Stderr, cliUsage)
+			return 2
+		}
+		cmd, args = cmd+" "+args[1], args[1:]
+	}
+
+	var opts cliOptions
+	var iterations, size int
+	var minSimilarity float64
+	var accept string
+	var all bool
+	var original, edited string
+	var ruleIDs, categories stringList
+	var pattern string
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.StringVar(&opts.output, "output", "text", "输出格式: text 或 json")
+	fs.StringVar(&opts.backupDir, "backup-dir", "", "备份根目录")
+	switch cmd {
+	case "apply":
+		fs.StringVar(&opts.target, "target", "", "汉化目标: antigravity 或 continue (默认使用配置中的 targets)")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.BoolVar(&opts.all, "all", false, "汉化检测到的所有 Antigravity 安装或 Continue 扩展")
+		fs.IntVar(&opts.jobs, "jobs", 0, "并行翻译的最大文件数 (默认 CPU 核数)")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "只预览替换，不备份也不写回")
+		fs.BoolVar(&opts.strict, "strict", false, "规则命中次数不符合预期时报告错误并且不修改文件")
+		fs.BoolVar(&opts.trace, "trace", false, "在每个汉化的文件旁写入替换记录")
+		fs.StringVar(&opts.bilingual, "bilingual", "", "双语显示格式: paren、suffix 或 off (默认使用配置中的 bilingual)")
+	case "restore":
+		fs.StringVar(&opts.backup, "backup", "", "要还原的备份 ID (默认最近一次)")
+		fs.Var(&opts.paths, "path", "还原该安装路径最近一次的备份，可重复")
+		fs.BoolVar(&opts.all, "all", false, "还原检测到的所有 Antigravity 安装最近一次的备份")
+	case "untranslate":
+		fs.StringVar(&opts.target, "target", "antigravity", "恢复的分组: antigravity 或 continue")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.BoolVar(&opts.all, "all", false, "恢复检测到的所有 Antigravity 安装或 Continue 扩展")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "只报告结果，不备份也不写回")
+		fs.StringVar(&opts.bilingual, "bilingual", "", "没有记下汉化时的双语显示设置 (旧版本汉化) 的文件使用的格式: paren、suffix 或 off (默认使用配置中的 bilingual)")
+	case "explain":
+		fs.StringVar(&opts.target, "target", "", "查找的分组: antigravity 或 continue (默认两者)")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.StringVar(&opts.bilingual, "bilingual", "", "没有记下汉化时的双语显示设置 (旧版本汉化) 的文件使用的格式: paren、suffix 或 off (默认使用配置中的 bilingual)")
+	case "revert":
+		fs.Var(&ruleIDs, "rule", "撤销的规则 ID，可重复")
+		fs.StringVar(&pattern, "pattern", "", "撤销原文或译文匹配该正则表达式的规则")
+		fs.Var(&categories, "category", "撤销该阶段类型的所有规则，可重复")
+		fs.StringVar(&opts.target, "target", "antigravity", "处理的分组: antigravity 或 continue")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.BoolVar(&opts.all, "all", false, "处理检测到的所有 Antigravity 安装或 Continue 扩展")
+		fs.BoolVar(&opts.dryRun, "dry-run", false, "只报告结果，不修改文件")
+		fs.StringVar(&opts.bilingual, "bilingual", "", "没有记下汉化时的双语显示设置 (旧版本汉化) 的文件使用的格式: paren、suffix 或 off (默认使用配置中的 bilingual)")
+	case "status":
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径，可重复 (默认自动检测)")
+		fs.BoolVar(&opts.all, "all", false, "查看检测到的所有 Antigravity 安装")
+	case "bench":
+		fs.StringVar(&opts.target, "target", "", "只测试 antigravity 或 continue")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.IntVar(&iterations, "iterations", 3, "每种实现运行的次数")
+		fs.IntVar(&size, "size", 8, "合成数据大小 (MB)")
+	case "rules stale":
+		fs.StringVar(&opts.target, "target", "antigravity", "检查的分组: antigravity 或 continue")
+		fs.Var(&opts.paths, "path", "Antigravity 安装路径或 Continue 扩展目录，可重复 (默认自动检测)")
+		fs.Float64Var(&minSimilarity, "min-similarity", translator.DefaultMinSimilarity, "候选字面量的最低相似度 (0 到 1)")
+		fs.StringVar(&accept, "accept", "", "写入用户规则文件的建议: all 或逗号分隔的编号")
+	case "rules extract":
+		fs.StringVar(&original, "original", "", "原版 bundle")
+		fs.StringVar(&edited, "edited", "", "手工修改后的 bundle")
+		fs.StringVar(&opts.target, "target", "", "文件所属的目标 ID，如 antigravity.chat (默认按文件名推断)")
+	case "strings diff":
+		fs.StringVar(&opts.target, "target", "", "比较单个文件时文件所属的目标 ID，如 antigravity.chat (默认按文件名推断)")
+		fs.Float64Var(&minSimilarity, "min-similarity", translator.DefaultModifiedSimilarity, "删除与新增的字面量配对为修改的最低相似度 (0 到 1)")
+		fs.BoolVar(&all, "all", false, "比较所有字面量 (默认只比较像界面文字的字面量)")
+	case "list", "installs", "lint":
+	default:
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n%s", cmd, cliUsage)
+		return 2
+	}
+	positional, err := parseInterspersed(fs, args[1:])
+	if err != nil {
+		return 2
+	}
+	if n, ok := cliPositionalArgs[cmd]; ok && len(positional) != n {
+		fmt.Fprintf(os.Stderr, "%s 需要 %d 个参数\n\n%s", cmd, cliPositionalArgs[cmd], cliUsage)
+		return 2
+	}
+	if opts.output != "text" && opts.output != "json" {
+		fmt.Fprintf(os.Stderr, "无效的输出格式: %s\n", opts.output)
+		return 2
+	}
+	if err := validBilingualStyle(opts.bilingual); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if opts.output == "json" {
+		opts.progress = printProgressJSON
+	}
+
+	// Ctrl+C 时取消尚未写回的操作
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch cmd {
+	case "apply":
+		return cliApply(ctx, opts)
+	case "restore":
+		return cliRestore(ctx, opts)
+	case "untranslate":
+		return cliUntranslate(ctx, opts)
+	case "explain":
+		return cliExplain(ctx, opts, positional[0])
+	case "revert":
+		return cliRevert(ctx, opts, ruleIDs, pattern, categories)
+	case "list":
+		return cliList(ctx, opts)
+	case "installs":
+		return cliInstalls(ctx, opts)
+	case "bench":
+		return cliBench(ctx, opts, iterations, size)
+	case "lint":
+		return cliLint(ctx, opts)
+	case "rules stale":
+		return cliRulesStale(ctx, opts, minSimilarity, accept)
+	case "rules extract":
+		return cliRulesExtract(ctx, opts, original, edited)
+	case "strings diff":
+		return cliStringsDiff(ctx, opts, positional[0], positional[1], translator.StringsDiffOptions{MinSimilarity: minSimilarity, All: all})
+	default:
+		return cliStatus(ctx, opts)
+	}
+}
+
+// cliPositionalArgs 命令需要的位置参数个数 (未列出的命令不接受位置参数)
+var cliPositionalArgs = map[string]int{
+	"strings diff": 2,
+	"explain":      1,
+}
+
+// parseInterspersed 解析可以出现在位置参数前后的选项，返回位置参数
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// exitCode 根据错误数返回退出码
+func exitCode(errors []translator.Problem) int {
+	if len(errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// printResults 输出一个或多个结果，多个结果时以 BatchResult 包装 JSON 输出
+func printResults(opts cliOptions, operation string, results []interface{}, print func(interface{})) {
+	if opts.output != "json" {
+		for _, r := range results {
+			print(r)
+		}
+		return
+	}
+	if len(results) == 1 {
+		printJSON(results[0])
+	} else {
+		printJSON(&BatchResult{Operation: operation, Results: results})
+	}
+}
+
+// installPathsFor 根据 --path/--all 确定要处理的 Antigravity 安装路径
+func installPathsFor(ctx context.Context, opts cliOptions) []string {
+	if len(opts.paths) > 0 {
+		return opts.paths
+	}
+	if opts.all {
+		var paths []string
+		for _, inst := range findAntigravityInstallations(ctx) {
+			paths = append(paths, inst.Path)
+		}
+		return paths
+	}
+	if path := findAntigravityInstallPath(ctx); path != "" {
+		return []string{path}
+	}
+	return nil
+}
+
+// continuePathsFor 返回要处理的 Continue 扩展目录: --path > --all (所有编辑器) > 空 (自动检测第一个)
+func continuePathsFor(ctx context.Context, opts cliOptions) []string {
+	if len(opts.paths) > 0 {
+		return opts.paths
+	}
+	var paths []string
+	if opts.all {
+		for _, ext := range findContinueExtensions(ctx) {
+			paths = append(paths, ext.Path)
+		}
+	}
+	return paths
+}
+
+func cliApply(ctx context.Context, opts cliOptions) int {
+	groups := []string{"antigravity"}
+	if opts.target != "" {
+		groups = []string{opts.target}
+	} else if cfg, err := loadConfig(); err == nil && len(cfg.Targets) > 0 {
+		groups = cfg.Targets
+	}
+	for _, t := range groups {
+		if t != "antigravity" && t != "continue" {
+			fmt.Fprintf(os.Stderr, "无效的汉化目标: %s\n", t)
+			return 2
+		}
+	}
+
+	tr := newTranslator(opts.translatorOptions)
+	var results []interface{}
+	code := 0
+	for _, t := range groups {
+		var paths []string
+		if t == "antigravity" {
+			paths = installPathsFor(ctx, opts)
+		} else {
+			paths = continuePathsFor(ctx, opts)
+		}
+		if len(paths) == 0 {
+			paths = []string{""} // 由 applyTarget 报告未检测到
+		}
+		for _, path := range paths {
+			result := applyTarget(ctx, tr, t, path, opts.dryRun)
+			results = append(results, result)
+			if len(result.Errors) > 0 || (!result.DryRun && result.Succeeded() != len(result.Files)) {
+				code = 1
+			}
+			for _, f := range result.Files {
+				if f.Error != nil {
+					code = 1
+				}
+			}
+		}
+	}
+
+	printResults(opts, "apply", results, func(r interface{}) {
+		if result := r.(*translator.ApplyResult); result.DryRun {
+			printDryRunResult(result)
+		} else {
+			printApplyResult(result)
+		}
+	})
+	return code
+}
+
+// applyTarget 检测路径并汉化单个目标，dryRun 为 true 时只预览
+func applyTarget(ctx context.Context, tr *translator.Translator, target, path string, dryRun bool) *translator.ApplyResult {
+	result := translator.NewApplyResult(target, "")
+	root, files, warnings, problem := locateTarget(ctx, target, path)
+	result.InstallPath = root
+	result.Warnings = append(result.Warnings, warnings...)
+	if problem != nil {
+		result.Errors = append(result.Errors, *problem)
+		return result
+	}
+	var applied *translator.ApplyResult
+	if dryRun {
+		applied = tr.Preview(ctx, target, root, files)
+	} else {
+		applied = tr.Apply(ctx, target, root, files)
+	}
+	applied.Warnings = append(result.Warnings, applied.Warnings...)
+	return applied
+}
+
+// locateTarget 检测路径并查找单个分组的目标文件，返回根目录、文件和警告；无法继续时返回错误
+func locateTarget(ctx context.Context, target, path string) (string, []targets.File, []translator.Problem, *translator.Problem) {
+	root := path
+	var warnings []translator.Problem
+	if target == "continue" {
+		if root == "" {
+			if ext := findContinueExtension(ctx); ext != nil {
+				root = ext.Path
+				warnings = obsoleteContinueWarnings(ext)
+			}
+		} else {
+			root = targets.ContinueRoot(root) // 允许直接指定 gui/assets 下的文件
+			warnings = obsoleteContinueWarningsFor(root)
+		}
+	}
+	fail := func(p translator.Problem) (string, []targets.File, []translator.Problem, *translator.Problem) {
+		return root, nil, warnings, &p
+	}
+
+	if root == "" {
+		return fail(translator.NewProblem(translator.CodeInstallNotFound, "", "未检测到 %s 安装路径，请使用 --path 指定", backupTypeLabel(target)))
+	}
+	if _, err := os.Stat(root); err != nil {
+		return fail(translator.NewProblem(translator.CodeFileNotFound, root, "路径不存在: %s", root))
+	}
+	if target == "antigravity" && !targets.ValidateInstallPath(root) {
+		return fail(translator.NewProblem(translator.CodeInvalidPath, root, "无效的 Antigravity 安装路径"))
+	}
+	files, err := targets.Locate(ctx, target, root)
+	if err != nil {
+		return fail(translator.NewProblem(translator.CodeCanceled, root, "已取消"))
+	}
+	if len(files) == 0 {
+		return fail(translator.NewProblem(translator.CodeNoTargetFiles, root, "未找到任何可汉化的文件"))
+	}
+	return root, files, warnings, nil
+}
+
+func cliRestore(ctx context.Context, opts cliOptions) int {
+	tr := newTranslator(opts.translatorOptions)
+	list, backups := tr.List(ctx)
+
+	// 确定要还原的备份: 指定 ID，或每个安装路径最近一次的备份
+	var wanted []string
+	if opts.backup != "" || (len(opts.paths) == 0 && !opts.all) {
+		wanted = []string{""}
+	} else {
+		wanted = installPathsFor(ctx, opts)
+	}
+
+	var results []interface{}
+	code := 0
+	for _, installPath := range wanted {
+		var selected *backup.Backup
+		for _, b := range backups {
+			if opts.backup != "" && b.ID != opts.backup {
+				continue
+			}
+			if installPath != "" && !targets.SamePath(b.Record.InstallPath, installPath) {
+				continue
+			}
+			selected = b
+			break
+		}
+
+		var result *translator.RestoreResult
+		if selected == nil {
+			result = &translator.RestoreResult{Operation: "restore", BackupID: opts.backup, InstallPath: installPath, Files: []translator.RestoredFile{}, Warnings: list.Warnings, Errors: list.Errors}
+			if len(result.Errors) == 0 {
+				result.Errors = append(result.Errors, translator.NewProblem(translator.CodeBackupNotFound, filepath.Join(list.BackupRoot, opts.backup), "未找到备份"))
+			}
+		} else {
+			result = tr.Restore(ctx, selected)
+		}
+		results = append(results, result)
+		if len(result.Errors) > 0 || result.Succeeded() != len(result.Files) {
+			code = 1
+		}
+	}
+
+	printResults(opts, "restore", results, func(r interface{}) { printRestoreResult(r.(*translator.RestoreResult)) })
+	return code
+}
+
+func cliUntranslate(ctx context.Context, opts cliOptions) int {
+	if opts.target != "antigravity" && opts.target != "continue" {
+		fmt.Fprintf(os.Stderr, "无效的恢复目标: %s\n", opts.target)
+		return 2
+	}
+	var paths []string
+	if opts.target == "antigravity" {
+		paths = installPathsFor(ctx, opts)
+	} else {
+		paths = continuePathsFor(ctx, opts)
+	}
+	if len(paths) == 0 {
+		paths = []string{""} // 由 locateTarget 报告未检测到
+	}
+
+	tr := newTranslator(opts.translatorOptions)
+	var results []interface{}
+	code := 0
+	for _, path := range paths {
+		result := translator.NewUntranslateResult(opts.target, "")
+		root, files, warnings, problem := locateTarget(ctx, opts.target, path)
+		result.InstallPath = root
+		result.Warnings = append(result.Warnings, warnings...)
+		if problem != nil {
+			result.Errors = append(result.Errors, *problem)
+		} else {
+			untranslated := tr.Untranslate(ctx, opts.target, root, files, opts.dryRun)
+			untranslated.Warnings = append(result.Warnings, untranslated.Warnings...)
+			result = untranslated
+		}
+		results = append(results, result)
+		if len(result.Errors) > 0 {
+			code = 1
+		}
+	}
+
+	printResults(opts, "untranslate", results, func(r interface{}) { printUntranslateResult(r.(*translator.UntranslateResult)) })
+	return code
+}
+
+func cliRevert(ctx context.Context, opts cliOptions, ruleIDs []string, pattern string, categories []string) int {
+	if opts.target != "antigravity" && opts.target != "continue" {
+		fmt.Fprintf(os.Stderr, "无效的目标: %s\n", opts.target)
+		return 2
+	}
+	sel := translator.RevertSelector{Rules: ruleIDs, Categories: categories}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "无效的正则表达式: %v\n", err)
+			return 2
+		}
+		sel.Pattern = re
+	}
+	if sel.Empty() {
+		fmt.Fprintf(os.Stderr, "revert 需要 --rule、--pattern 或 --category\n\n%s", cliUsage)
+		return 2
+	}
+	var paths []string
+	if opts.target == "antigravity" {
+		paths = installPathsFor(ctx, opts)
+	} else {
+		paths = continuePathsFor(ctx, opts)
+	}
+	if len(paths) == 0 {
+		paths = []string{""} // 由 locateTarget 报告未检测到
+	}
+
+	tr := newTranslator(opts.translatorOptions)
+	var results []interface{}
+	code := 0
+	for _, path := range paths {
+		result := translator.NewRevertResult(opts.target, "")
+		root, files, warnings, problem := locateTarget(ctx, opts.target, path)
+		result.InstallPath = root
+		result.Warnings = append(result.Warnings, warnings...)
+		if problem != nil {
+			result.Errors = append(result.Errors, *problem)
+		} else {
+			reverted := tr.Revert(ctx, opts.target, root, files, sel, opts.dryRun)
+			reverted.Warnings = append(result.Warnings, reverted.Warnings...)
+			result = reverted
+		}
+		results = append(results, result)
+		if len(result.Errors) > 0 {
+			code = 1
+		}
+	}
+
+	printResults(opts, "revert", results, func(r interface{}) { printRevertResult(r.(*translator.RevertResult)) })
+	return code
+}
+
+func cliExplain(ctx context.Context, opts cliOptions, text string) int {
+	if text == "" {
+		fmt.Fprintln(os.Stderr, "要查找的文字为空")
+		return 2
+	}
+	groups := []string{"antigravity", "continue"}
+	switch opts.target {
+	case "":
+	case "antigravity", "continue":
+		groups = []string{opts.target}
+	default:
+		fmt.Fprintf(os.Stderr, "无效的查找目标: %s\n", opts.target)
+		return 2
+	}
+
+	// 未指定分组时只查找检测到的分组，两者都未检测到才报告错误
+	var files []targets.File
+	var warnings, problems []translator.Problem
+	for _, group := range groups {
+		var paths []string
+		if group == "antigravity" {
+			paths = installPathsFor(ctx, opts)
+		} else {
+			paths = continuePathsFor(ctx, opts)
+		}
+		if len(paths) == 0 {
+			paths = []string{""} // 由 locateTarget 报告未检测到
+		}
+		for _, path := range paths {
+			_, located, w, problem := locateTarget(ctx, group, path)
+			warnings = append(warnings, w...)
+			if problem != nil {
+				problems = append(problems, *problem)
+				continue
+			}
+			files = append(files, located...)
+		}
+	}
+
+	result := newTranslator(opts.translatorOptions).Explain(ctx, text, files)
+	result.Warnings = append(warnings, result.Warnings...)
+	if len(files) == 0 || len(groups) == 1 {
+		result.Errors = append(result.Errors, problems...)
+	}
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printExplainResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+func cliList(ctx context.Context, opts cliOptions) int {
+	result, _ := newTranslator(opts.translatorOptions).List(ctx)
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printListResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+func cliLint(ctx context.Context, opts cliOptions) int {
+	result := newTranslator(opts.translatorOptions).Lint(ctx)
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printLintResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+func cliRulesStale(ctx context.Context, opts cliOptions, minSimilarity float64, accept string) int {
+	if opts.target != "antigravity" && opts.target != "continue" {
+		fmt.Fprintf(os.Stderr, "无效的汉化目标: %s\n", opts.target)
+		return 2
+	}
+	if minSimilarity <= 0 || minSimilarity > 1 {
+		fmt.Fprintf(os.Stderr, "无效的相似度: %v\n", minSimilarity)
+		return 2
+	}
+	accepted, err := parseAccept(accept)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	var paths []string
+	if opts.target == "antigravity" {
+		paths = installPathsFor(ctx, opts)
+	} else {
+		paths = continuePathsFor(ctx, opts)
+	}
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+
+	tr := newTranslator(opts.translatorOptions)
+	var results []interface{}
+	code := 0
+	for _, path := range paths {
+		root, files, warnings, problem := locateTarget(ctx, opts.target, path)
+		var result *translator.StaleResult
+		if problem != nil {
+			result = translator.NewStaleResult(opts.target, root)
+			result.Errors = append(result.Errors, *problem)
+		} else {
+			result = tr.Stale(ctx, opts.target, root, files, minSimilarity)
+			if accept != "" && len(result.Errors) == 0 {
+				acceptSuggestions(result, accepted)
+			}
+		}
+		result.Warnings = append(warnings, result.Warnings...)
+		results = append(results, result)
+		code = max(code, exitCode(result.Errors))
+	}
+
+	printResults(opts, "rules stale", results, func(r interface{}) { printStaleResult(r.(*translator.StaleResult)) })
+	return code
+}
+
+// parseAccept 解析 --accept: "all" 返回 nil (全部)，否则返回逗号分隔的编号
+func parseAccept(accept string) ([]int, error) {
+	if accept == "" || accept == "all" {
+		return nil, nil
+	}
+	var ids []int
+	for _, field := range splitList(accept) {
+		id, err := strconv.Atoi(field)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("无效的建议编号: %s", field)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// acceptSuggestions 把选中的建议 (ids 为空时为每条失效规则的第一个建议) 写入用户规则文件
+func acceptSuggestions(result *translator.StaleResult, ids []int) {
+	if ids == nil {
+		for _, rule := range result.Rules {
+			if len(rule.Suggestions) > 0 {
+				ids = append(ids, rule.Suggestions[0].ID)
+			}
+		}
+	}
+	path, err := userRulesPath()
+	if err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, "", "无法确定用户规则文件路径: %v", err))
+		return
+	}
+	userRules, err := rules.LoadUserRules(path)
+	if err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "读取用户规则文件失败: %v", err))
+		return
+	}
+	var accepted []int
+	for _, id := range ids {
+		rule, suggestion := result.Suggestion(id)
+		if suggestion == nil {
+			result.Warnings = append(result.Warnings, translator.NewProblem(translator.CodeConfig, path, "没有编号为 %d 的建议", id))
+			continue
+		}
+		userRules.Add(rules.UserRule{Pack: rule.Pack, From: suggestion.From, To: suggestion.To, Replaces: rule.From})
+		accepted = append(accepted, id)
+	}
+	if len(accepted) == 0 {
+		return
+	}
+	if err := userRules.Save(path); err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "保存用户规则文件失败: %v", err))
+		return
+	}
+	result.UserRules, result.Accepted = path, accepted
+}
+
+func cliRulesExtract(ctx context.Context, opts cliOptions, original, edited string) int {
+	if original == "" || edited == "" {
+		fmt.Fprintf(os.Stderr, "rules extract 需要 --original 和 --edited\n")
+		return 2
+	}
+	var target *targets.Target
+	if opts.target != "" {
+		if target = targets.Lookup(opts.target); target == nil {
+			fmt.Fprintf(os.Stderr, "未知的目标: %s\n", opts.target)
+			return 2
+		}
+	}
+
+	result := translator.NewExtractResult(opts.target, original, edited)
+	originalContent, err := os.ReadFile(original)
+	if err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeReadFailed, original, "读取失败: %v", err))
+	}
+	editedContent, err := os.ReadFile(edited)
+	if err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeReadFailed, edited, "读取失败: %v", err))
+	}
+	if target == nil && len(result.Errors) == 0 {
+		if target = targets.ForFile(original, originalContent); target == nil {
+			target = targets.ForFile(edited, editedContent)
+		}
+		if target == nil {
+			result.Errors = append(result.Errors, translator.NewProblem(translator.CodeNoTargetFiles, original, "无法判断文件属于哪个目标，请使用 --target 指定"))
+		}
+	}
+	if len(result.Errors) == 0 {
+		result = newTranslator(opts.translatorOptions).ExtractRules(ctx, target, original, edited, originalContent, editedContent)
+	}
+
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printExtractResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+func cliStringsDiff(ctx context.Context, opts cliOptions, oldArg, newArg string, diffOpts translator.StringsDiffOptions) int {
+	if diffOpts.MinSimilarity <= 0 || diffOpts.MinSimilarity > 1 {
+		fmt.Fprintf(os.Stderr, "无效的相似度: %v\n", diffOpts.MinSimilarity)
+		return 2
+	}
+	var target *targets.Target
+	if opts.target != "" {
+		if target = targets.Lookup(opts.target); target == nil {
+			fmt.Fprintf(os.Stderr, "未知的目标: %s\n", opts.target)
+			return 2
+		}
+	}
+
+	tr := newTranslator(opts.translatorOptions)
+	oldFiles, oldProblem := resolveBundles(ctx, tr, oldArg, target)
+	newFiles, newProblem := resolveBundles(ctx, tr, newArg, target)
+	var result *translator.StringsDiffResult
+	if oldProblem != nil || newProblem != nil {
+		result = translator.NewStringsDiffResult(oldArg, newArg)
+		for _, p := range []*translator.Problem{oldProblem, newProblem} {
+			if p != nil {
+				result.Errors = append(result.Errors, *p)
+			}
+		}
+	} else {
+		result = tr.DiffStrings(ctx, oldArg, newArg, oldFiles, newFiles, diffOpts)
+	}
+
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printStringsDiffResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+// resolveBundles 解析 strings diff 的一个版本，依次尝试:
+//   - 单个 bundle 文件 (目标由 target 指定，或按路径和内容推断)
+//   - 备份目录或备份 ID (按备份记录中的原始路径推断每个文件的目标)
+//   - Antigravity 安装目录或 Continue 扩展目录
+func resolveBundles(ctx context.Context, tr *translator.Translator, arg string, target *targets.Target) ([]translator.BundleFiles, *translator.Problem) {
+	fail := func(p translator.Problem) ([]translator.BundleFiles, *translator.Problem) { return nil, &p }
+
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		if target == nil {
+			content, err := os.ReadFile(arg)
+			if err != nil {
+				return fail(translator.NewProblem(translator.CodeReadFailed, arg, "读取失败: %v", err))
+			}
+			if target = targets.ForFile(arg, content); target == nil {
+				return fail(translator.NewProblem(translator.CodeNoTargetFiles, arg, "无法判断文件属于哪个目标，请使用 --target 指定"))
+			}
+		}
+		return []translator.BundleFiles{{Target: target, Paths: []string{arg}}}, nil
+	}
+
+	// 备份: 目录中有备份记录，或者是备份根目录下的备份 ID
+	selected, _ := backup.Open(arg)
+	if _, err := os.Stat(arg); err != nil {
+		_, backups := tr.List(ctx)
+		for _, b := range backups {
+			if b.ID == arg {
+				selected = b
+			}
+		}
+		if selected == nil {
+			return fail(translator.NewProblem(translator.CodeBackupNotFound, arg, "既不是文件或目录，也不是备份 ID: %s", arg))
+		}
+	}
+	if selected != nil {
+		var bundles []translator.BundleFiles
+		for originalPath, name := range selected.Record.Files {
+			path := filepath.Join(selected.Dir, name)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fail(translator.NewProblem(translator.CodeBackupFileAbsent, path, "读取备份文件失败: %v", err))
+			}
+			if t := targets.ForFile(originalPath, content); t != nil && (target == nil || t == target) {
+				bundles = append(bundles, translator.BundleFiles{Target: t, Paths: []string{path}})
+			}
+		}
+		sort.Slice(bundles, func(i, j int) bool { return bundles[i].Paths[0] < bundles[j].Paths[0] })
+		if len(bundles) == 0 {
+			return fail(translator.NewProblem(translator.CodeNoTargetFiles, selected.Dir, "备份中没有可比较的文件"))
+		}
+		return bundles, nil
+	}
+
+	// 安装目录或扩展目录
+	var bundles []translator.BundleFiles
+	for _, group := range []string{"antigravity", "continue"} {
+		files, err := targets.Locate(ctx, group, targets.ContinueRoot(arg))
+		if err != nil {
+			return fail(translator.NewProblem(translator.CodeCanceled, arg, "已取消"))
+		}
+		for _, f := range files {
+			if target == nil || f.Target == target {
+				bundles = append(bundles, translator.BundleFiles{Target: f.Target, Paths: []string{f.Path}})
+			}
+		}
+	}
+	if len(bundles) == 0 {
+		return fail(translator.NewProblem(translator.CodeNoTargetFiles, arg, "未找到任何可比较的文件"))
+	}
+	return bundles, nil
+}
+
+func cliInstalls(ctx context.Context, opts cliOptions) int {
+	result := &InstallsResult{
+		Operation:          "installs",
+		Installations:      findAntigravityInstallations(ctx),
+		ContinueExtensions: findContinueExtensions(ctx),
+		Warnings:           []translator.Problem{},
+		Errors:             []translator.Problem{},
+	}
+	if result.ContinueExtensions == nil {
+		result.ContinueExtensions = []*targets.ContinueExtension{}
+	}
+	for _, ext := range result.ContinueExtensions {
+		result.Warnings = append(result.Warnings, obsoleteContinueWarnings(ext)...)
+	}
+	if result.Installations == nil {
+		result.Installations = []targets.Installation{}
+		if len(result.ContinueExtensions) == 0 {
+			result.Errors = append(result.Errors, translator.NewProblem(translator.CodeInstallNotFound, "", "未检测到 Antigravity 安装或 Continue 扩展"))
+		}
+	}
+	if opts.output == "json" {
+		printJSON(result)
+	} else {
+		printInstallsResult(result)
+	}
+	return exitCode(result.Errors)
+}
+
+func cliStatus(ctx context.Context, opts cliOptions) int {
+	continueDir := ""
+	if ext := findContinueExtension(ctx); ext != nil {
+		continueDir = ext.Path
+	}
+	installPaths := installPathsFor(ctx, opts)
+	tr := newTranslator(opts.translatorOptions)
+	if len(installPaths) == 0 {
+		installPaths = []string{""}
+	}
+
+	var results []interface{}
+	code := 0
+	for i, installPath := range installPaths {
+		continuePath := ""
+		if i == 0 {
+			continuePath = continueDir // Continue 扩展只在第一个结果中报告
+		}
+		result := tr.Status(ctx, installPath, continuePath)
+		if installPath == "" && continuePath == "" {
+			result.Errors = append(result.Errors, translator.NewProblem(translator.CodeInstallNotFound, "", "未检测到 Antigravity 或 Continue 扩展"))
+		}
+		results = append(results, result)
+		code = max(code, exitCode(result.Errors))
+	}
+
+	printResults(opts, "status", results, func(r interface{}) { printStatusResult(r.(*translator.StatusResult)) })
+	return code
+}
+
+// cliConfig 执行 config get/set
+func cliConfig(args []string) int {
+	if len(args) == 0 || (args[0] != "get" && args[0] != "set") {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("config "+action, flag.ContinueOnError)
+	output := fs.String("output", "text", "输出格式: text 或 json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	args = append([]string{action}, fs.Args()...)
+
+	path, _ := configPath()
+	result := &ConfigResult{Operation: "config", Path: path, Values: map[string]string{}, Warnings: []translator.Problem{}, Errors: []translator.Problem{}}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "读取配置文件失败: %v", err))
+	} else if args[0] == "get" {
+		keys := configKeyNames()
+		if len(args) > 1 {
+			keys = args[1:]
+		}
+		for _, key := range keys {
+			k, ok := configKeys[key]
+			if !ok {
+				result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "未知的配置项: %s", key))
+				continue
+			}
+			result.Values[key] = k.get(&cfg)
+		}
+	} else if len(args) != 3 {
+		fmt.Fprint(os.Stderr, cliUsage)
+		return 2
+	} else if err := setConfigValue(&cfg, args[1], args[2]); err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "%v", err))
+	} else if err := saveConfig(cfg); err != nil {
+		result.Errors = append(result.Errors, translator.NewProblem(translator.CodeConfig, path, "保存配置文件失败: %v", err))
+	} else {
+		result.Values[args[1]] = configKeys[args[1]].get(&cfg)
+	}
+
+	if *output == "json" {
+		printJSON(result)
+	} else {
+		printConfigResult(result)
+	}
+	return exitCode(result.Errors)
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+	"antigravity_translator/translator"
+)
+
+// appDirName 本工具在各平台配置/数据目录下使用的目录名
+const appDirName = "antigravity_translator"
+
+// supportedLocales 支持的目标语言
+var supportedLocales = []string{"zh-CN"}
+
+// Config 工具配置文件
+type Config struct {
+	InstallPath    string         `json:"install_path,omitempty"`    // Antigravity 安装路径
+	ContinueDir    string         `json:"continue_dir,omitempty"`    // Continue 扩展目录
+	ExtensionRoots []string       `json:"extension_roots,omitempty"` // 查找 Continue 扩展的编辑器扩展目录，为空时使用默认列表
+	BackupRoot     string         `json:"backup_root,omitempty"`     // 备份根目录
+	Targets        []string       `json:"targets,omitempty"`         // 默认汉化目标 ("antigravity", "continue")
+	RulePacks      []string       `json:"rule_packs,omitempty"`      // 启用的规则包，为空时全部启用
+	Locale         string         `json:"locale,omitempty"`          // 目标语言
+	Prompts        PromptDefaults `json:"prompts"`                   // 交互提示的默认回答
+
+	Bilingual           string   `json:"bilingual,omitempty"`            // 双语显示格式 ("paren"、"suffix")，为空时只显示译文
+	BilingualCategories []string `json:"bilingual_categories,omitempty"` // 双语显示生效的规则类别，为空时全部生效
+	BilingualMaxLength  int      `json:"bilingual_max_length,omitempty"` // 追加原文的最大字符数，0 使用默认值，负数表示不限
+}
+
+// PromptDefaults 交互提示的默认回答: "yes"、"no" 或空 (每次询问)
+type PromptDefaults struct {
+	UseDetectedPath string `json:"use_detected_path,omitempty"` // 是否使用自动检测到的路径
+	ConfirmApply    string `json:"confirm_apply,omitempty"`     // 是否开始汉化
+	ConfirmRestore  string `json:"confirm_restore,omitempty"`   // 是否确认还原
+	RememberPaths   string `json:"remember_paths,omitempty"`    // 是否记住确认过的路径 (默认记住)
+}
+
+// configPath 返回配置文件路径 (Windows: %APPDATA%，Linux: $XDG_CONFIG_HOME，macOS: ~/Library/Application Support)
+func configPath() (string, error) {
+	if p := os.Getenv("ANTIGRAVITY_TRANSLATOR_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, appDirName, "config.json"), nil
+}
+
+// userRulesPath 返回用户规则文件路径 (与配置文件在同一目录)
+func userRulesPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "user_rules.json"), nil
+}
+
+// hashesPath 返回原版文件哈希记录的路径 (与配置文件在同一目录，不随备份目录一起丢失)
+func hashesPath() string {
+	path, err := configPath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "pristine_hashes.json")
+}
+
+// loadUserRules 读取用户规则文件，无法读取时返回空的规则文件
+func loadUserRules() *rules.UserRules {
+	path, err := userRulesPath()
+	if err != nil {
+		return &rules.UserRules{}
+	}
+	u, _ := rules.LoadUserRules(path)
+	return u
+}
+
+// loadConfig 读取配置文件，文件不存在时返回空配置
+func loadConfig() (Config, error) {
+	var cfg Config
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(content, &cfg)
+	return cfg, err
+}
+
+// saveConfig 写入配置文件
+func saveConfig(cfg Config) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// rememberPath 将用户确认过的路径写入配置文件
+func rememberPath(key, value string) {
+	cfg, err := loadConfig()
+	if err != nil || cfg.Prompts.RememberPaths == "no" {
+		return
+	}
+	if setConfigValue(&cfg, key, value) == nil {
+		saveConfig(cfg)
+	}
+}
+
+// rulePackEnabled 判断规则包是否启用
+func (c Config) rulePackEnabled(name string) bool {
+	if len(c.RulePacks) == 0 {
+		return true
+	}
+	for _, p := range c.RulePacks {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ========================================
+// 备份目录与 Translator
+// ========================================
+
+// backupDirEnv 指定备份根目录的环境变量
+const backupDirEnv = "ANTIGRAVITY_BACKUP_DIR"
+
+// translatorOptions 创建 Translator 时使用的命令行参数，零值表示全部使用配置文件和默认值 (交互模式)
+type translatorOptions struct {
+	backupDir string                         // --backup-dir: 备份根目录
+	jobs      int                            // --jobs: 并行翻译的最大文件数，0 表示使用 CPU 核数
+	strict    bool                           // --strict: 规则命中次数不符合预期时报告错误并且不修改文件
+	trace     bool                           // --trace: 汉化时在每个文件旁写入替换记录
+	bilingual string                         // --bilingual: 双语显示格式 ("paren"、"suffix"、"off")，为空时使用配置文件
+	progress  func(translator.ProgressEvent) // 进度输出方式，为空时使用 printProgressText
+}
+
+// backupRootDir 返回备份根目录
+// 优先级: 命令行参数 (flag) > 环境变量 > 配置文件 > 平台默认目录
+func backupRootDir(flag string) (string, error) {
+	if flag != "" {
+		return filepath.Abs(flag)
+	}
+	if dir := os.Getenv(backupDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	if cfg, err := loadConfig(); err == nil && cfg.BackupRoot != "" {
+		return filepath.Abs(cfg.BackupRoot)
+	}
+	return backup.DefaultRoot()
+}
+
+// newTranslator 按命令行参数和配置文件创建 Translator
+// 无法确定备份目录时 Backups.Root 为空，需要备份的操作会报告错误
+func newTranslator(opts translatorOptions) *translator.Translator {
+	cfg, _ := loadConfig()
+	root, _ := backupRootDir(opts.backupDir)
+	progress := opts.progress
+	if progress == nil {
+		progress = printProgressText
+	}
+	return &translator.Translator{
+		Backups:     backup.Store{Root: root},
+		Jobs:        opts.jobs,
+		Progress:    progress,
+		PackEnabled: cfg.rulePackEnabled,
+		Strict:      opts.strict,
+		Trace:       opts.trace,
+		UserRules:   loadUserRules(),
+		HashesPath:  hashesPath(),
+		Bilingual:   cfg.bilingualOptions(opts.bilingual),
+	}
+}
+
+// bilingualOptions 返回双语显示设置，flag 为命令行参数 (优先于配置文件)；未启用时返回 nil
+func (c Config) bilingualOptions(flag string) *translator.BilingualOptions {
+	style := c.Bilingual
+	if flag != "" {
+		style = flag
+	}
+	if style == "" || style == "off" {
+		return nil
+	}
+	maxLength := c.BilingualMaxLength
+	switch {
+	case maxLength == 0:
+		maxLength = translator.DefaultBilingualMaxLength
+	case maxLength < 0:
+		maxLength = 0
+	}
+	return &translator.BilingualOptions{Style: style, MaxLength: maxLength, Categories: c.BilingualCategories}
+}
+
+// bilingualCategories 返回双语显示可选的规则类别: 按原文推断的用途和内置规则包的阶段类型
+func bilingualCategories() []string {
+	categories := append([]string{}, translator.RuleCategories...)
+	for _, name := range rules.Names() {
+		for _, phase := range rules.Lookup(name).Phases {
+			if !containsString(categories, phase.Kind) {
+				categories = append(categories, phase.Kind)
+			}
+		}
+	}
+	return append(categories, "user")
+}
+
+// validBilingualStyle 检查双语显示格式
+func validBilingualStyle(v string) error {
+	if v != "" && v != "off" && !containsString(engine.BilingualStyles, v) {
+		return fmt.Errorf("未知的双语显示格式: %s (支持: %s, off)", v, strings.Join(engine.BilingualStyles, ", "))
+	}
+	return nil
+}
+
+// ========================================
+// config get/set
+// ========================================
+
+// configKey 可通过 config get/set 读写的配置项
+type configKey struct {
+	get func(c *Config) string
+	set func(c *Config, v string) error
+}
+
+var configKeys = map[string]configKey{
+	"install_path": {
+		get: func(c *Config) string { return c.InstallPath },
+		set: func(c *Config, v string) error { c.InstallPath = v; return nil },
+	},
+	"continue_dir": {
+		get: func(c *Config) string { return c.ContinueDir },
+		set: func(c *Config, v string) error { c.ContinueDir = v; return nil },
+	},
+	"extension_roots": {
+		get: func(c *Config) string { return strings.Join(c.ExtensionRoots, ",") },
+		set: func(c *Config, v string) error { c.ExtensionRoots = splitList(v); return nil },
+	},
+	"backup_root": {
+		get: func(c *Config) string { return c.BackupRoot },
+		set: func(c *Config, v string) error { c.BackupRoot = v; return nil },
+	},
+	"targets": {
+		get: func(c *Config) string { return strings.Join(c.Targets, ",") },
+		set: func(c *Config, v string) error {
+			targets := splitList(v)
+			for _, t := range targets {
+				if t != "antigravity" && t != "continue" {
+					return fmt.Errorf("未知的汉化目标: %s", t)
+				}
+			}
+			c.Targets = targets
+			return nil
+		},
+	},
+	"rule_packs": {
+		get: func(c *Config) string { return strings.Join(c.RulePacks, ",") },
+		set: func(c *Config, v string) error {
+			packs := splitList(v)
+			for _, p := range packs {
+				if rules.Lookup(p) == nil {
+					return fmt.Errorf("未知的规则包: %s", p)
+				}
+			}
+			c.RulePacks = packs
+			return nil
+		},
+	},
+	"locale": {
+		get: func(c *Config) string { return c.Locale },
+		set: func(c *Config, v string) error {
+			if v != "" && !containsString(supportedLocales, v) {
+				return fmt.Errorf("不支持的语言: %s (支持: %s)", v, strings.Join(supportedLocales, ", "))
+			}
+			c.Locale = v
+			return nil
+		},
+	},
+	"bilingual": {
+		get: func(c *Config) string { return c.Bilingual },
+		set: func(c *Config, v string) error {
+			if err := validBilingualStyle(v); err != nil {
+				return err
+			}
+			if v == "off" {
+				v = ""
+			}
+			c.Bilingual = v
+			return nil
+		},
+	},
+	"bilingual_categories": {
+		get: func(c *Config) string { return strings.Join(c.BilingualCategories, ",") },
+		set: func(c *Config, v string) error {
+			categories := splitList(v)
+			valid := bilingualCategories()
+			for _, cat := range categories {
+				if !containsString(valid, cat) {
+					return fmt.Errorf("未知的规则类别: %s (支持: %s)", cat, strings.Join(valid, ", "))
+				}
+			}
+			c.BilingualCategories = categories
+			return nil
+		},
+	},
+	"bilingual_max_length": {
+		get: func(c *Config) string {
+			if c.BilingualMaxLength == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.BilingualMaxLength)
+		},
+		set: func(c *Config, v string) error {
+			if v == "" {
+				c.BilingualMaxLength = 0
+				return nil
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("bilingual_max_length 必须是整数: %s", v)
+			}
+			c.BilingualMaxLength = n
+			return nil
+		},
+	},
+	"prompts.use_detected_path": promptKey(func(c *Config) *string { return &c.Prompts.UseDetectedPath }),
+	"prompts.confirm_apply":     promptKey(func(c *Config) *string { return &c.Prompts.ConfirmApply }),
+	"prompts.confirm_restore":   promptKey(func(c *Config) *string { return &c.Prompts.ConfirmRestore }),
+	"prompts.remember_paths":    promptKey(func(c *Config) *string { return &c.Prompts.RememberPaths }),
+}
+
+// promptKey 构造提示默认值的配置项，只接受 yes、no 或空
+func promptKey(field func(c *Config) *string) configKey {
+	return configKey{
+		get: func(c *Config) string { return *field(c) },
+		set: func(c *Config, v string) error {
+			v = strings.ToLower(v)
+			if v != "" && v != "yes" && v != "no" {
+				return fmt.Errorf("提示默认值只能是 yes、no 或空")
+			}
+			*field(c) = v
+			return nil
+		},
+	}
+}
+
+// configKeyNames 返回所有配置项名称 (已排序)
+func configKeyNames() []string {
+	names := make([]string, 0, len(configKeys))
+	for name := range configKeys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func setConfigValue(cfg *Config, key, value string) error {
+	k, ok := configKeys[key]
+	if !ok {
+		return fmt.Errorf("未知的配置项: %s", key)
+	}
+	return k.set(cfg, value)
+}
+
+// splitList 解析逗号分隔的列表
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"antigravity_translator/targets"
+	"antigravity_translator/translator"
+)
+
+// ========================================
+// 安装与扩展发现 (读取配置后调用 targets 包)
+// ========================================
+
+// findAntigravityInstallations 检测所有 Antigravity 安装
+// 顺序: 配置文件中记住的路径 > 注册表 > 常见安装位置
+func findAntigravityInstallations(ctx context.Context) []targets.Installation {
+	cfg, _ := loadConfig()
+	return targets.FindInstallations(ctx, cfg.InstallPath)
+}
+
+// findAntigravityInstallPath 自动检测 Antigravity 安装路径 (返回优先级最高的一个)
+func findAntigravityInstallPath(ctx context.Context) string {
+	installs := findAntigravityInstallations(ctx)
+	if len(installs) == 0 {
+		return ""
+	}
+	return installs[0].Path
+}
+
+// findContinueExtensions 在所有编辑器扩展目录中查找 Continue 扩展
+// 顺序: 配置文件中记住的目录 > extension_roots 中各目录 (未配置时使用默认列表)
+func findContinueExtensions(ctx context.Context) []*targets.ContinueExtension {
+	cfg, _ := loadConfig()
+	return targets.FindContinueExtensions(ctx, cfg.ContinueDir, cfg.ExtensionRoots)
+}
+
+// findContinueExtension 返回第一个检测到的 Continue 扩展，未找到时返回 nil
+func findContinueExtension(ctx context.Context) *targets.ContinueExtension {
+	if found := findContinueExtensions(ctx); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// continueExtensionLabel 返回扩展的显示名称
+func continueExtensionLabel(ext *targets.ContinueExtension) string {
+	var tags []string
+	if ext.Editor != "" {
+		tags = append(tags, ext.Editor)
+	}
+	if ext.Version != "" {
+		tags = append(tags, "v"+ext.Version)
+	}
+	if ext.Platform != "" {
+		tags = append(tags, ext.Platform)
+	}
+	if len(tags) == 0 {
+		return ext.Path
+	}
+	return ext.Path + " (" + strings.Join(tags, ", ") + ")"
+}
+
+// obsoleteWarnings 为残留的旧版本生成警告
+func obsoleteWarnings(dirs []string) []translator.Problem {
+	var warnings []translator.Problem
+	for _, dir := range dirs {
+		warnings = append(warnings, translator.NewProblem(translator.CodeObsoleteExtension, dir, "发现未被编辑器使用的 Continue 扩展版本，可以删除: %s", dir))
+	}
+	return warnings
+}
+
+// obsoleteContinueWarnings 为检测到的扩展残留的旧版本生成警告
+func obsoleteContinueWarnings(ext *targets.ContinueExtension) []translator.Problem {
+	return obsoleteWarnings(ext.Obsolete)
+}
+
+// obsoleteContinueWarningsFor 用户直接指定扩展目录时，检查同一扩展目录下是否残留旧版本
+func obsoleteContinueWarningsFor(dir string) []translator.Problem {
+	return obsoleteWarnings(targets.ObsoleteContinueVersions(dir))
+}
+
+// installationLabel 返回安装的显示名称
+func installationLabel(inst targets.Installation) string {
+	label := inst.Path
+	var tags []string
+	if inst.Version != "" {
+		tags = append(tags, "v"+inst.Version)
+	}
+	tags = append(tags, inst.Channel, installTypeLabel(inst.InstallType))
+	return label + " (" + strings.Join(tags, ", ") + ")"
+}
+
+func installTypeLabel(installType string) string {
+	switch installType {
+	case "user":
+		return "用户安装"
+	case "system":
+		return "系统安装"
+	default:
+		return "便携版"
+	}
+}
+
+// parseSelection 解析 "1,3"、"1-3"、"a" 形式的多选输入，返回从 0 开始的下标
+func parseSelection(input string, n int) []int {
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input == "a" || input == "all" {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+
+	picked := make(map[int]bool)
+	for _, part := range splitList(input) {
+		var from, to int
+		if strings.Contains(part, "-") {
+			if c, _ := fmt.Sscanf(part, "%d-%d", &from, &to); c != 2 {
+				return nil
+			}
+		} else {
+			if c, _ := fmt.Sscanf(part, "%d", &from); c != 1 {
+				return nil
+			}
+			to = from
+		}
+		if from < 1 || to > n || from > to {
+			return nil
+		}
+		for i := from; i <= to; i++ {
+			picked[i-1] = true
+		}
+	}
+
+	var indexes []int
+	for i := range picked {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ========================================
+// 锚定规则 (上下文锚点)
+// ========================================
+//
+// "Active"、"Agent"、"History" 这类通用字符串在 bundle 中既是界面文字，也是枚举值、事件名和
+// 对象键。锚定规则只替换出现在指定位置的字符串字面量，不需要把 label:"..." 之类的上下文手写进原文:
+//   - 属性 (Props): label:"x"、label: "x"、"aria-label":"x"、label="x"、label={"x"}
+//   - 调用 (Callees): 作为函数的第一个参数，如 t("x")、showInformationMessage("x")；
+//     函数名 "t" 也匹配 i18n.t("x") 这样带对象前缀的调用，写成 "i18n.t" 时只匹配该前缀
+//
+// 原文和译文是用同一种引号括起的完整字符串字面量，匹配 "..."、'...'、`...` 任意一种，
+// 译文使用匹配到的引号；锚点本身原样保留。
+
+// UIProps 通常承载界面文字的属性名
+var UIProps = []string{"label", "title", "placeholder", "children", "aria-label"}
+
+// Anchor 上下文锚点，Props 和 Callees 至少设置一个，任意一个位置匹配即可
+type Anchor struct {
+	Props   []string // 属性名 (对象键或 JSX 属性)
+	Callees []string // 被调用的函数名，可以带对象前缀 (如 "window.showInformationMessage")
+}
+
+// Props 创建属性锚点
+func Props(names ...string) *Anchor {
+	return &Anchor{Props: names}
+}
+
+// Callees 创建调用锚点
+func Callees(names ...string) *Anchor {
+	return &Anchor{Callees: names}
+}
+
+// String 返回锚点的可读描述，如 "label|title"、"t()"
+func (a *Anchor) String() string {
+	if a == nil {
+		return ""
+	}
+	var parts []string
+	parts = append(parts, a.Props...)
+	for _, callee := range a.Callees {
+		parts = append(parts, callee+"()")
+	}
+	return strings.Join(parts, "|")
+}
+
+// identifierPattern 属性名和函数名允许的字符 (属性名还可以包含 "-"，如 aria-label)
+var identifierPattern = regexp.MustCompile(`^[\w$-]+(\.[\w$]+)*$`)
+
+// anchorPattern 返回匹配锚点 (直到字符串字面量的开头引号之前) 的正则表达式
+// 锚点前必须是标识符之外的字符，避免 data-label、xlabel 之类的名称被当成 label
+func anchorPattern(a *Anchor) (string, error) {
+	if len(a.Props) == 0 && len(a.Callees) == 0 {
+		return "", fmt.Errorf("锚点没有设置属性名或函数名")
+	}
+	var alts []string
+	if len(a.Props) > 0 {
+		var names []string
+		for _, name := range a.Props {
+			if !identifierPattern.MatchString(name) || strings.Contains(name, ".") {
+				return "", fmt.Errorf("无效的属性名 %q", name)
+			}
+			q := regexp.QuoteMeta(name)
+			names = append(names, q, `"`+q+`"`, `'`+q+`'`)
+		}
+		alts = append(alts, `(?:^|[^\w$.-])(?:`+strings.Join(names, "|")+`)\s*[:=]\s*(?:\{\s*)?`)
+	}
+	if len(a.Callees) > 0 {
+		var names []string
+		for _, name := range a.Callees {
+			if !identifierPattern.MatchString(name) || strings.Contains(name, "-") {
+				return "", fmt.Errorf("无效的函数名 %q", name)
+			}
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		alts = append(alts, `(?:^|[^\w$])(?:`+strings.Join(names, "|")+`)\(\s*`)
+	}
+	return strings.Join(alts, "|"), nil
+}
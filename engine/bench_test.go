@@ -0,0 +1,74 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+)
+
+// benchSize 合成 bundle 的大小 (字节)
+const benchSize = 1 << 20
+
+// benchmarkApply 对规则包的合成数据应用整个规则集
+func benchmarkApply(b *testing.B, pack, target string) {
+	set := rules.Lookup(pack)
+	content := engine.SyntheticBundle(benchSize, set)
+	ctx := context.Background()
+	engine.ApplyTarget(ctx, target, content, set) // 编译规则，不计入耗时
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := engine.ApplyTarget(ctx, target, content, set); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkLegacy 同 benchmarkApply，使用逐条替换的旧实现 (engine.ApplyRule)
+func benchmarkLegacy(b *testing.B, pack, target string) {
+	set := rules.Lookup(pack)
+	content := engine.SyntheticBundle(benchSize, set)
+	engine.ApplyLegacy(target, content, set) // 编译规则，不计入耗时
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine.ApplyLegacy(target, content, set)
+	}
+}
+
+func BenchmarkApplyMain(b *testing.B)      { benchmarkApply(b, "main", "antigravity.main") }
+func BenchmarkLegacyMain(b *testing.B)     { benchmarkLegacy(b, "main", "antigravity.main") }
+func BenchmarkApplyChat(b *testing.B)      { benchmarkApply(b, "chat", "antigravity.chat") }
+func BenchmarkLegacyChat(b *testing.B)     { benchmarkLegacy(b, "chat", "antigravity.chat") }
+func BenchmarkApplyContinue(b *testing.B)  { benchmarkApply(b, "continue", "continue.gui") }
+func BenchmarkLegacyContinue(b *testing.B) { benchmarkLegacy(b, "continue", "continue.gui") }
+
+// BenchmarkReplacer 只测单个阶段的 Aho-Corasick 扫描
+func BenchmarkReplacer(b *testing.B) {
+	set := rules.Lookup("chat")
+	phase := set.Phases[0]
+	content := []byte(engine.SyntheticBundle(benchSize, set))
+	r := phase.Replacer()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Replace(content)
+	}
+}
+
+// BenchmarkLegacyReplacer 同 BenchmarkReplacer，逐条规则 strings.Replace
+func BenchmarkLegacyReplacer(b *testing.B) {
+	set := rules.Lookup("chat")
+	phase := set.Phases[0]
+	content := engine.SyntheticBundle(benchSize, set)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out := content
+		for _, rule := range phase.Rules() {
+			out, _ = engine.ApplyRule(rule, out)
+		}
+	}
+}
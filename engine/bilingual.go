@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"antigravity_translator/literals"
+)
+
+// ========================================
+// 双语显示
+// ========================================
+//
+// 双语模式下译文字面量同时带上原文，如 "始终继续 (Always Proceed)"，方便对照英文文档和错误报告。
+// 只转换原文和译文都是单个完整字符串字面量的规则 (普通、锚定、Continue 的带引号规则等)，
+// 原文追加在译文字面量的引号之内:
+//   - 普通规则按字节替换，输出的引号与原文相同，原文的写法 (包括转义) 在同样的引号中一定合法
+//   - 转义无关规则 (MatchEscapes) 的原文按解码后的文本书写，输出时与译文一起按匹配到的字面量重新编码
+//   - 含 ${...} 表达式的模板字面量不转换，以免表达式在输出中出现两次
+//   - 原文已含中文的规则 (修改已有译文的规则，如 "提及" → "引用") 不转换，原文不是英文
+
+// 双语显示的格式
+const (
+	BilingualParen  = "paren"  // 译文 (原文)
+	BilingualSuffix = "suffix" // 译文 · 原文
+)
+
+// BilingualStyles 支持的双语显示格式
+var BilingualStyles = []string{BilingualParen, BilingualSuffix}
+
+// Bilingual 返回同时显示译文和原文的规则；原文长度 (字符数) 超过 maxLength (大于 0 时)、原文含中文、
+// 或规则不是单个字面量之间的替换时原样返回，ok 为 false
+func (r Rule) Bilingual(style string, maxLength int) (Rule, bool) {
+	if r.Mode&MatchRegexp != 0 || r.Mode&MatchWhitespace != 0 {
+		return r, false
+	}
+	from, quote, ok := singleLiteral(r.From)
+	if !ok {
+		return r, false
+	}
+	to, toQuote, ok := singleLiteral(r.To)
+	if !ok || toQuote != quote || strings.Contains(from, "${") || to == from || literals.HasCJK(from) {
+		return r, false
+	}
+	if maxLength > 0 && utf8.RuneCountInString(from) > maxLength {
+		return r, false
+	}
+	switch style {
+	case BilingualParen:
+		to = fmt.Sprintf("%s (%s)", to, from)
+	case BilingualSuffix:
+		to = fmt.Sprintf("%s · %s", to, from)
+	default:
+		return r, false
+	}
+	r.To = string(quote) + to + string(quote)
+	return r, true
+}
+
+// singleLiteral 拆开单个完整的字符串字面量，返回引号之内的文本和引号；
+// 文本中含有未转义的同种引号 (如 `"a","b"`) 时不是单个字面量
+func singleLiteral(s string) (string, byte, bool) {
+	if len(s) < 2 || !isQuote(s[0]) || s[len(s)-1] != s[0] {
+		return "", 0, false
+	}
+	text := s[1 : len(s)-1]
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if i == len(text)-1 {
+				return "", 0, false // 结尾的引号被转义
+			}
+			i++
+		case s[0]:
+			return "", 0, false
+		}
+	}
+	return text, s[0], true
+}
+
+// MapRules 返回规则集的副本，每条规则经 fn 转换 (在阶段首次使用时转换并编译，规则的顺序和 ID 不变)
+func (s *RuleSet) MapRules(fn func(phase *Phase, rule Rule) Rule) *RuleSet {
+	mapped := &RuleSet{Name: s.Name, Phases: make([]*Phase, len(s.Phases))}
+	for i, p := range s.Phases {
+		mapped.Phases[i] = NewPhase(p.Kind, p.Category, func() []Rule {
+			rules := slices.Clone(p.Rules())
+			for j, rule := range rules {
+				rules[j] = fn(p, rule)
+			}
+			return rules
+		})
+	}
+	return mapped
+}
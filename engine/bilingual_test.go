@@ -0,0 +1,32 @@
+package engine
+
+import "testing"
+
+// TestRuleBilingual 只有原文和译文都是单个字面量、原文不含中文的规则带上原文
+func TestRuleBilingual(t *testing.T) {
+	tests := []struct {
+		name   string
+		rule   Rule
+		style  string
+		max    int
+		want   string
+		wantOK bool
+	}{
+		{"括号", Rule{From: `"Always Proceed"`, To: `"始终继续"`}, BilingualParen, 0, `"始终继续 (Always Proceed)"`, true},
+		{"后缀", Rule{From: `'Always Proceed'`, To: `'始终继续'`}, BilingualSuffix, 0, `'始终继续 · Always Proceed'`, true},
+		{"原文含中文", Rule{From: `"提及"`, To: `"引用"`}, BilingualParen, 0, `"引用"`, false},
+		{"原文部分含中文", Rule{From: `"Open 设置"`, To: `"打开设置"`}, BilingualParen, 0, `"打开设置"`, false},
+		{"原文过长", Rule{From: `"Always Proceed"`, To: `"始终继续"`}, BilingualParen, 5, `"始终继续"`, false},
+		{"引号不同", Rule{From: `"Run"`, To: `'运行'`}, BilingualParen, 0, `'运行'`, false},
+		{"模板表达式", Rule{From: "`Run ${a}`", To: "`运行 ${a}`"}, BilingualParen, 0, "`运行 ${a}`", false},
+		{"不是字面量", Rule{From: `Run command`, To: `运行命令`}, BilingualParen, 0, `运行命令`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.rule.Bilingual(tt.style, tt.max)
+			if got.To != tt.want || ok != tt.wantOK {
+				t.Errorf("Bilingual() = %q, %v; want %q, %v", got.To, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+// Package engine 实现汉化使用的多模式替换引擎。
+//
+// 规则 (Rule) 按阶段 (Phase) 组织，多个阶段组成一个规则集 (RuleSet)。同一阶段的规则
+// 编译成一个 Aho-Corasick 自动机，对内容只扫描一遍，按最左最长语义完成替换并精确统计
+// 每条规则的命中次数；各阶段按顺序执行，后面的阶段可以匹配前面阶段的译文。
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ========================================
+// 多模式替换引擎 (Aho-Corasick)
+// ========================================
+
+// Rule 一条替换规则，原文包含 ${$1}、${*} 等占位符或设置了 Mode、Anchor 时为模式规则 (见 pattern.go)，否则为字面量规则
+type Rule struct {
+	Kind   string // 规则类型 ("normal", "template", "variable", "quoted", "raw", "regex", "anchored")
+	From   string
+	To     string
+	Mode   Mode    // 匹配方式，默认逐字节匹配
+	Limit  int     // 每次应用最多替换的次数，超出的匹配保持原样；0 表示不限
+	Anchor *Anchor // 上下文锚点，非空时只替换出现在锚点位置的字符串字面量 (见 anchor.go)
+	Scope  *Scope  // 作用范围，非空时只对指定目标或标记区间生效 (见 scope.go)
+	Expect *Expect // 在每个文件中预期的命中次数，不符时记录到 Stats.Violations (见 expect.go)
+}
+
+// Mode 规则的匹配方式，可以组合使用
+type Mode uint8
+
+const (
+	// MatchWhitespace 原文中的每段空白匹配任意一段空白 (空格、制表符、换行)，格式化工具改变换行和缩进后仍能匹配
+	// 译文中的第 k 个 ${~} 原样输出原文中第 k 段含换行的空白所匹配到的文本
+	MatchWhitespace Mode = 1 << iota
+
+	// MatchEscapes 原文按解码后的文本书写，匹配 \uXXXX、\xXX、\" 等各种等价写法，
+	// 译文按匹配到的字面量的引号和转义方式重新编码 (见 escape.go)
+	MatchEscapes
+
+	// MatchRegexp 原文是 RE2 正则表达式，译文中的 $1、${1}、${name} 引用编号或命名子匹配，$$ 输出 "$" (见 regex.go)
+	// 不能与其他匹配方式组合
+	MatchRegexp
+)
+
+// IsPattern 判断规则是否需要按模式匹配 (不能参与 Aho-Corasick 扫描)
+func (r Rule) IsPattern() bool {
+	return r.Mode != 0 || r.Anchor != nil || hasPlaceholders(r.From)
+}
+
+// WithMode 为一组规则设置匹配方式
+func WithMode(mode Mode, rules []Rule) []Rule {
+	for i := range rules {
+		rules[i].Mode |= mode
+	}
+	return rules
+}
+
+// Replacer 由一组规则编译出的 Aho-Corasick 自动机
+// 对输入只扫描一遍，按最左最长 (leftmost-longest) 语义选择不重叠的匹配并同时构建输出，
+// 每条规则的命中次数精确统计。From 相同的规则只有第一条生效，模式规则会被忽略。Replacer 可以并发使用。
+type Replacer struct {
+	rules    []Rule
+	classes  [256]int32 // 字节到字符类的映射，未出现在任何规则中的字节都属于类 0
+	nclass   int
+	trans    []int32 // 稠密转移表: trans[state*nclass+class]
+	depth    []int32 // 状态对应前缀的长度
+	match    []int32 // 以该状态结尾的最长规则下标，-1 表示没有
+	matchLen []int32
+	shorter  []int32 // 以该状态结尾、比 match 短的下一条规则所在的状态，-1 表示没有；match 超出次数限制时沿此回退
+}
+
+// NewReplacer 编译规则，空的 From 和模式规则会被忽略
+func NewReplacer(rules []Rule) *Replacer {
+	r := &Replacer{rules: rules}
+
+	// 1. 字符类: 只给规则中出现过的字节分配独立的类，压缩转移表
+	r.nclass = 1
+	for _, rule := range rules {
+		if rule.IsPattern() {
+			continue
+		}
+		for i := 0; i < len(rule.From); i++ {
+			if b := rule.From[i]; r.classes[b] == 0 {
+				r.classes[b] = int32(r.nclass)
+				r.nclass++
+			}
+		}
+	}
+
+	// 2. 字典树
+	newState := func(depth int) int32 {
+		for c := 0; c < r.nclass; c++ {
+			r.trans = append(r.trans, -1)
+		}
+		r.depth = append(r.depth, int32(depth))
+		r.match = append(r.match, -1)
+		r.matchLen = append(r.matchLen, 0)
+		r.shorter = append(r.shorter, -1)
+		return int32(len(r.depth) - 1)
+	}
+	newState(0)
+	for i, rule := range rules {
+		if rule.From == "" || rule.IsPattern() {
+			continue
+		}
+		state := int32(0)
+		for j := 0; j < len(rule.From); j++ {
+			idx := int(state)*r.nclass + int(r.classes[rule.From[j]])
+			if r.trans[idx] < 0 {
+				next := newState(j + 1)
+				r.trans[idx] = next
+			}
+			state = r.trans[idx]
+		}
+		if r.match[state] < 0 {
+			r.match[state] = int32(i)
+			r.matchLen[state] = int32(len(rule.From))
+		}
+	}
+
+	// 3. 按广度优先计算失败转移，补全为 DFA，并沿失败链继承最长匹配
+	// owner[u] 是 match[u] 所在的状态 (u 自身或失败链上的状态)，-1 表示没有
+	fail := make([]int32, len(r.depth))
+	owner := make([]int32, len(r.depth))
+	owner[0] = -1
+	queue := make([]int32, 0, len(r.depth))
+	for c := 0; c < r.nclass; c++ {
+		if next := r.trans[c]; next < 0 {
+			r.trans[c] = 0
+		} else {
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if r.match[u] < 0 {
+			r.match[u] = r.match[fail[u]]
+			r.matchLen[u] = r.matchLen[fail[u]]
+			owner[u] = owner[fail[u]]
+			r.shorter[u] = r.shorter[fail[u]]
+		} else {
+			owner[u] = u
+			r.shorter[u] = owner[fail[u]]
+		}
+		for c := 0; c < r.nclass; c++ {
+			idx := int(u)*r.nclass + c
+			fallback := r.trans[int(fail[u])*r.nclass+c]
+			if next := r.trans[idx]; next < 0 {
+				r.trans[idx] = fallback
+			} else {
+				fail[next] = fallback
+				queue = append(queue, next)
+			}
+		}
+	}
+	return r
+}
+
+// Rules 返回编译时使用的规则
+func (r *Replacer) Rules() []Rule { return r.rules }
+
+// exhausted 判断规则是否已达到次数限制
+func (r *Replacer) exhausted(rule int32, counts []int) bool {
+	limit := r.rules[rule].Limit
+	return limit > 0 && counts[rule] >= limit
+}
+
+// Replace 单次扫描替换，返回结果和每条规则的命中次数 (与规则下标对应)
+// 没有任何命中时直接返回 src
+func (r *Replacer) Replace(src []byte) ([]byte, []int) {
+	return r.replace(src, nil)
+}
+
+// replace 同 Replace，edit 不为空时对每处替换调用一次 (见 trace.go)
+func (r *Replacer) replace(src []byte, edit editFunc) ([]byte, []int) {
+	counts := make([]int, len(r.rules))
+	var out []byte
+	last := 0 // src[:last] 已写入 out
+
+	state := int32(0)
+	best, bestStart, bestEnd := int32(-1), 0, 0
+
+	commit := func() {
+		if out == nil {
+			out = make([]byte, 0, len(src)+len(src)/8)
+		}
+		out = append(out, src[last:bestStart]...)
+		if edit != nil {
+			edit(int(best), bestStart, bestEnd, len(out), len(out)+len(r.rules[best].To))
+		}
+		out = append(out, r.rules[best].To...)
+		counts[best]++
+		last = bestEnd
+	}
+
+	for i := 0; i < len(src); {
+		state = r.trans[int(state)*r.nclass+int(r.classes[src[i]])]
+		i++
+
+		// 记录起点最靠左的匹配，起点相同时取更长的；超出次数限制的规则不再匹配，改用同一位置结尾的更短的规则
+		m, length := r.match[state], r.matchLen[state]
+		for s := state; m >= 0 && r.exhausted(m, counts); {
+			if s = r.shorter[s]; s < 0 {
+				m = -1
+			} else {
+				m, length = r.match[s], r.matchLen[s]
+			}
+		}
+		if m >= 0 {
+			start := i - int(length)
+			if best < 0 || start < bestStart || (start == bestStart && i > bestEnd) {
+				best, bestStart, bestEnd = m, start, i
+			}
+		}
+
+		// 当前前缀的起点已越过候选匹配的起点，之后不可能出现更靠左或更长的匹配
+		if best >= 0 && i-int(r.depth[state]) > bestStart {
+			commit()
+			i, state, best = bestEnd, 0, -1
+		}
+	}
+	if best >= 0 {
+		commit()
+	}
+
+	if out == nil {
+		return src, counts
+	}
+	return append(out, src[last:]...), counts
+}
+
+// ========================================
+// 规则阶段与规则集
+// ========================================
+
+// Category 阶段命中的规则数计入 Stats 的哪个字段
+type Category int
+
+const (
+	CategoryNormal   Category = iota // 计入 Stats.NormalCount
+	CategoryTemplate                 // 计入 Stats.TemplateCount
+	CategoryVariable                 // 计入 Stats.VariableCount
+	CategoryRegex                    // 计入 Stats.RegexCount
+)
+
+// Phase 规则集中的一个阶段，同一阶段的字面量规则在一次扫描中完成替换，模式规则随后依次执行
+// 规则在首次使用时才构建和编译，按目标和区间分组的自动机在首次用于该目标时编译，Phase 可以并发使用
+type Phase struct {
+	Kind     string
+	Category Category
+	build    func() []Rule
+
+	once     sync.Once
+	rules    []Rule
+	keys     []string // 规则内容的短哈希 (见 RuleID)
+	patterns []indexedPattern
+	errs     []error
+
+	mu     sync.Mutex
+	groups map[string][]*ruleGroup // 目标 ID -> 规则分组 (见 scope.go)
+}
+
+// indexedPattern 阶段中的模式规则及其在规则列表中的下标
+type indexedPattern struct {
+	index int
+	*pattern
+}
+
+// NewPhase 创建一个阶段，build 在首次使用时调用
+func NewPhase(kind string, category Category, build func() []Rule) *Phase {
+	return &Phase{Kind: kind, Category: category, build: build}
+}
+
+// Replacer 返回未指定目标时对整个文件生效的字面量规则编译出的自动机
+func (p *Phase) Replacer() *Replacer {
+	p.once.Do(p.compile)
+	groups := p.groupsFor("")
+	return groups[len(groups)-1].replacer
+}
+
+// compile 构建规则并编译模式规则；无法编译的模式规则被跳过并记录到 Errors
+func (p *Phase) compile() {
+	rules := p.build()
+	p.rules = rules
+	p.keys = ruleKeys(rules)
+	for i, rule := range rules {
+		if !rule.IsPattern() {
+			continue
+		}
+		pat, err := compilePattern(rule)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("%s 规则 %q: %w", rule.Kind, rule.From, err))
+			continue
+		}
+		p.patterns = append(p.patterns, indexedPattern{i, pat})
+	}
+}
+
+// Errors 返回编译失败的模式规则
+func (p *Phase) Errors() []error {
+	p.once.Do(p.compile)
+	return p.errs
+}
+
+// Rules 返回本阶段的规则
+func (p *Phase) Rules() []Rule {
+	p.once.Do(p.compile)
+	return p.rules
+}
+
+// Keys 返回本阶段每条规则内容的短哈希，即规则 ID 的最后一段 (见 RuleID)
+func (p *Phase) Keys() []string {
+	p.once.Do(p.compile)
+	return p.keys
+}
+
+// Apply 对目标 (ID，为空表示不按目标过滤) 应用本阶段的规则并把命中累计到 stats
+func (p *Phase) Apply(target string, content []byte, stats *Stats) []byte {
+	return p.apply(target, content, stats, nil)
+}
+
+// apply 同 Apply，tr 不为空时记录每处替换 (见 trace.go)
+func (p *Phase) apply(target string, content []byte, stats *Stats, tr *tracer) []byte {
+	p.once.Do(p.compile)
+	counts := make([]int, len(p.rules))
+	skipped := make([]int, len(p.rules))
+	samples := make(map[int][]Sample)
+	sample := func(index int, from, to []byte) {
+		if len(samples[index]) < MaxSamples {
+			samples[index] = append(samples[index], Sample{From: string(from), To: string(to)})
+		}
+	}
+	for _, g := range p.groupsFor(target) {
+		content = g.apply(content, counts, skipped, sample, tr)
+	}
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		rule := p.rules[i]
+		hit := RuleHit{Kind: rule.Kind, From: rule.From, To: rule.To, Count: n, Samples: samples[i]}
+		if rule.Anchor != nil {
+			hit.Anchor = rule.Anchor.String()
+		}
+		if rule.Scope != nil {
+			hit.Scope = rule.Scope.String()
+		}
+		stats.Rules = append(stats.Rules, hit)
+		stats.count(p.Category)
+	}
+	for i, rule := range p.rules {
+		if skipped[i] > 0 {
+			stats.Skipped = append(stats.Skipped, Skip{Kind: rule.Kind, From: rule.From, Count: skipped[i]})
+		}
+		if rule.Expect != nil && rule.Scope.AppliesTo(target) && !rule.Expect.Allows(counts[i]) {
+			stats.Violations = append(stats.Violations, Violation{Kind: rule.Kind, From: rule.From, Expect: rule.Expect.String(), Count: counts[i], Missing: counts[i] < rule.Expect.Min, Skipped: skipped[i]})
+		}
+	}
+	return content
+}
+
+// RuleSet 一个规则包: 按顺序执行的若干阶段
+type RuleSet struct {
+	Name   string
+	Phases []*Phase
+}
+
+// Apply 依次应用规则集 (不按目标过滤规则)，ctx 取消时在阶段之间停止并返回 ctx.Err()
+func Apply(ctx context.Context, content string, sets ...*RuleSet) (string, Stats, error) {
+	return ApplyTarget(ctx, "", content, sets...)
+}
+
+// ApplyTarget 对某个目标 (ID，如 "antigravity.main") 依次应用规则集，只对其他目标生效的规则被跳过
+func ApplyTarget(ctx context.Context, target, content string, sets ...*RuleSet) (string, Stats, error) {
+	out, stats, _, err := applyTarget(ctx, target, content, sets, nil)
+	return out, stats, err
+}
+
+// ApplyTargetTraced 同 ApplyTarget，同时返回每处替换在结果中的位置 (按偏移排序，见 trace.go)
+func ApplyTargetTraced(ctx context.Context, target, content string, sets ...*RuleSet) (string, Stats, []Replacement, error) {
+	return applyTarget(ctx, target, content, sets, &tracer{})
+}
+
+// applyTarget 依次应用规则集，tr 不为空时记录替换
+func applyTarget(ctx context.Context, target, content string, sets []*RuleSet, tr *tracer) (string, Stats, []Replacement, error) {
+	stats := Stats{}
+	buf := []byte(content)
+	for _, set := range sets {
+		for _, p := range set.Phases {
+			if err := ctx.Err(); err != nil {
+				return content, Stats{}, nil, err
+			}
+			if tr != nil {
+				tr.set, tr.phase, tr.keys = set.Name, p.Kind, p.Keys()
+			}
+			buf = p.apply(target, buf, &stats, tr)
+		}
+	}
+	var trace []Replacement
+	if tr != nil {
+		trace = tr.entries
+	}
+	return string(buf), stats, trace, nil
+}
+
+// RulesFromMap 把 map 形式的规则转换为列表 (按原文排序，保证结果稳定)
+func RulesFromMap(kind string, m map[string]string) []Rule {
+	rules := make([]Rule, 0, len(m))
+	for from, to := range m {
+		rules = append(rules, Rule{Kind: kind, From: from, To: to})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].From < rules[j].From })
+	return rules
+}
+
+// RulesFromPairs 把有序的规则对转换为列表
+func RulesFromPairs(kind string, pairs [][2]string) []Rule {
+	rules := make([]Rule, 0, len(pairs))
+	for _, pair := range pairs {
+		rules = append(rules, Rule{Kind: kind, From: pair[0], To: pair[1]})
+	}
+	return rules
+}
+
+// RulesFromTable 把 Rule 形式的规则表 (可设置 Anchor、Limit 等) 转换为指定类型的规则列表 (不修改原表)
+func RulesFromTable(kind string, table []Rule) []Rule {
+	rules := make([]Rule, 0, len(table))
+	for _, rule := range table {
+		rule.Kind = kind
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// RulesFromRegexps 把正则规则表转换为指定类型的规则列表 (设置 MatchRegexp，不修改原表)
+func RulesFromRegexps(kind string, table []Rule) []Rule {
+	rules := RulesFromTable(kind, table)
+	return WithMode(MatchRegexp, rules)
+}
+
+// ApplyRule 单独应用一条规则 (字面量规则使用 strings.ReplaceAll)，返回结果和替换次数
+// 限定了区间的规则只替换区间内的内容，目标限定由调用方判断 (Scope.AppliesTo)；供基准测试对比逐条替换的旧实现
+func ApplyRule(rule Rule, content string) (string, int) {
+	if start, end := rule.Scope.region(); start != "" {
+		scoped := rule
+		scoped.Scope = nil
+		total := 0
+		out := applyRanges([]byte(content), start, end, func(src []byte) []byte {
+			s, n := ApplyRule(scoped, string(src))
+			total += n
+			return []byte(s)
+		})
+		return string(out), total
+	}
+	if !rule.IsPattern() {
+		count := strings.Count(content, rule.From)
+		if count == 0 {
+			return content, 0
+		}
+		if rule.Limit > 0 && count > rule.Limit {
+			count = rule.Limit
+		}
+		return strings.Replace(content, rule.From, rule.To, count), count
+	}
+	pat, err := compilePattern(rule)
+	if err != nil {
+		return content, 0
+	}
+	out, count, _ := pat.replace([]byte(content), nil, nil)
+	return string(out), count
+}
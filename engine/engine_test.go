@@ -0,0 +1,63 @@
+package engine
+
+import "testing"
+
+// TestReplacerLimit 规则超出次数限制后，跳过的区间中更短或重叠的匹配仍然生效
+func TestReplacerLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		src   string
+		want  string
+		hits  []int
+	}{
+		{
+			name:  "更短的后缀规则",
+			rules: []Rule{{From: "ab", To: "X", Limit: 1}, {From: "b", To: "Y"}},
+			src:   "ab ab ab",
+			want:  "X aY aY",
+			hits:  []int{1, 2},
+		},
+		{
+			name:  "起点相同的更短规则",
+			rules: []Rule{{From: "ab", To: "X", Limit: 1}, {From: "a", To: "Z"}},
+			src:   "ab ab",
+			want:  "X Zb",
+			hits:  []int{1, 1},
+		},
+		{
+			name:  "重叠的规则",
+			rules: []Rule{{From: "abc", To: "X", Limit: 1}, {From: "bcd", To: "Y"}},
+			src:   "abc abcd",
+			want:  "X aY",
+			hits:  []int{1, 1},
+		},
+		{
+			name:  "经过多级回退",
+			rules: []Rule{{From: "abc", To: "X", Limit: 1}, {From: "bc", To: "Y", Limit: 1}, {From: "c", To: "Z"}},
+			src:   "abc abc abc",
+			want:  "X aY abZ",
+			hits:  []int{1, 1, 1},
+		},
+		{
+			name:  "没有可回退的规则",
+			rules: []Rule{{From: "ab", To: "X", Limit: 2}},
+			src:   "ab ab ab",
+			want:  "X X ab",
+			hits:  []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, counts := NewReplacer(tt.rules).Replace([]byte(tt.src))
+			if string(out) != tt.want {
+				t.Errorf("Replace(%q) = %q, want %q", tt.src, out, tt.want)
+			}
+			for i, want := range tt.hits {
+				if counts[i] != want {
+					t.Errorf("规则 %q 命中 %d 次, want %d", tt.rules[i].From, counts[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"antigravity_translator/literals"
+)
+
+// ========================================
+// 转义无关匹配 (MatchEscapes)
+// ========================================
+//
+// 设置了 MatchEscapes 的规则按解码后的文本书写 (如 "• Disabled"、`resolve "fetch"`)，
+// 匹配时同一个字符在打包文件中的各种写法都能匹配:
+//   - 非 ASCII 字符: 原字符、\uXXXX、\u{X} (前导零最多 6 个)、\xXX (≤ 0xFF)、代理对 😀
+//   - 引号 " ' `: 原字符或 \" \' \`
+//   - 换行、回车、制表符: 原字符或 \n \r \t
+//   - 反斜杠: \\
+//
+// 可打印的 ASCII 字符压缩工具不会转义，只按原字符匹配。
+// 输出译文时按匹配到的字面量重新编码: 原文中以转义形式出现的字符沿用相同的写法，
+// 与字面量引号相同的引号和反斜杠加上转义，非模板字面量中的换行写成 \n。
+// 原文只是字面量中间的一段时，用 literals.Extract 找出包含匹配的字面量来确定引号；
+// 匹配不在字面量内或跨越字面量边界时引号无法确定，不替换 (计入 Stats.Skipped)。
+
+// isQuote 判断是否为 JS 字符串的引号
+func isQuote(c byte) bool {
+	return c == '"' || c == '\'' || c == '`'
+}
+
+// hexPattern 匹配大小写任意的十六进制数字串
+func hexPattern(v rune, width int) string {
+	var b strings.Builder
+	for _, c := range fmt.Sprintf("%0*x", width, v) {
+		if c >= 'a' && c <= 'f' {
+			b.WriteString("[" + string(c) + strings.ToUpper(string(c)) + "]")
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// escapedRunePattern 返回匹配字符 r 各种 JS 写法的正则表达式
+func escapedRunePattern(r rune) string {
+	switch {
+	case r == '\\':
+		return `\\\\`
+	case r < utf8.RuneSelf && isQuote(byte(r)):
+		return `(?:` + regexp.QuoteMeta(string(r)) + `|\\` + regexp.QuoteMeta(string(r)) + `)`
+	case r == '\n':
+		return `(?:\n|\\n)`
+	case r == '\r':
+		return `(?:\r|\\r)`
+	case r == '\t':
+		return `(?:\t|\\t)`
+	case r < utf8.RuneSelf:
+		return regexp.QuoteMeta(string(r))
+	}
+	alts := []string{regexp.QuoteMeta(string(r)), `\\u\{0{0,6}` + hexPattern(r, 1) + `\}`}
+	if r <= 0xFFFF {
+		alts = append(alts, `\\u`+hexPattern(r, 4))
+	} else {
+		hi, lo := 0xD800+((r-0x10000)>>10), 0xDC00+((r-0x10000)&0x3FF)
+		alts = append(alts, `\\u`+hexPattern(hi, 4)+`\\u`+hexPattern(lo, 4))
+	}
+	if r <= 0xFF {
+		alts = append(alts, `\\x`+hexPattern(r, 2))
+	}
+	return `(?:` + strings.Join(alts, "|") + `)`
+}
+
+// maxEscapedLen 返回字符 r 各种 JS 写法的最大字节数 (见 escapedRunePattern)
+func maxEscapedLen(r rune) int {
+	switch {
+	case r == '\\' || r == '\n' || r == '\r' || r == '\t' || r < utf8.RuneSelf && isQuote(byte(r)):
+		return 2
+	case r < utf8.RuneSelf:
+		return 1
+	}
+	return len(`\u{000000`) + len(fmt.Sprintf("%x", r)) + len(`}`) // 不短于原字符、\uXXXX 和代理对
+}
+
+// escapedPattern 返回匹配 text 各种 JS 写法的正则表达式
+func escapedPattern(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		b.WriteString(escapedRunePattern(r))
+	}
+	return b.String()
+}
+
+// wholeLiteralQuote 原文首尾是同一种引号时 (整个字符串字面量) 返回该引号，否则返回 0
+func wholeLiteralQuote(s string) byte {
+	if len(s) >= 2 && isQuote(s[0]) && s[len(s)-1] == s[0] {
+		return s[0]
+	}
+	return 0
+}
+
+// enclosingQuote 返回完全包含 [start, end) 的字符串字面量的引号 (lits 为 literals.Extract 的结果)；
+// 匹配不在字面量内或跨越了字面量边界时引号无法确定，返回 false
+func enclosingQuote(lits []literals.Literal, start, end int) (byte, bool) {
+	i := sort.Search(len(lits), func(i int) bool { return lits[i].End > start })
+	if i == len(lits) || lits[i].Start >= start || end > lits[i].End-1 {
+		return 0, false
+	}
+	return lits[i].Quote, true
+}
+
+// literalEncoder 按匹配到的字面量的引号和转义方式编码译文
+type literalEncoder struct {
+	quote byte            // 字面量的引号，0 表示无法确定
+	forms map[rune]string // 匹配文本中以转义形式出现的字符 -> 转义写法
+}
+
+// newLiteralEncoder 解析匹配到的文本，记录其中各字符的转义写法
+func newLiteralEncoder(quote byte, matched []byte) *literalEncoder {
+	e := &literalEncoder{quote: quote, forms: make(map[rune]string)}
+	for i := 0; i < len(matched); {
+		if matched[i] != '\\' || i+1 >= len(matched) {
+			_, size := utf8.DecodeRune(matched[i:])
+			i += size
+			continue
+		}
+		r, n := decodeEscape(matched[i:])
+		if n == 0 {
+			i += 2
+			continue
+		}
+		if _, seen := e.forms[r]; !seen && (r >= utf8.RuneSelf || r == '\n' || r == '\r' || r == '\t') {
+			e.forms[r] = string(matched[i : i+n])
+		}
+		i += n
+	}
+	return e
+}
+
+// decodeEscape 解码 s 开头的 \uXXXX、\u{X}、\xXX、\n 等转义，返回字符和长度；无法识别时长度为 0
+func decodeEscape(s []byte) (rune, int) {
+	if len(s) < 2 || s[0] != '\\' {
+		return 0, 0
+	}
+	switch s[1] {
+	case 'n':
+		return '\n', 2
+	case 'r':
+		return '\r', 2
+	case 't':
+		return '\t', 2
+	case 'x':
+		if len(s) >= 4 {
+			if v, err := strconv.ParseUint(string(s[2:4]), 16, 8); err == nil {
+				return rune(v), 4
+			}
+		}
+	case 'u':
+		if len(s) >= 3 && s[2] == '{' {
+			end := strings.IndexByte(string(s[3:min(len(s), 12)]), '}')
+			if end > 0 {
+				if v, err := strconv.ParseUint(string(s[3:3+end]), 16, 32); err == nil {
+					return rune(v), 4 + end
+				}
+			}
+			return 0, 0
+		}
+		if len(s) >= 6 {
+			v, err := strconv.ParseUint(string(s[2:6]), 16, 16)
+			if err != nil {
+				return 0, 0
+			}
+			r := rune(v)
+			if r >= 0xD800 && r < 0xDC00 && len(s) >= 12 && s[6] == '\\' && s[7] == 'u' {
+				if lo, err := strconv.ParseUint(string(s[8:12]), 16, 16); err == nil && lo >= 0xDC00 && lo < 0xE000 {
+					return 0x10000 + (r-0xD800)<<10 + (rune(lo) - 0xDC00), 12
+				}
+			}
+			return r, 6
+		}
+	}
+	return 0, 0
+}
+
+// encode 把解码后的文本编码后追加到 dst
+func (e *literalEncoder) encode(dst []byte, text string) []byte {
+	for _, r := range text {
+		switch {
+		case r == '\\':
+			dst = append(dst, `\\`...)
+		case r < utf8.RuneSelf && byte(r) == e.quote:
+			dst = append(dst, '\\', byte(r))
+		case e.forms[r] != "":
+			dst = append(dst, e.forms[r]...)
+		case r == '\n' && e.quote != '`' && e.quote != 0:
+			dst = append(dst, `\n`...)
+		case r == '\r' && e.quote != '`' && e.quote != 0:
+			dst = append(dst, `\r`...)
+		default:
+			dst = utf8.AppendRune(dst, r)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,57 @@
+package engine
+
+import "fmt"
+
+// ========================================
+// 预期命中次数
+// ========================================
+//
+// 上游重构后规则可能不再命中 (设置页仍是英文)，也可能命中了不该命中的地方 (界面乱码)。
+// 规则可以声明在每个文件中预期的命中次数，实际次数不符时记录到 Stats.Violations，
+// 由调用方作为警告 (或严格模式下的错误) 报告，在用户发现之前察觉新版本的变化。
+
+// Expect 规则在单个文件中预期的命中次数范围
+type Expect struct {
+	Min int
+	Max int // 小于 0 表示不限
+}
+
+// Exactly 恰好命中 n 次
+func Exactly(n int) *Expect { return &Expect{Min: n, Max: n} }
+
+// AtLeast 至少命中 n 次
+func AtLeast(n int) *Expect { return &Expect{Min: n, Max: -1} }
+
+// AtMost 至多命中 n 次
+func AtMost(n int) *Expect { return &Expect{Min: 0, Max: n} }
+
+// Allows 判断命中次数是否符合预期
+func (e *Expect) Allows(count int) bool {
+	return count >= e.Min && (e.Max < 0 || count <= e.Max)
+}
+
+// Valid 判断预期本身是否有效 (范围非空)
+func (e *Expect) Valid() bool {
+	return e.Min >= 0 && (e.Max < 0 || e.Max >= e.Min)
+}
+
+// String 返回预期的可读描述，如 "恰好 1 次"
+func (e *Expect) String() string {
+	switch {
+	case e.Max == e.Min:
+		return fmt.Sprintf("恰好 %d 次", e.Min)
+	case e.Max < 0:
+		return fmt.Sprintf("至少 %d 次", e.Min)
+	case e.Min == 0:
+		return fmt.Sprintf("至多 %d 次", e.Max)
+	}
+	return fmt.Sprintf("%d 到 %d 次", e.Min, e.Max)
+}
+
+// WithExpect 为一组规则设置预期命中次数
+func WithExpect(expect *Expect, rules []Rule) []Rule {
+	for i := range rules {
+		rules[i].Expect = expect
+	}
+	return rules
+}
@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ========================================
+// 反向规则
+// ========================================
+//
+// 备份丢失时，把规则的原文和译文对调后应用到已汉化的文件上，可以恢复出 (接近) 原版的内容:
+//   - 占位符 ${$N}、${*} 在两边对调，要求原文中的每个占位符都出现在译文中 (否则表达式已经丢失)
+//   - 空白不敏感规则 (MatchWhitespace): 译文中的第 k 个 ${~} 换成换行，反向规则的译文中原文第 k 段含换行的空白
+//     换成 ${~}，从而保留汉化后文件中的换行和缩进；不含换行的空白按原文输出
+//   - 锚定规则和作用范围保持不变，Limit 和 Expect 不再适用
+//   - 正则规则的译文是 regexp.Expand 模板，无法反向
+//
+// 同一阶段中译文相同的规则 (如 "Active" 和 "Activity" 都译为 "活动") 反向后无法区分，由调用方检查。
+
+// Inverse 返回原文和译文对调的反向规则，规则无法反向时返回错误
+func (r Rule) Inverse() (Rule, error) {
+	if r.Mode&MatchRegexp != 0 {
+		return Rule{}, fmt.Errorf("正则规则无法反向")
+	}
+	if r.To == "" {
+		return Rule{}, fmt.Errorf("译文为空，原文已经丢失")
+	}
+	from, to := placeholderRefs(r.From), placeholderRefs(r.To)
+	for name := range from {
+		if name == "*" {
+			continue
+		}
+		if !to[name] {
+			return Rule{}, fmt.Errorf("译文没有使用占位符 ${%s}，对应的表达式已经丢失", name)
+		}
+	}
+	if strings.Count(r.From, "${*}") != strings.Count(r.To, "${*}") {
+		return Rule{}, fmt.Errorf("原文和译文中的 ${*} 个数不同")
+	}
+
+	inv := Rule{Kind: r.Kind, From: r.To, To: r.From, Mode: r.Mode, Anchor: r.Anchor, Scope: r.Scope}
+	if r.Mode&MatchWhitespace != 0 {
+		to, breaks := whitespaceBreaks(r.From)
+		if breaks != strings.Count(r.To, whitespaceMarker) {
+			return Rule{}, fmt.Errorf("译文中的 %s 个数与原文中含换行的空白段数不同", whitespaceMarker)
+		}
+		inv.From = strings.ReplaceAll(r.To, whitespaceMarker, "\n")
+		inv.To = to
+	}
+	if inv.IsPattern() {
+		if _, err := compilePattern(inv); err != nil {
+			return Rule{}, err
+		}
+	}
+	return inv, nil
+}
+
+// placeholderRefs 返回文本中出现的占位符名称 ("$1"、"*")
+func placeholderRefs(s string) map[string]bool {
+	refs := make(map[string]bool)
+	for i := 0; i < len(s); i++ {
+		if name, n := placeholderAt(s, i); n > 0 {
+			refs[name] = true
+			i += n - 1
+		}
+	}
+	return refs
+}
+
+// whitespaceBreaks 把文本中每段含换行的空白换成 ${~}，返回结果和替换的段数
+func whitespaceBreaks(s string) (string, int) {
+	var b strings.Builder
+	breaks := 0
+	for len(s) > 0 {
+		n := strings.IndexFunc(s, isSpace)
+		if n < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:n])
+		s = s[n:]
+		end := strings.IndexFunc(s, func(r rune) bool { return !isSpace(r) })
+		if end < 0 {
+			end = len(s)
+		}
+		if strings.ContainsAny(s[:end], "\r\n") {
+			b.WriteString(whitespaceMarker)
+			breaks++
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+	return b.String(), breaks
+}
@@ -0,0 +1,53 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+)
+
+// ========================================
+// 基准测试辅助
+// ========================================
+
+// ApplyLegacy 旧的逐条替换实现: 每条规则各扫描一遍全文 (字面量规则 strings.Count + strings.Replace)
+// 仅用于基准测试对比 (bench 命令和 go test -bench)，返回结果和替换总次数
+func ApplyLegacy(target, content string, sets ...*RuleSet) (string, int) {
+	hits := 0
+	for _, set := range sets {
+		for _, p := range set.Phases {
+			for _, rule := range p.Rules() {
+				if !rule.Scope.AppliesTo(target) {
+					continue
+				}
+				var count int
+				content, count = ApplyRule(rule, content)
+				hits += count
+			}
+		}
+	}
+	return content, hits
+}
+
+// SyntheticBundle 用规则原文和填充代码拼出约 size 字节的合成 JS，供没有安装时测试
+func SyntheticBundle(size int, sets ...*RuleSet) string {
+	var froms []string
+	for _, set := range sets {
+		for _, p := range set.Phases {
+			for _, r := range p.Rules() {
+				froms = append(froms, r.From)
+			}
+		}
+	}
+	sort.Strings(froms)
+
+	const filler = `;var e=function(t,n){return t&&n?t.concat(n):[]},a={label:"x",value:0};`
+	var b strings.Builder
+	b.Grow(size + 1024)
+	for i := 0; b.Len() < size; i++ {
+		b.WriteString(filler)
+		if i%4 == 0 && len(froms) > 0 {
+			b.WriteString(froms[(i/4)%len(froms)])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,345 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"antigravity_translator/literals"
+)
+
+// ========================================
+// 模式规则 (模板字面量占位符、空白不敏感)
+// ========================================
+//
+// 原文中可以用占位符匹配被压缩工具重命名的表达式:
+//   - ${$1}、${$1.name}: $N 匹配 ${...} 中的任意 JS 表达式 (其后的 .name 等必须原样出现)，
+//     译文中的 ${$N...} 用同一编号的表达式替换
+//   - ${*}: 匹配 ${...} 中的任意表达式，译文中的第 k 个 ${*} 输出原文中第 k 个 ${*} 匹配到的表达式
+//
+// 设置了 MatchEscapes 的规则见 escape.go，正则规则 (MatchRegexp) 见 regex.go，锚定规则 (Anchor) 见 anchor.go。
+//
+// 设置了 MatchWhitespace 的规则中，原文的每段空白匹配任意一段空白；含换行的空白依次编号，
+// 译文中的第 k 个 ${~} 输出第 k 段换行空白匹配到的原始文本 (保留原有的换行和缩进)。
+//
+// 模式规则不参与 Aho-Corasick 扫描，而是编译成正则表达式，在同一阶段的字面量规则之后依次执行。
+
+// exprPattern ${...} 中的 JS 表达式: 不含反引号，花括号最多嵌套一层 (如 ${f({a:1})})
+const exprPattern = `((?:[^{}\x60\\]|\\.|\{(?:[^{}\x60\\]|\\.)*\})+?)`
+
+// pattern 由一条模式规则编译出的正则表达式
+type pattern struct {
+	re      *regexp.Regexp
+	groups  map[string]int // 占位符 ("$1"、"*1"、"~1" ...)、锚点 ("anchor") 和引号 ("quote") -> 子匹配编号
+	to      []segment      // 译文模板
+	escapes bool           // 按匹配到的字面量重新编码译文 (MatchEscapes)
+	literal bool           // 整个字符串字面量规则 (MatchEscapes 或锚定规则，且原文首尾是同一种引号): 匹配任意一种引号的字面量
+	regex   bool           // 正则规则: 译文是 regexp.Expand 模板
+	expand  []byte
+	limit   int // 最多替换次数，0 表示不限
+
+	prefilter prefilter // 字面量预筛选 (见 prefilter.go)
+}
+
+// segment 译文模板的一段: 字面量文本或占位符引用
+type segment struct {
+	text  string
+	ref   string // 非空时输出该占位符匹配到的文本
+	raw   bool   // 不重新编码 ("${")
+	quote bool   // 输出匹配到的字面量的引号
+}
+
+// whitespaceMarker 译文中输出原始换行空白的标记
+const whitespaceMarker = "${~}"
+
+// placeholderAt 在 s[i:] 处解析占位符 "${$N" 或 "${*}"，返回占位符名称和长度
+// "${$N" 之后的 ".name}" 等后缀不属于占位符本身
+func placeholderAt(s string, i int) (name string, n int) {
+	if !strings.HasPrefix(s[i:], "${") {
+		return "", 0
+	}
+	if strings.HasPrefix(s[i+2:], "*}") {
+		return "*", 3 // 只消费 "${*"，"}" 作为字面量保留
+	}
+	j := i + 2
+	if j >= len(s) || s[j] != '$' {
+		return "", 0
+	}
+	k := j + 1
+	for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+		k++
+	}
+	if k == j+1 {
+		return "", 0
+	}
+	return s[j:k], k - i
+}
+
+// hasPlaceholders 判断原文是否包含占位符
+func hasPlaceholders(s string) bool {
+	for i := strings.Index(s, "${"); i >= 0; {
+		if _, n := placeholderAt(s, i); n > 0 {
+			return true
+		}
+		next := strings.Index(s[i+2:], "${")
+		if next < 0 {
+			break
+		}
+		i += 2 + next
+	}
+	return false
+}
+
+// compilePattern 把模式规则编译成正则表达式
+func compilePattern(rule Rule) (*pattern, error) {
+	if rule.Mode&MatchRegexp != 0 {
+		return compileRegexp(rule)
+	}
+	p := &pattern{groups: make(map[string]int), escapes: rule.Mode&MatchEscapes != 0, limit: rule.Limit}
+
+	var expr strings.Builder
+	group, stars, breaks := 0, 0, 0
+
+	// quote 不含空白的原文片段
+	quote := regexp.QuoteMeta
+	space := `\s+`
+	if p.escapes {
+		quote = escapedPattern
+		space = `(?:\s|\\[nrt])+`
+	}
+
+	// literal 写入一段字面量原文，空白不敏感时把每段空白换成 \s+ (含换行的空白作为子匹配)
+	literal := func(text string) {
+		if rule.Mode&MatchWhitespace == 0 {
+			expr.WriteString(quote(text))
+			return
+		}
+		for len(text) > 0 {
+			n := strings.IndexFunc(text, isSpace)
+			if n < 0 {
+				expr.WriteString(quote(text))
+				return
+			}
+			expr.WriteString(quote(text[:n]))
+			text = text[n:]
+			end := strings.IndexFunc(text, func(r rune) bool { return !isSpace(r) })
+			if end < 0 {
+				end = len(text)
+			}
+			if strings.ContainsAny(text[:end], "\r\n") {
+				group++
+				breaks++
+				p.groups["~"+strconv.Itoa(breaks)] = group
+				expr.WriteString("(" + space + ")")
+			} else {
+				expr.WriteString(space)
+			}
+			text = text[end:]
+		}
+	}
+
+	// 转义无关的整个字符串字面量规则和锚定规则: 匹配 "..."、'...'、`...` 任意一种，译文使用匹配到的引号
+	from, to := rule.From, rule.To
+	if p.escapes || rule.Anchor != nil {
+		if q := wholeLiteralQuote(from); q != 0 && wholeLiteralQuote(to) == q {
+			p.literal = true
+			from, to = from[1:len(from)-1], to[1:len(to)-1]
+		}
+	}
+	if rule.Anchor != nil {
+		if !p.literal {
+			return nil, fmt.Errorf("锚定规则的原文和译文必须是用同一种引号括起的完整字符串字面量")
+		}
+		anchor, err := anchorPattern(rule.Anchor)
+		if err != nil {
+			return nil, err
+		}
+		group++
+		p.groups["anchor"] = group
+		expr.WriteString("(" + anchor + ")")
+	}
+	if p.literal {
+		group++
+		p.groups["quote"] = group
+		expr.WriteString("([\"'`])")
+	}
+
+	last := 0
+	for i := 0; i < len(from); i++ {
+		name, n := placeholderAt(from, i)
+		if n == 0 {
+			continue
+		}
+		literal(from[last:i])
+		expr.WriteString(`\$\{`)
+		expr.WriteString(exprPattern)
+		group++
+		if name == "*" {
+			stars++
+			name = "*" + strconv.Itoa(stars)
+		}
+		if _, dup := p.groups[name]; !dup {
+			p.groups[name] = group
+		}
+		i += n - 1
+		last = i + 1
+	}
+	literal(from[last:])
+	if p.literal {
+		expr.WriteString("[\"'`]")
+	}
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, err
+	}
+	p.re = re
+	p.prefilter = newPrefilter(rule, from, p.literal)
+	p.to = parseTemplate(to, rule.Mode&MatchWhitespace != 0)
+	if p.literal {
+		q := segment{quote: true}
+		p.to = append(append([]segment{q}, p.to...), q)
+	}
+	if rule.Anchor != nil {
+		p.to = append([]segment{{ref: "anchor"}}, p.to...)
+	}
+	for _, seg := range p.to {
+		if _, ok := p.groups[seg.ref]; seg.ref != "" && !ok {
+			return nil, fmt.Errorf("译文引用了原文中不存在的占位符 %s", placeholderName(seg.ref))
+		}
+	}
+	return p, nil
+}
+
+// parseTemplate 把译文拆成字面量和占位符引用: ${$N 输出 "${" 加第 N 个表达式，第 k 个 ${* 输出 "${" 加第 k 个 ${*} 表达式，
+// whitespace 为 true 时第 k 个 ${~} 输出第 k 段换行空白
+func parseTemplate(to string, whitespace bool) []segment {
+	var segs []segment
+	stars, breaks := 0, 0
+	last := 0
+	for i := 0; i < len(to); i++ {
+		if whitespace && strings.HasPrefix(to[i:], whitespaceMarker) {
+			breaks++
+			segs = append(segs, segment{text: to[last:i]}, segment{ref: "~" + strconv.Itoa(breaks)})
+			i += len(whitespaceMarker) - 1
+			last = i + 1
+			continue
+		}
+		name, n := placeholderAt(to, i)
+		if n == 0 {
+			continue
+		}
+		if name == "*" {
+			stars++
+			name = "*" + strconv.Itoa(stars)
+		}
+		segs = append(segs, segment{text: to[last:i]}, segment{text: "${", raw: true}, segment{ref: name})
+		i += n - 1
+		last = i + 1
+	}
+	return append(segs, segment{text: to[last:]})
+}
+
+// replace 替换 src 中的所有匹配 (最多 limit 处)，返回结果、替换次数和跳过的匹配数
+// (整个字面量规则匹配到两端引号不同的文本、转义无关的片段规则不在字符串字面量内时不替换，计入跳过数)；sample 不为空时对每处替换调用一次，参数为匹配到的原文和生成的译文；edit 不为空时报告每处替换的位置 (锚点不计在内，下标为 0)
+func (p *pattern) replace(src []byte, sample func(from, to []byte), edit editFunc) ([]byte, int, int) {
+	matches := p.find(src)
+	if len(matches) == 0 {
+		return src, 0, 0
+	}
+	out := make([]byte, 0, len(src)+len(src)/8)
+	last, count, skipped := 0, 0, 0
+	var lits []literals.Literal // 片段规则重新编码时使用，第一次需要时提取
+	extracted := false
+	for _, m := range matches {
+		// 整个字面量规则两端的引号必须相同，字面量中间的片段必须能确定所在字面量的引号
+		var quote byte
+		switch {
+		case p.literal:
+			quote = src[m[2*p.groups["quote"]]]
+			if quote != src[m[1]-1] {
+				skipped++
+				continue
+			}
+		case p.escapes:
+			if !extracted {
+				lits, extracted = literals.Extract(src), true
+			}
+			q, ok := enclosingQuote(lits, m[0], m[1])
+			if !ok {
+				skipped++
+				continue
+			}
+			quote = q
+		}
+		if p.limit > 0 && count >= p.limit {
+			break
+		}
+		count++
+		out = append(out, src[last:m[0]]...)
+		start := len(out)
+		last = m[1]
+		if p.regex {
+			out = p.re.Expand(out, p.expand, src, m)
+			if sample != nil {
+				sample(src[m[0]:m[1]], out[start:])
+			}
+			if edit != nil {
+				edit(0, m[0], m[1], start, len(out))
+			}
+			continue
+		}
+		var enc *literalEncoder
+		if p.escapes {
+			enc = newLiteralEncoder(quote, src[m[0]:m[1]])
+		}
+		for _, seg := range p.to {
+			if seg.quote {
+				out = append(out, quote)
+				continue
+			}
+			if seg.ref == "" {
+				if enc != nil && !seg.raw {
+					out = enc.encode(out, seg.text)
+				} else {
+					out = append(out, seg.text...)
+				}
+				continue
+			}
+			if g, ok := p.groups[seg.ref]; ok && m[2*g] >= 0 {
+				out = append(out, src[m[2*g]:m[2*g+1]]...)
+			}
+		}
+		if sample != nil {
+			sample(src[m[0]:m[1]], out[start:])
+		}
+		if edit != nil {
+			skip := 0 // 锚点原样输出，不算作替换的一部分
+			if g, ok := p.groups["anchor"]; ok {
+				skip = m[2*g+1] - m[0]
+			}
+			edit(0, m[0]+skip, m[1], start+skip, len(out))
+		}
+	}
+	if count == 0 {
+		return src, 0, skipped
+	}
+	return append(out, src[last:]...), count, skipped
+}
+
+// placeholderName 返回占位符引用在规则中的写法
+func placeholderName(ref string) string {
+	switch ref[0] {
+	case '*':
+		return "${*} (第 " + ref[1:] + " 个)"
+	case '~':
+		return whitespaceMarker + " (第 " + ref[1:] + " 个)"
+	}
+	return "${" + ref + "}"
+}
+
+// isSpace 空白不敏感匹配时视为空白的字符
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
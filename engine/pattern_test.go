@@ -0,0 +1,62 @@
+package engine
+
+import "testing"
+
+// TestPatternQuoteMismatch 整个字面量规则匹配到两端引号不同的文本时不替换，计入 Stats.Skipped 和 Violation
+func TestPatternQuoteMismatch(t *testing.T) {
+	const src = `a("Run");b('Run");c('Run')`
+	phase := func(expect *Expect) *RuleSet {
+		rule := Rule{Kind: "normal", From: `"Run"`, To: `"运行"`, Mode: MatchEscapes, Expect: expect}
+		return &RuleSet{Name: "test", Phases: []*Phase{NewPhase("normal", CategoryNormal, func() []Rule { return []Rule{rule} })}}
+	}
+
+	out, stats, err := ApplyTarget(t.Context(), "", src, phase(Exactly(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `a("运行");b('Run");c('运行')`; out != want {
+		t.Errorf("out = %q, want %q", out, want)
+	}
+	if len(stats.Skipped) != 1 || stats.Skipped[0].Count != 1 {
+		t.Errorf("Skipped = %+v, want 1 处", stats.Skipped)
+	}
+	if len(stats.Violations) != 0 {
+		t.Errorf("Violations = %+v, want none", stats.Violations)
+	}
+
+	_, stats, _ = ApplyTarget(t.Context(), "", src, phase(Exactly(3)))
+	if len(stats.Violations) != 1 || stats.Violations[0].Count != 2 || stats.Violations[0].Skipped != 1 {
+		t.Errorf("Violations = %+v, want 命中 2 次、跳过 1 处", stats.Violations)
+	}
+}
+
+// TestPatternEnclosingQuote 字面量中间的片段按实际所在的字面量重新编码，不受字面量内其他引号字符的影响
+func TestPatternEnclosingQuote(t *testing.T) {
+	rule := Rule{Kind: "normal", From: "• Disabled - x", To: `• 已禁用 - "y"`, Mode: MatchEscapes}
+	set := &RuleSet{Name: "test", Phases: []*Phase{NewPhase("normal", CategoryNormal, func() []Rule { return []Rule{rule} })}}
+	tests := []struct {
+		src, want string
+		skipped   int
+	}{
+		{`a="Agent's • Disabled - x"`, `a="Agent's • 已禁用 - \"y\""`, 0},
+		{`a='Agent\'s • Disabled - x'`, `a='Agent\'s • 已禁用 - "y"'`, 0},
+		{"a=`it's \"• Disabled - x`", "a=`it's \"• 已禁用 - \"y\"`", 0},
+		{`a="b";// • Disabled - x`, `a="b";// • Disabled - x`, 1},
+	}
+	for _, tt := range tests {
+		out, stats, err := ApplyTarget(t.Context(), "", tt.src, set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != tt.want {
+			t.Errorf("Apply(%q) = %q, want %q", tt.src, out, tt.want)
+		}
+		skipped := 0
+		for _, s := range stats.Skipped {
+			skipped += s.Count
+		}
+		if skipped != tt.skipped {
+			t.Errorf("Apply(%q) 跳过 %d 处, want %d", tt.src, skipped, tt.skipped)
+		}
+	}
+}
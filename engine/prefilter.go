@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// ========================================
+// 模式规则的字面量预筛选
+// ========================================
+//
+// 锚定规则的正则表达式以 (?:^|[^\w$.-])(?:label|title|...) 开头，RE2 找不到字面量前缀，
+// 每条规则都要逐字节扫描整个 bundle。编译时从原文中取出每处匹配都必然原样包含的一段文字 (needle)，
+// 应用时先用 bytes.Index 查找 needle，只在每处 needle 附近的窗口内运行正则表达式:
+//   - 原文中 needle 前后的部分长度有上限时，窗口就是 needle 向前、向后各扩展上限字节
+//   - 锚定规则的锚点中可以有任意多的空白，窗口从开头引号继续向前扩展到锚点之前的第一个
+//     不可能属于锚点的字符 (含该字符，使 [^\w$.-] 的判断与全文扫描相同)
+//   - 转义无关匹配 (MatchEscapes) 时只有可打印的 ASCII 字符 (引号和反斜杠除外) 原样出现，其他字符按最长的转义写法计算长度
+//   - 占位符、空白不敏感等长度不限的部分使 needle 只能用来判断是否需要扫描: 全文不含 needle 时跳过，否则扫描全文
+//
+// 相邻或重叠的窗口合并后扫描，结果与扫描全文完全相同。
+
+// minNeedle 预筛选使用的最短文字，更短时直接扫描全文 (needle 是整个字面量的内容时不限长度)
+const minNeedle = 3
+
+// maxAnchorSpan 锚定规则向前查找锚点开头的最大字节数，超出时 (通常不是真正的锚点) 改为扫描全文
+const maxAnchorSpan = 256
+
+// prefilter 模式规则的预筛选条件
+type prefilter struct {
+	needle   []byte // 每处匹配都原样包含的文字，为空时不筛选
+	before   int    // 匹配开头到 needle 的最大字节数 (锚定规则不含锚点)，-1 表示不限
+	after    int    // needle 结尾到匹配结尾的最大字节数，-1 表示不限
+	anchored bool   // 窗口继续向前扩展到锚点之前
+	quoted   bool   // needle 是整个字面量的内容，两侧必须紧挨着引号
+}
+
+// prefilterToken 原文中的一个字符或一段长度不限的部分
+type prefilterToken struct {
+	text  string // 字符本身 (长度不限的部分为空)
+	max   int    // 匹配到的文本的最大字节数，-1 表示不限
+	plain bool   // 在 bundle 中只能原样出现
+}
+
+// newPrefilter 计算模式规则的预筛选条件，body 为原文 (整个字面量规则去掉首尾引号)
+func newPrefilter(rule Rule, body string, literal bool) prefilter {
+	escapes := rule.Mode&MatchEscapes != 0
+	var tokens []prefilterToken
+	for i := 0; i < len(body); {
+		if _, n := placeholderAt(body, i); n > 0 {
+			tokens = append(tokens, prefilterToken{max: -1})
+			i += n
+			continue
+		}
+		if rule.Mode&MatchWhitespace != 0 && isSpace(rune(body[i])) {
+			for i < len(body) && isSpace(rune(body[i])) {
+				i++
+			}
+			tokens = append(tokens, prefilterToken{max: -1})
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(body[i:])
+		t := prefilterToken{text: body[i : i+size], max: size, plain: true}
+		if escapes {
+			t.max, t.plain = maxEscapedLen(r), r >= ' ' && r <= '~' && r != '\\' && !isQuote(byte(r))
+		}
+		tokens = append(tokens, t)
+		i += size
+	}
+	return prefilterFromTokens(tokens, literal, rule.Anchor != nil)
+}
+
+// prefilterFromTokens 选出最长的一段原样出现的文字作为 needle，并计算它前后的最大长度
+func prefilterFromTokens(tokens []prefilterToken, literal, anchored bool) prefilter {
+	best, bestLen := -1, 0
+	for i := 0; i < len(tokens); {
+		if !tokens[i].plain {
+			i++
+			continue
+		}
+		j, n := i, 0
+		for ; j < len(tokens) && tokens[j].plain; j++ {
+			n += len(tokens[j].text)
+		}
+		if n > bestLen {
+			best, bestLen = i, n
+		}
+		i = j
+	}
+	f := prefilter{anchored: anchored}
+	end := best
+	for ; end < len(tokens) && tokens[end].plain; end++ {
+		f.needle = append(f.needle, tokens[end].text...)
+	}
+	f.quoted = literal && best == 0 && end == len(tokens)
+	if bestLen == 0 || bestLen < minNeedle && !f.quoted {
+		return prefilter{}
+	}
+	f.before = sumMax(tokens[:best])
+	f.after = sumMax(tokens[end:])
+	if literal {
+		f.before, f.after = addBound(f.before, 1), addBound(f.after, 1) // 两端的引号
+	}
+	return f
+}
+
+// sumMax 返回一串 token 匹配到的文本的最大总长度，-1 表示不限
+func sumMax(tokens []prefilterToken) int {
+	total := 0
+	for _, t := range tokens {
+		if t.max < 0 {
+			return -1
+		}
+		total += t.max
+	}
+	return total
+}
+
+// addBound 给长度上限加上 n，不限时仍为不限
+func addBound(bound, n int) int {
+	if bound < 0 {
+		return bound
+	}
+	return bound + n
+}
+
+// isAnchorByte 判断字符是否可能属于锚点 (属性名、函数名、引号、空白和 : = { ()
+func isAnchorByte(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	switch c {
+	case '_', '$', '.', '-', '"', '\'', ':', '=', '{', '(', ' ', '\t', '\n', '\r', '\f':
+		return true
+	}
+	return false
+}
+
+// candidate 判断 src[h:] 处的 needle 是否可能属于一处匹配 (整个字面量的内容两侧必须是引号)
+func (f *prefilter) candidate(src []byte, h int) bool {
+	if !f.quoted {
+		return true
+	}
+	end := h + len(f.needle)
+	return h > 0 && end < len(src) && isQuote(src[h-1]) && isQuote(src[end])
+}
+
+// windowStart 返回包含 src[h:] 处 needle 的匹配可能的最小起点；锚点过长时返回 false
+func (f *prefilter) windowStart(src []byte, h int) (int, bool) {
+	lo := max(0, h-f.before)
+	if !f.anchored {
+		return lo, true
+	}
+	for lo > 0 && isAnchorByte(src[lo-1]) {
+		lo--
+		if h-lo > maxAnchorSpan {
+			return 0, false
+		}
+	}
+	return max(0, lo-1), true
+}
+
+// find 返回 src 中的所有匹配 (同 FindAllSubmatchIndex)，有预筛选条件时只扫描 needle 附近的窗口
+func (p *pattern) find(src []byte) [][]int {
+	f := &p.prefilter
+	if len(f.needle) == 0 {
+		return p.re.FindAllSubmatchIndex(src, -1)
+	}
+	h := bytes.Index(src, f.needle)
+	if h < 0 {
+		return nil
+	}
+	if f.before < 0 || f.after < 0 {
+		return p.re.FindAllSubmatchIndex(src, -1)
+	}
+
+	var matches [][]int
+	last, lo, hi := 0, 0, -1 // last: 上一处匹配的结尾；[lo, hi): 当前合并的窗口
+	scan := func() {
+		start := max(lo, last)
+		if start >= hi {
+			return
+		}
+		for _, m := range p.re.FindAllSubmatchIndex(src[start:hi], -1) {
+			for i := range m {
+				if m[i] >= 0 {
+					m[i] += start
+				}
+			}
+			matches = append(matches, m)
+			last = m[1]
+		}
+	}
+	for {
+		if f.candidate(src, h) {
+			wlo, ok := f.windowStart(src, h)
+			if !ok {
+				return p.re.FindAllSubmatchIndex(src, -1)
+			}
+			if wlo > hi {
+				scan()
+				lo = wlo
+			}
+			hi = max(hi, min(len(src), h+len(f.needle)+f.after))
+		}
+		next := bytes.Index(src[h+1:], f.needle)
+		if next < 0 {
+			break
+		}
+		h += 1 + next
+	}
+	scan()
+	return matches
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPrefilterFind 预筛选后只扫描窗口的结果与扫描全文完全相同
+func TestPrefilterFind(t *testing.T) {
+	rules := []Rule{
+		{From: `"Active"`, To: `"活动"`, Anchor: Props(UIProps...)},
+		{From: `"in"`, To: `"在"`, Anchor: Props("keyString")},
+		{From: `"Run"`, To: `"运行"`, Anchor: Callees("t", "window.showInformationMessage")},
+		{From: "Run command", To: "运行命令"},
+		{From: "`Run ${$1} now`", To: "`立即运行 ${$1}`"},
+		{From: `Exit code (\d+)`, To: `退出码 $1`, Mode: MatchRegexp},
+		{From: "• Disabled - Agent will never run", To: "• 已禁用 - 代理永远不会运行", Mode: MatchEscapes},
+		{From: `"Don't stop • now"`, To: `"不要停止"`, Mode: MatchEscapes},
+	}
+	contexts := []string{
+		`label:"Active"`, `label :  ` + "\n" + ` { 'Active'}`, `xlabel:"Active"`, `data-label="Active"`, `"aria-label":"Active"`,
+		`a.label:"Active"`, `label:"Active'`, `{label:"Active",title:"Active"}`, `keyString:"in"`, `keyString:"in"+"in"`,
+		`t("Run")`, `i18n.t( "Run")`, `a-t("Run")`, `window.showInformationMessage("Run")`, `xt("Run")`, `"Run command"`,
+		"`Run ${a.b} now`", `Exit code 17`, `"• Disabled - Agent will never run"`, `'\u2022 Disabled - Agent will never run'`,
+		`"\u{0002022} Disabled - Agent will never run"`, `"\ud83d\ude00 Disabled - Agent will never run"`, `'Don\'t stop \u2022 now'`, `"Don't stop \xe2 now"`,
+		`label:` + strings.Repeat(" ", 300) + `"Active"`, `"Active"`, `label:"Active"label:"Active"`,
+	}
+	var b strings.Builder
+	for i := range 200 {
+		b.WriteString(contexts[i%len(contexts)])
+		b.WriteString(`;var e=function(t,n){return t&&n?t.concat(n):[]},in=1;`)
+	}
+	for _, src := range []string{b.String(), `label:"Active"`, `"in"`, ``} {
+		for _, rule := range rules {
+			p, err := compilePattern(rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.prefilter.needle) == 0 {
+				t.Errorf("%q 没有预筛选条件", rule.From)
+			}
+			got := p.find([]byte(src))
+			want := p.re.FindAllSubmatchIndex([]byte(src), -1)
+			if !slices.EqualFunc(got, want, slices.Equal) {
+				t.Errorf("%q: find 找到 %d 处，扫描全文找到 %d 处", rule.From, len(got), len(want))
+			}
+		}
+	}
+}
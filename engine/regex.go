@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ========================================
+// 正则规则 (MatchRegexp)
+// ========================================
+//
+// 只差一个数字或名称的字符串 (如 `Exit code ${e.exitCode}` 中被压缩工具重命名的变量、
+// 复数后缀) 可以写成一条 RE2 正则规则:
+//   - 原文是 Go regexp (RE2) 语法，不支持反向引用和环视
+//   - 译文按 regexp.Expand 展开: $1、${1} 引用编号子匹配，${name} 引用 (?P<name>...) 命名子匹配，$$ 输出 "$"
+//   - 译文中要输出 JS 模板的 "${" 时写成 "$${"
+//
+// 编译时检查原文能否匹配空字符串 (会在每个位置插入译文) 和译文是否引用了不存在的子匹配。
+
+// compileRegexp 编译正则规则
+func compileRegexp(rule Rule) (*pattern, error) {
+	if rule.Mode != MatchRegexp {
+		return nil, fmt.Errorf("正则规则不能与其他匹配方式组合")
+	}
+	re, err := regexp.Compile(rule.From)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("正则表达式可以匹配空字符串")
+	}
+	if err := checkExpandTemplate(re, rule.To); err != nil {
+		return nil, err
+	}
+	p := &pattern{re: re, regex: true, expand: []byte(rule.To), limit: rule.Limit}
+	if prefix, _ := re.LiteralPrefix(); len(prefix) >= minNeedle {
+		p.prefilter = prefilter{needle: []byte(prefix), after: -1} // 每处匹配都以该前缀开头
+	}
+	return p, nil
+}
+
+// checkExpandTemplate 检查译文模板中的 $name、${name} 是否都是原文中存在的子匹配
+// regexp.Expand 会把不存在的引用替换为空，"$1x" 也会被当成名为 "1x" 的引用，这类错误只能在这里发现
+func checkExpandTemplate(re *regexp.Regexp, template string) error {
+	names := make(map[string]bool)
+	for i, name := range re.SubexpNames() {
+		names[strconv.Itoa(i)] = true
+		if name != "" {
+			names[name] = true
+		}
+	}
+	isNameByte := func(c byte) bool {
+		return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 >= len(template) {
+			continue
+		}
+		var name string
+		switch next := template[i+1]; {
+		case next == '$':
+			i++
+			continue
+		case next == '{':
+			end := i + 2
+			for end < len(template) && isNameByte(template[end]) {
+				end++
+			}
+			if end == i+2 || end >= len(template) || template[end] != '}' {
+				continue // 不是合法的引用，regexp.Expand 原样输出
+			}
+			name, i = template[i+2:end], end
+		case isNameByte(next):
+			end := i + 1
+			for end < len(template) && isNameByte(template[end]) {
+				end++
+			}
+			name, i = template[i+1:end], end-1
+		default:
+			continue
+		}
+		if !names[name] {
+			return fmt.Errorf("译文引用了原文中不存在的子匹配 $%s (引用后紧跟字母、数字或下划线时请写成 ${N} 的形式)", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+)
+
+// ========================================
+// 规则作用范围 (目标与标记区间)
+// ========================================
+//
+// 同一个规则包可能用于多个文件 (如 main 规则包同时用于 jetskiAgent/main.js 和
+// workbench.desktop.main.js)，"General"、"Terminal" 这类通用字符串在其中一个文件里是设置页的
+// 文字，在另一个文件里却遍布整个 VS Code 工作台。Scope 限定规则生效的范围:
+//   - Targets: 只对这些目标 (ID，如 "antigravity.main") 生效
+//   - Start/End: 只替换 Start 标记之后、End 标记之前的内容 (标记本身不替换，可出现多段)
+//
+// 同一阶段中限定了区间的规则按 (Start, End) 分组，先于不限区间的规则执行，标记按本阶段开始时的内容查找。
+
+// Scope 规则的作用范围，nil 表示对所有目标的整个文件生效
+type Scope struct {
+	Targets []string // 目标 ID，为空表示所有目标
+	Start   string   // 区间开始标记，为空表示不限区间
+	End     string   // 区间结束标记，为空表示到文件末尾；找不到结束标记时该段不替换
+}
+
+// ForTargets 创建只对给定目标生效的作用范围
+func ForTargets(ids ...string) *Scope {
+	return &Scope{Targets: ids}
+}
+
+// Between 创建只在 start 和 end 标记之间生效的作用范围
+func Between(start, end string) *Scope {
+	return &Scope{Start: start, End: end}
+}
+
+// Between 在已有的目标限定上增加区间限定
+func (s *Scope) Between(start, end string) *Scope {
+	scoped := *s
+	scoped.Start, scoped.End = start, end
+	return &scoped
+}
+
+// AppliesTo 判断规则是否对目标生效；target 为空 (未指定目标，如 engine.Apply) 时不按目标过滤
+func (s *Scope) AppliesTo(target string) bool {
+	if s == nil || len(s.Targets) == 0 || target == "" {
+		return true
+	}
+	for _, id := range s.Targets {
+		if id == target {
+			return true
+		}
+	}
+	return false
+}
+
+// String 返回作用范围的可读描述，如 "antigravity.main"、"antigravity.main [\"Agent\" … \"Account\"]"
+func (s *Scope) String() string {
+	if s == nil {
+		return ""
+	}
+	desc := strings.Join(s.Targets, ",")
+	if s.Start != "" {
+		if desc != "" {
+			desc += " "
+		}
+		desc += "[" + s.Start + " … " + s.End + "]"
+	}
+	return desc
+}
+
+// region 返回限定的区间标记，不限区间时返回两个空字符串
+func (s *Scope) region() (start, end string) {
+	if s == nil || s.Start == "" {
+		return "", ""
+	}
+	return s.Start, s.End
+}
+
+// WithScope 为一组规则设置作用范围
+func WithScope(scope *Scope, rules []Rule) []Rule {
+	for i := range rules {
+		rules[i].Scope = scope
+	}
+	return rules
+}
+
+// span 内容中的一个区间 [start, end)
+type span struct{ start, end int }
+
+// findRanges 返回 src 中每个 start 标记之后、end 标记之前的区间
+func findRanges(src []byte, start, end string) []span {
+	var spans []span
+	i := 0
+	for {
+		k := bytes.Index(src[i:], []byte(start))
+		if k < 0 {
+			return spans
+		}
+		from, to := i+k+len(start), len(src)
+		if end != "" {
+			e := bytes.Index(src[from:], []byte(end))
+			if e < 0 {
+				return spans
+			}
+			to = from + e
+		}
+		spans = append(spans, span{from, to})
+		if end == "" {
+			return spans
+		}
+		i = to + len(end)
+	}
+}
+
+// applyRanges 对 src 中每个 start 标记之后、end 标记之前的区间调用 fn，其余内容保持不变
+func applyRanges(src []byte, start, end string, fn func([]byte) []byte) []byte {
+	out, _ := applySpans(src, findRanges(src, start, end), func(chunk []byte, _ editFunc) []byte { return fn(chunk) }, nil)
+	return out
+}
+
+// applySpans 对 src 中的每个区间调用 fn，其余内容保持不变，返回结果和各区间在结果中的位置
+// edit 不为空时，fn 报告的替换位置换算为整个内容中的偏移后再转给 edit
+func applySpans(src []byte, spans []span, fn func(chunk []byte, edit editFunc) []byte, edit editFunc) ([]byte, []span) {
+	if len(spans) == 0 {
+		return src, spans
+	}
+	var out []byte
+	moved := make([]span, len(spans))
+	last := 0
+	for i, sp := range spans {
+		out = append(out, src[last:sp.start]...)
+		base := len(out)
+		var chunkEdit editFunc
+		if edit != nil {
+			chunkEdit = func(index, srcStart, srcEnd, dstStart, dstEnd int) {
+				edit(index, sp.start+srcStart, sp.start+srcEnd, base+dstStart, base+dstEnd)
+			}
+		}
+		out = append(out, fn(src[sp.start:sp.end], chunkEdit)...)
+		moved[i] = span{base, len(out)}
+		last = sp.end
+	}
+	return append(out, src[last:]...), moved
+}
+
+// ruleGroup 阶段中对某个目标、在同一区间内生效的规则
+type ruleGroup struct {
+	start, end string
+	replacer   *Replacer // 不属于本组的规则原文置空 (下标与阶段规则一致)
+	patterns   []indexedPattern
+}
+
+// groupsFor 按目标和区间把阶段的规则分组，限定区间的组在前，不限区间的组在最后
+func (p *Phase) groupsFor(target string) []*ruleGroup {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if groups, ok := p.groups[target]; ok {
+		return groups
+	}
+
+	type key struct{ start, end string }
+	var order []key
+	members := make(map[key][]bool)
+	for i, rule := range p.rules {
+		if !rule.Scope.AppliesTo(target) {
+			continue
+		}
+		start, end := rule.Scope.region()
+		k := key{start, end}
+		if members[k] == nil {
+			members[k] = make([]bool, len(p.rules))
+			if start != "" {
+				order = append(order, k)
+			}
+		}
+		members[k][i] = true
+	}
+	order = append(order, key{})
+
+	var groups []*ruleGroup
+	for _, k := range order {
+		in := members[k]
+		g := &ruleGroup{start: k.start, end: k.end}
+		rules := make([]Rule, len(p.rules))
+		for i, rule := range p.rules {
+			if in != nil && in[i] && !rule.IsPattern() {
+				rules[i] = rule
+			}
+		}
+		g.replacer = NewReplacer(rules)
+		for _, pat := range p.patterns {
+			if in != nil && in[pat.index] {
+				g.patterns = append(g.patterns, pat)
+			}
+		}
+		groups = append(groups, g)
+	}
+	if p.groups == nil {
+		p.groups = make(map[string][]*ruleGroup)
+	}
+	p.groups[target] = groups
+	return groups
+}
+
+// apply 在 content 上应用本组规则，命中次数累加到 counts，跳过的匹配数累加到 skipped；tr 不为空时记录每处替换
+// 字面量规则的自动机和每条模式规则依次作为一步，限定了区间时每一步只替换本组开始时找到的区间
+func (g *ruleGroup) apply(content []byte, counts, skipped []int, sample func(index int, from, to []byte), tr *tracer) []byte {
+	steps := make([]func(src []byte, edit editFunc) []byte, 0, 1+len(g.patterns))
+	steps = append(steps, func(src []byte, edit editFunc) []byte {
+		out, n := g.replacer.replace(src, edit)
+		for i, c := range n {
+			counts[i] += c
+		}
+		return out
+	})
+	for _, pat := range g.patterns {
+		steps = append(steps, func(src []byte, edit editFunc) []byte {
+			var patEdit editFunc
+			if edit != nil {
+				patEdit = func(_, srcStart, srcEnd, dstStart, dstEnd int) { edit(pat.index, srcStart, srcEnd, dstStart, dstEnd) }
+			}
+			out, c, s := pat.replace(src, func(from, to []byte) { sample(pat.index, from, to) }, patEdit)
+			counts[pat.index] += c
+			skipped[pat.index] += s
+			return out
+		})
+	}
+
+	var spans []span
+	if g.start != "" {
+		if spans = findRanges(content, g.start, g.end); len(spans) == 0 {
+			return content
+		}
+	}
+	for _, step := range steps {
+		var edits []editSpan
+		var edit editFunc
+		if tr != nil {
+			edit = func(index, srcStart, srcEnd, dstStart, dstEnd int) {
+				edits = append(edits, editSpan{index, srcStart, srcEnd, dstStart, dstEnd})
+			}
+		}
+		var out []byte
+		if g.start == "" {
+			out = step(content, edit)
+		} else {
+			out, spans = applySpans(content, spans, step, edit)
+		}
+		if len(edits) > 0 {
+			tr.step(content, out, edits)
+		}
+		content = out
+	}
+	return content
+}
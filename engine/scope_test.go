@@ -0,0 +1,33 @@
+package engine
+
+import "testing"
+
+// TestScopeBetween 限定区间的规则只替换开始标记之后、结束标记之前的内容
+func TestScopeBetween(t *testing.T) {
+	rules := []Rule{
+		{From: `"Fast"`, To: `"快速"`, Scope: ForTargets("antigravity.workbench").Between("<s>", "</s>")},
+		{From: "Slow", To: "慢速", Scope: Between("<s>", ""), Mode: MatchEscapes},
+	}
+	set := &RuleSet{Name: "test", Phases: []*Phase{NewPhase("normal", CategoryNormal, func() []Rule { return rules })}}
+	tests := []struct {
+		name, target, src, want string
+	}{
+		{"区间内外", "antigravity.workbench", `"Fast"<s>"Fast"</s>"Fast"`, `"Fast"<s>"快速"</s>"Fast"`},
+		{"多段区间", "antigravity.workbench", `<s>"Fast"</s>"Fast"<s>"Fast"</s>`, `<s>"快速"</s>"Fast"<s>"快速"</s>`},
+		{"缺少结束标记", "antigravity.workbench", `"Fast"<s>"Fast"`, `"Fast"<s>"Fast"`},
+		{"缺少开始标记", "antigravity.workbench", `"Fast"</s>"Fast"`, `"Fast"</s>"Fast"`},
+		{"其他目标", "antigravity.main", `<s>"Fast"</s>`, `<s>"Fast"</s>`},
+		{"结束标记为空时到文件末尾", "", `"Slow"<s>"Slow","Slow"`, `"Slow"<s>"慢速","慢速"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, _, err := ApplyTarget(t.Context(), tt.target, tt.src, set)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out != tt.want {
+				t.Errorf("Apply(%q) = %q, want %q", tt.src, out, tt.want)
+			}
+		})
+	}
+}
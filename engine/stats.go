@@ -0,0 +1,84 @@
+package engine
+
+// Stats 翻译统计
+type Stats struct {
+	NormalCount   int         `json:"normal_count"`
+	TemplateCount int         `json:"template_count"`
+	VariableCount int         `json:"variable_count"`
+	RegexCount    int         `json:"regex_count"`
+	Rules         []RuleHit   `json:"rules"`                // 每条命中规则的明细
+	Violations    []Violation `json:"violations,omitempty"` // 命中次数不符合预期的规则
+	Skipped       []Skip      `json:"skipped,omitempty"`    // 匹配到但无法确定字面量引号而未替换的规则
+}
+
+// Skip 规则因无法确定字面量的引号而跳过的次数: 整个字面量规则匹配到两端引号不同的文本 (如 "Run')，
+// 或转义无关的片段规则匹配到字符串字面量之外 (如注释中) 的文本。
+// 通常说明原文恰好出现在两个相邻字面量的交界处，或上游改变了引号写法
+type Skip struct {
+	Kind  string `json:"kind"`
+	From  string `json:"from"`
+	Count int    `json:"count"`
+}
+
+// Violation 命中次数不符合 Expect 的规则
+type Violation struct {
+	Kind    string `json:"kind"`
+	From    string `json:"from"`
+	Expect  string `json:"expect"`            // 如 "恰好 1 次"
+	Count   int    `json:"count"`             // 实际命中次数
+	Missing bool   `json:"missing,omitempty"` // 命中次数少于预期 (否则为多于预期)
+	Skipped int    `json:"skipped,omitempty"` // 因无法确定引号而跳过的匹配数
+}
+
+// RuleHit 单条规则的命中情况
+type RuleHit struct {
+	Kind    string   `json:"kind"` // 规则类型 ("normal", "template", "variable", "quoted", "raw", "regex", "anchored")
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Anchor  string   `json:"anchor,omitempty"`  // 锚定规则的锚点，如 "label|title"
+	Scope   string   `json:"scope,omitempty"`   // 规则的作用范围，如 "antigravity.main"
+	Count   int      `json:"count"`             // 替换次数
+	Samples []Sample `json:"samples,omitempty"` // 模式规则的前几处实际替换 (原文和译文可能随匹配变化)
+}
+
+// MaxSamples 每条模式规则最多记录的替换样例数
+const MaxSamples = 3
+
+// Sample 一处实际发生的替换
+type Sample struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Merge 累加另一份统计
+func (s *Stats) Merge(other Stats) {
+	s.NormalCount += other.NormalCount
+	s.TemplateCount += other.TemplateCount
+	s.VariableCount += other.VariableCount
+	s.RegexCount += other.RegexCount
+	s.Rules = append(s.Rules, other.Rules...)
+	s.Violations = append(s.Violations, other.Violations...)
+	s.Skipped = append(s.Skipped, other.Skipped...)
+}
+
+// Hits 返回所有规则的替换总次数
+func (s Stats) Hits() int {
+	n := 0
+	for _, hit := range s.Rules {
+		n += hit.Count
+	}
+	return n
+}
+
+func (s *Stats) count(c Category) {
+	switch c {
+	case CategoryTemplate:
+		s.TemplateCount++
+	case CategoryVariable:
+		s.VariableCount++
+	case CategoryRegex:
+		s.RegexCount++
+	default:
+		s.NormalCount++
+	}
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// 替换记录 (trace)
+// ========================================
+//
+// ApplyTargetTraced 在翻译的同时记录每处替换在最终结果中的位置、产生它的规则和实际替换的文本，
+// 用于从界面上的一段译文反查规则 (explain) 和只撤销某些规则的替换 (revert)。
+//
+// 每个阶段中字面量规则的扫描和每条模式规则各算一步。每一步之后，之前记录的位置按本步的替换换算:
+// 不受影响的记录按长度变化平移；被本步的一处替换完整覆盖、且译文仍原样出现在新译文中的记录
+// (如模板规则把含有已翻译字面量的表达式原样输出) 移到新的位置；其余被改写的记录丢弃。
+
+// Replacement 一处替换
+type Replacement struct {
+	Offset int    `json:"offset"` // 译文在结果中的字节偏移
+	Length int    `json:"length"` // 译文的字节长度
+	Rule   string `json:"rule"`   // 规则 ID (见 RuleID)
+	Source string `json:"source"` // 被替换的原文
+	Target string `json:"target"` // 输出的译文
+}
+
+// RuleID 返回规则的 ID: 规则集名称/阶段类型/规则内容的短哈希 (见 Phase.Keys)，如 "chat/normal/3fa2c1d0"
+// 哈希只取规则类型、原文、锚点和作用范围，规则表增删其他规则后 ID 不变；
+// 修改译文后 ID 也不变，使用旧的替换记录前仍应先用 Rule.Produces 核对
+func RuleID(set, phase, key string) string {
+	return set + "/" + phase + "/" + key
+}
+
+// ruleKey 返回规则内容的短哈希 (SHA-256 的前 8 个十六进制字符)
+func ruleKey(rule Rule) string {
+	sum := sha256.Sum256([]byte(rule.Kind + "\x00" + rule.From + "\x00" + rule.Anchor.String() + "\x00" + rule.Scope.String()))
+	return hex.EncodeToString(sum[:4])
+}
+
+// ruleKeys 返回一组规则的短哈希；同一阶段中内容相同的规则 (通常是用户规则重复) 依次加上 "-2"、"-3" 等后缀
+func ruleKeys(rules []Rule) []string {
+	keys := make([]string, len(rules))
+	seen := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		key := ruleKey(rule)
+		seen[key]++
+		if n := seen[key]; n > 1 {
+			key += "-" + strconv.Itoa(n)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// RulePosition 返回规则的位置: 规则集名称/阶段类型/规则在阶段中的序号 (从 1 开始)，如 "chat/normal/12"
+// 位置随规则表增删规则而变化，只用于显示；记录和引用规则时使用 RuleID
+func RulePosition(set, phase string, index int) string {
+	return fmt.Sprintf("%s/%s/%d", set, phase, index)
+}
+
+// ParseRuleID 解析规则 ID，key 为规则内容的短哈希 (可能带重复后缀)
+func ParseRuleID(id string) (set, phase, key string, ok bool) {
+	parts := strings.Split(id, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[2], true
+}
+
+// Rule 按 ID 查找规则集中的规则，同时返回它在阶段中的序号 (从 1 开始，用于 RulePosition)
+func (s *RuleSet) Rule(id string) (Rule, int, bool) {
+	set, phase, key, ok := ParseRuleID(id)
+	if !ok || set != s.Name {
+		return Rule{}, 0, false
+	}
+	for _, p := range s.Phases {
+		if p.Kind != phase {
+			continue
+		}
+		if i := slices.Index(p.Keys(), key); i >= 0 {
+			return p.rules[i], i + 1, true
+		}
+	}
+	return Rule{}, 0, false
+}
+
+// Produces 判断规则应用到 source 上是否恰好得到 target，用于核对替换记录中的规则 ID 是否仍指向产生这处替换的规则；
+// 记录的原文不含锚点，核对时不考虑锚点、作用范围和次数限制
+func (r Rule) Produces(source, target string) bool {
+	r.Anchor, r.Scope, r.Limit = nil, nil, 0
+	out, n := ApplyRule(r, source)
+	return n > 0 && out == target
+}
+
+// editFunc 报告一处替换: 规则在阶段中的下标，原文在输入中的位置 [srcStart, srcEnd)，译文在输出中的位置 [dstStart, dstEnd)
+type editFunc func(index, srcStart, srcEnd, dstStart, dstEnd int)
+
+// editSpan 一处替换的位置
+type editSpan struct {
+	index            int
+	srcStart, srcEnd int
+	dstStart, dstEnd int
+}
+
+// tracer 翻译过程中的替换记录，位置始终对应当前内容
+type tracer struct {
+	set, phase string   // 当前执行的规则集和阶段
+	keys       []string // 当前阶段规则内容的短哈希 (见 RuleID)
+	entries    []Replacement
+}
+
+// step 把已有记录换算到一步替换之后的内容 dst 中，并加入本步的替换 (edits 按位置排序)
+func (t *tracer) step(src, dst []byte, edits []editSpan) {
+	kept := make([]Replacement, 0, len(t.entries)+len(edits))
+	k, delta := 0, 0
+	for _, r := range t.entries {
+		for k < len(edits) && edits[k].srcEnd <= r.Offset {
+			delta += (edits[k].dstEnd - edits[k].dstStart) - (edits[k].srcEnd - edits[k].srcStart)
+			k++
+		}
+		end := r.Offset + r.Length
+		if k == len(edits) || edits[k].srcStart >= end {
+			r.Offset += delta
+			kept = append(kept, r)
+			continue
+		}
+		e := edits[k]
+		if e.srcStart > r.Offset || e.srcEnd < end {
+			continue // 部分被改写
+		}
+		produced := dst[e.dstStart:e.dstEnd]
+		rel := min(r.Offset-e.srcStart, len(produced))
+		i := bytes.Index(produced[rel:], []byte(r.Target))
+		if i >= 0 {
+			i += rel
+		} else if i = bytes.Index(produced, []byte(r.Target)); i < 0 {
+			continue
+		}
+		r.Offset = e.dstStart + i
+		kept = append(kept, r)
+	}
+	for _, e := range edits {
+		kept = append(kept, Replacement{
+			Offset: e.dstStart,
+			Length: e.dstEnd - e.dstStart,
+			Rule:   RuleID(t.set, t.phase, t.keys[e.index]),
+			Source: string(src[e.srcStart:e.srcEnd]),
+			Target: string(dst[e.dstStart:e.dstEnd]),
+		})
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Offset < kept[j].Offset })
+	t.entries = kept
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestRuleProduces 替换记录中的原文和译文与规则核对 (规则的译文在汉化后可能已经修改)
+func TestRuleProduces(t *testing.T) {
+	tests := []struct {
+		name           string
+		rule           Rule
+		source, target string
+		want           bool
+	}{
+		{"字面量", Rule{From: `"Browser"`, To: `"浏览器"`}, `"Browser"`, `"浏览器"`, true},
+		{"另一条规则的记录", Rule{From: `"Browser"`, To: `"浏览器"`}, `"Bytes"`, `"字节"`, false},
+		{"译文已修改", Rule{From: `"Browser"`, To: `"浏览器"`}, `"Browser"`, `"浏览"`, false},
+		{"锚定规则 (记录不含锚点)", Rule{From: `"Active"`, To: `"活动"`, Anchor: Props("label")}, `"Active"`, `"活动"`, true},
+		{"转义无关规则", Rule{From: `"Don't stop"`, To: `"不要停止"`, Mode: MatchEscapes}, `'Don\'t stop'`, `'不要停止'`, true},
+		{"正则规则", Rule{From: `Exit code \$\{([\w.]+)\}`, To: `退出码 $${$1}`, Mode: MatchRegexp}, "Exit code ${t.exitCode}", "退出码 ${t.exitCode}", true},
+		{"正则规则的子匹配不同", Rule{From: `Exit code \$\{([\w.]+)\}`, To: `退出码 $${$1}`, Mode: MatchRegexp}, "Exit code ${t.exitCode}", "退出码 ${n}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Produces(tt.source, tt.target); got != tt.want {
+				t.Errorf("Produces(%q, %q) = %v, want %v", tt.source, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRuleID 规则 ID 由规则内容决定，在前面插入规则后不变，只有显示用的位置随之变化
+func TestRuleID(t *testing.T) {
+	rules := []Rule{
+		{Kind: "normal", From: `"Browser"`, To: `"浏览器"`},
+		{Kind: "normal", From: `"Active"`, To: `"活动"`, Anchor: Props("label")},
+		{Kind: "normal", From: `"Active"`, To: `"活动"`, Anchor: Props("title")},
+		{Kind: "normal", From: `"Bytes"`, To: `"字节"`, Scope: ForTargets("antigravity.main")},
+		{Kind: "normal", From: `"Bytes"`, To: `"字节数"`, Scope: ForTargets("antigravity.main")},
+	}
+	build := func(rules []Rule) *RuleSet {
+		return &RuleSet{Name: "chat", Phases: []*Phase{NewPhase("normal", CategoryNormal, func() []Rule { return rules })}}
+	}
+	before := build(rules)
+	after := build(append([]Rule{{Kind: "normal", From: `"Agent"`, To: `"代理"`}}, rules...))
+
+	keys := before.Phases[0].Keys()
+	for i, key := range keys {
+		if slices.Contains(keys[:i], key) {
+			t.Errorf("规则 %d 的 ID %q 与前面的规则重复", i+1, key)
+		}
+		id := RuleID("chat", "normal", key)
+		got, index, ok := after.Rule(id)
+		if !ok || got != rules[i] {
+			t.Errorf("插入规则后 %s 没有指向规则 %d (%q)", id, i+1, rules[i].From)
+			continue
+		}
+		if index != i+2 {
+			t.Errorf("%s 的位置为 %d，want %d", id, index, i+2)
+		}
+	}
+	if keys[3] == keys[4] || keys[4] != keys[3]+"-2" {
+		t.Errorf("内容相同的规则应加上重复后缀: %q, %q", keys[3], keys[4])
+	}
+	if _, _, ok := before.Rule(RulePosition("chat", "normal", 1)); ok {
+		t.Error("按位置编号的 ID 不应再能找到规则")
+	}
+}
@@ -0,0 +1,141 @@
+package literals
+
+import "bytes"
+
+// ========================================
+// 两个版本的字面量对齐
+// ========================================
+//
+// 比较原版和手工修改过的 bundle: 两个文件依次交替的 "代码、字面量、代码、字面量 ..." 中，代码部分应当
+// 完全相同，字面量一一对应。代码部分不同时记为一个代码改动区间，向后查找连续几段代码重新相同的位置继续对齐；
+// 区间内的字面量不参与对齐。
+
+// alignWindow 重新对齐时在每个文件中最多向后查找的字面量个数
+const alignWindow = 64
+
+// alignConfirm 重新对齐时要求之后连续相同的代码段数
+const alignConfirm = 3
+
+// Pair 两个版本中对齐的一对字面量 (内容可能相同)
+type Pair struct {
+	Old, New Literal
+}
+
+// Region 两个版本中字符串字面量之外的差异 (代码改动)，区间为字节偏移 [Start, End)
+type Region struct {
+	OldStart, OldEnd int
+	NewStart, NewEnd int
+}
+
+// Alignment 对齐结果
+type Alignment struct {
+	Old, New []Literal // 两个版本的全部字面量
+	Pairs    []Pair    // 对齐的字面量，按出现顺序排列
+	Regions  []Region  // 代码改动
+}
+
+// Align 对齐两个版本的字面量
+func Align(oldContent, newContent []byte) *Alignment {
+	a := &Alignment{Old: Extract(oldContent), New: Extract(newContent)}
+	o := sequence{content: oldContent, lits: a.Old}
+	n := sequence{content: newContent, lits: a.New}
+
+	i, j := 0, 0
+	for {
+		if bytes.Equal(o.gap(i), n.gap(j)) {
+			if i == len(a.Old) && j == len(a.New) {
+				return a
+			}
+			if i < len(a.Old) && j < len(a.New) {
+				a.Pairs = append(a.Pairs, Pair{Old: a.Old[i], New: a.New[j]})
+				i, j = i+1, j+1
+				continue
+			}
+		}
+		// 代码不同 (或一边的字面量已经用完): 查找重新对齐的位置
+		ni, nj, ok := resync(o, n, i, j)
+		if !ok {
+			a.Regions = append(a.Regions, trimRegion(oldContent, newContent, o.gapStart(i), len(oldContent), n.gapStart(j), len(newContent)))
+			return a
+		}
+		a.Regions = append(a.Regions, trimRegion(oldContent, newContent, o.gapStart(i), o.litStart(ni), n.gapStart(j), n.litStart(nj)))
+		if ni == len(a.Old) && nj == len(a.New) {
+			return a
+		}
+		a.Pairs = append(a.Pairs, Pair{Old: a.Old[ni], New: a.New[nj]})
+		i, j = ni+1, nj+1
+	}
+}
+
+// sequence 一个版本的内容及其字面量
+type sequence struct {
+	content []byte
+	lits    []Literal
+}
+
+// gapStart 第 k 个字面量之前的代码段的开头 (即上一个字面量的结尾)
+func (s sequence) gapStart(k int) int {
+	if k == 0 {
+		return 0
+	}
+	return s.lits[k-1].End
+}
+
+// litStart 第 k 个字面量的开头，k 等于字面量个数时为内容末尾
+func (s sequence) litStart(k int) int {
+	if k >= len(s.lits) {
+		return len(s.content)
+	}
+	return s.lits[k].Start
+}
+
+// gap 第 k 个字面量之前的代码段，k 等于字面量个数时为最后一个字面量之后的代码，超出时返回 nil
+func (s sequence) gap(k int) []byte {
+	if k > len(s.lits) {
+		return nil
+	}
+	return s.content[s.gapStart(k):s.litStart(k)]
+}
+
+// resync 从 (i, j) 开始查找之后连续 alignConfirm 段代码相同 (或同时到达末尾) 的字面量位置，
+// 优先选择两边跳过的字面量总数最少的位置
+func resync(o, n sequence, i, j int) (int, int, bool) {
+	for d := 0; d <= 2*alignWindow; d++ {
+		for a := max(0, d-alignWindow); a <= min(d, alignWindow); a++ {
+			ni, nj := i+a, j+d-a
+			if ni > len(o.lits) || nj > len(n.lits) {
+				continue
+			}
+			if ni == len(o.lits) && nj == len(n.lits) {
+				return ni, nj, true
+			}
+			if ni == len(o.lits) || nj == len(n.lits) {
+				continue
+			}
+			ok := true
+			for t := 1; t <= alignConfirm && ok; t++ {
+				og, ng := o.gap(ni+t), n.gap(nj+t)
+				if og == nil || ng == nil {
+					ok = og == nil && ng == nil
+					break
+				}
+				ok = bytes.Equal(og, ng)
+			}
+			if ok {
+				return ni, nj, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// trimRegion 去掉区间两端相同的内容，只保留实际不同的部分
+func trimRegion(oldContent, newContent []byte, oldStart, oldEnd, newStart, newEnd int) Region {
+	for oldStart < oldEnd && newStart < newEnd && oldContent[oldStart] == newContent[newStart] {
+		oldStart, newStart = oldStart+1, newStart+1
+	}
+	for oldEnd > oldStart && newEnd > newStart && oldContent[oldEnd-1] == newContent[newEnd-1] {
+		oldEnd, newEnd = oldEnd-1, newEnd-1
+	}
+	return Region{OldStart: oldStart, OldEnd: oldEnd, NewStart: newStart, NewEnd: newEnd}
+}
@@ -0,0 +1,327 @@
+// Package literals 从压缩后的 JS bundle 中提取字符串字面量。
+//
+// 提取器是一个只识别注释、字符串、模板字面量和正则字面量的简化词法分析器，不构建语法树，
+// 足以在几十 MB 的 bundle 中快速、稳定地找出所有 "..."、'...' 和 `...`。模板字面量作为
+// 一个整体返回 (${...} 中的表达式原样保留在文本中)。
+package literals
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Literal 一个字符串字面量
+type Literal struct {
+	Start int    // 开头引号在内容中的字节偏移
+	End   int    // 结尾引号之后的字节偏移
+	Quote byte   // '"'、'\'' 或 '`'
+	Raw   string // 含引号的原始文本
+}
+
+// Text 返回去掉引号的原始文本 (转义保持原样)
+func (l Literal) Text() string {
+	return l.Raw[1 : len(l.Raw)-1]
+}
+
+// Value 返回解码转义后的文本，模板字面量中的 ${...} 原样保留
+func (l Literal) Value() string {
+	return Decode(l.Text())
+}
+
+// Unquote 拆开首尾是同一种引号的完整字符串字面量 (如规则原文 `"Accept"`)，返回去掉引号的文本和引号
+func Unquote(s string) (text string, quote byte, ok bool) {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'' || s[0] == '`') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1], s[0], true
+	}
+	return "", 0, false
+}
+
+// regexKeywords 之后出现 "/" 时表示正则字面量而不是除号的关键字
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "instanceof": true, "yield": true, "await": true,
+}
+
+// headerKeywords 之后的 (...) 是语句头的关键字: 配对的 ")" 之后是语句 (可以以正则字面量开头)，而不是除号
+var headerKeywords = map[string]bool{"if": true, "while": true, "for": true, "with": true}
+
+// Extract 按出现顺序返回 content 中的所有字符串字面量
+// 未闭合的字面量 (如被截断的文件末尾) 被忽略
+func Extract(content []byte) []Literal {
+	var lits []Literal
+	s := &scanner{src: content, regex: true}
+	s.scan(&lits, false)
+	return lits
+}
+
+// scanner 简化的 JS 词法扫描器
+type scanner struct {
+	src    []byte
+	pos    int
+	regex  bool   // 此处的 "/" 开始正则字面量 (上一个记号之后需要一个操作数)，否则是除号
+	word   string // 上一个记号是标识符或关键字时的名称
+	parens []bool // 未闭合的 "(" 是否为 if/while/for/with 的语句头
+}
+
+// scan 扫描代码，inExpr 为 true 时扫描模板字面量 ${...} 中的表达式，遇到配对的 "}" 返回
+func (s *scanner) scan(lits *[]Literal, inExpr bool) {
+	depth := 0
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '"' || c == '\'':
+			start := s.pos
+			if s.skipString(c) && lits != nil {
+				*lits = append(*lits, Literal{Start: start, End: s.pos, Quote: c, Raw: string(s.src[start:s.pos])})
+			}
+			s.regex, s.word = false, ""
+		case c == '`':
+			start := s.pos
+			if s.skipTemplate() && lits != nil {
+				*lits = append(*lits, Literal{Start: start, End: s.pos, Quote: c, Raw: string(s.src[start:s.pos])})
+			}
+			s.regex, s.word = false, ""
+		case c == '/' && s.peek(1) == '/':
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		case c == '/' && s.peek(1) == '*':
+			end := bytes.Index(s.src[s.pos+2:], []byte("*/"))
+			if end < 0 {
+				s.pos = len(s.src)
+			} else {
+				s.pos += 2 + end + 2
+			}
+		case c == '/' && s.regex:
+			s.skipRegex()
+			s.regex, s.word = false, ""
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			s.pos++
+		case isIdentByte(c):
+			start := s.pos
+			for s.pos < len(s.src) && isIdentByte(s.src[s.pos]) {
+				s.pos++
+			}
+			s.word = string(s.src[start:s.pos])
+			s.regex = regexKeywords[s.word]
+		case (c == '+' || c == '-') && s.peek(1) == c:
+			// 需要操作数的位置是前缀 ++x (之后仍需要操作数)，否则是后缀 x++ (之后是运算符)
+			s.pos += 2
+			s.word = ""
+		default:
+			if inExpr {
+				if c == '{' {
+					depth++
+				} else if c == '}' {
+					if depth == 0 {
+						return
+					}
+					depth--
+				}
+			}
+			s.pos++
+			s.punct(c)
+		}
+	}
+}
+
+// punct 根据标点更新扫描状态
+func (s *scanner) punct(c byte) {
+	switch c {
+	case '(':
+		s.parens = append(s.parens, headerKeywords[s.word])
+		s.regex = true
+	case ')':
+		header := false
+		if n := len(s.parens); n > 0 {
+			header, s.parens = s.parens[n-1], s.parens[:n-1]
+		}
+		s.regex = header
+	case ']':
+		s.regex = false
+	default:
+		s.regex = strings.IndexByte("{},;=:[!&|?+-*/%<>~^", c) >= 0
+	}
+	s.word = ""
+}
+
+func (s *scanner) peek(n int) byte {
+	if s.pos+n < len(s.src) {
+		return s.src[s.pos+n]
+	}
+	return 0
+}
+
+// skipString 跳过 "..." 或 '...'，返回是否正常闭合 (遇到未转义的换行视为未闭合)
+func (s *scanner) skipString(quote byte) bool {
+	s.pos++
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos += 2
+		case quote:
+			s.pos++
+			return true
+		case '\n':
+			return false
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.src)
+	return false
+}
+
+// skipTemplate 跳过模板字面量 (含嵌套的 ${...} 表达式)，返回是否正常闭合
+func (s *scanner) skipTemplate() bool {
+	s.pos++
+	for s.pos < len(s.src) {
+		switch c := s.src[s.pos]; {
+		case c == '\\':
+			s.pos += 2
+		case c == '`':
+			s.pos++
+			return true
+		case c == '$' && s.peek(1) == '{':
+			s.pos += 2
+			regex, word, parens := s.regex, s.word, len(s.parens)
+			s.regex, s.word = true, ""
+			s.scan(nil, true) // 表达式中的字面量属于外层模板，不单独提取
+			s.regex, s.word, s.parens = regex, word, s.parens[:min(parens, len(s.parens))]
+			if s.pos < len(s.src) {
+				s.pos++ // 配对的 "}"
+			}
+		default:
+			s.pos++
+		}
+	}
+	s.pos = len(s.src)
+	return false
+}
+
+// skipRegex 跳过正则字面量 (含字符类中的 "/" 和末尾的标志)
+func (s *scanner) skipRegex() {
+	s.pos++
+	inClass := false
+	for s.pos < len(s.src) {
+		switch s.src[s.pos] {
+		case '\\':
+			s.pos += 2
+			continue
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return
+		case '/':
+			if !inClass {
+				s.pos++
+				for s.pos < len(s.src) && isIdentByte(s.src[s.pos]) {
+					s.pos++
+				}
+				return
+			}
+		}
+		s.pos++
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// Decode 解码 JS 字符串中的转义 (\n、\t、\uXXXX、\u{X}、\xXX、\" 等)，无法识别的转义去掉反斜杠
+func Decode(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '\\' || i+1 >= len(text) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := text[i]; e {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\n':
+			// 续行
+		case 'x':
+			if v, err := strconv.ParseUint(safeSlice(text, i+1, i+3), 16, 32); err == nil {
+				b.WriteRune(rune(v))
+				i += 2
+			} else {
+				b.WriteByte(e)
+			}
+		case 'u':
+			r, n := decodeUnicode(text[i+1:])
+			if n == 0 {
+				b.WriteByte(e)
+				continue
+			}
+			i += n
+			// 代理对
+			if r >= 0xD800 && r < 0xDC00 && strings.HasPrefix(text[i+1:], `\u`) {
+				if lo, m := decodeUnicode(text[i+3:]); m > 0 && lo >= 0xDC00 && lo < 0xE000 {
+					r = (r-0xD800)<<10 + (lo - 0xDC00) + 0x10000
+					i += 2 + m
+				}
+			}
+			if !utf8.ValidRune(r) {
+				r = utf8.RuneError
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(e)
+		}
+	}
+	return b.String()
+}
+
+// decodeUnicode 解析 \u 之后的 XXXX 或 {X...}，返回码点和消耗的字节数
+func decodeUnicode(s string) (rune, int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 2 {
+			return 0, 0
+		}
+		v, err := strconv.ParseUint(s[1:end], 16, 32)
+		if err != nil {
+			return 0, 0
+		}
+		return rune(v), end + 1
+	}
+	v, err := strconv.ParseUint(safeSlice(s, 0, 4), 16, 32)
+	if err != nil || len(s) < 4 {
+		return 0, 0
+	}
+	return rune(v), 4
+}
+
+// safeSlice 返回 s[i:j]，越界时截断
+func safeSlice(s string, i, j int) string {
+	if i > len(s) {
+		return ""
+	}
+	if j > len(s) {
+		j = len(s)
+	}
+	return s[i:j]
+}
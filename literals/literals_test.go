@@ -0,0 +1,94 @@
+package literals
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestExtract 区分正则字面量和除号，提取所有字符串字面量
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{"普通字面量", `a("x",'y',` + "`z`" + `)`, []string{`"x"`, `'y'`, "`z`"}},
+		{"转义的引号", `a="x\"y",b='it\'s'`, []string{`"x\"y"`, `'it\'s'`}},
+		{"注释", "a=1;// \"x\"\nb=\"y\"/* 'z' */;c='w'", []string{`"y"`, `'w'`}},
+		{"除号", `a=b/2;c="x";d=e/f/"y"`, []string{`"x"`, `"y"`}},
+		{"正则字面量", `a=/"x'/g;b="y"`, []string{`"y"`}},
+		{"字符类中的斜杠", `a=/[/"]/;b="y"`, []string{`"y"`}},
+		{"关键字之后的正则", `return/"x"/.test(s)?"y":"z"`, []string{`"y"`, `"z"`}},
+		{"后缀自增之后是除号", `a=x++/2;b='k'`, []string{`'k'`}},
+		{"后缀自减之后是除号", `a=x--/2;b="k"`, []string{`"k"`}},
+		{"前缀自增之后是正则", `a=++/x"/.lastIndex;b="k"`, []string{`"k"`}},
+		{"自增后的加号", `a=x+++/"/.source.length;b="k"`, []string{`"k"`}},
+		{"if 条件之后是正则", `if(a)/re"g/.test(s);q="ok"`, []string{`"ok"`}},
+		{"while 条件之后是正则", `while(f(a))/"/.exec(s);q="ok"`, []string{`"ok"`}},
+		{"调用之后是除号", `a=f(b)/2;c="x"`, []string{`"x"`}},
+		{"数组之后是除号", `a=b[0]/2;c="x"`, []string{`"x"`}},
+		{"模板中的表达式", "a=`x${f(\"y\")/2}z`;b=\"w\"", []string{"`x${f(\"y\")/2}z`", `"w"`}},
+		{"模板表达式中的正则", "a=`${/\"/.source}`;b=\"w\"", []string{"`${/\"/.source}`", `"w"`}},
+		{"未闭合的字面量", "a=\"x\nb='y'", []string{`'y'`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, lit := range Extract([]byte(tt.src)) {
+				if tt.src[lit.Start:lit.End] != lit.Raw {
+					t.Errorf("%q 的偏移 [%d, %d) 与内容不符", lit.Raw, lit.Start, lit.End)
+				}
+				got = append(got, lit.Raw)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDecode 解码 JS 字符串中的转义
+func TestDecode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{`plain`, "plain"},
+		{`a\nb\tc\\d`, "a\nb\tc\\d"},
+		{`\"x\" \'y\'`, `"x" 'y'`},
+		{`• \u{1F600} \xe9`, "• 😀 é"},
+		{`😀`, "😀"},
+		{`\uZZZZ \x`, `uZZZZ x`},
+		{"a\\\nb", "ab"},
+	}
+	for _, tt := range tests {
+		if got := Decode(tt.in); got != tt.want {
+			t.Errorf("Decode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestAlign 对齐两个版本的字面量，字面量之外的改动记为代码改动区间
+func TestAlign(t *testing.T) {
+	t.Run("只改字面量", func(t *testing.T) {
+		a := Align([]byte(`a("Run");b("Stop")`), []byte(`a("运行");b("Stop")`))
+		if len(a.Pairs) != 2 || a.Pairs[0].New.Raw != `"运行"` || len(a.Regions) != 0 {
+			t.Errorf("Pairs = %+v, Regions = %+v", a.Pairs, a.Regions)
+		}
+	})
+	t.Run("代码改动", func(t *testing.T) {
+		old := `a("x");b("y");c("z");d("w");e("v")`
+		edited := `a("x");B(1,"q");b("y");c("z");d("w");e("v")`
+		a := Align([]byte(old), []byte(edited))
+		var pairs []string
+		for _, p := range a.Pairs {
+			pairs = append(pairs, p.Old.Raw+"="+p.New.Raw)
+		}
+		if want := []string{`"x"="x"`, `"y"="y"`, `"z"="z"`, `"w"="w"`, `"v"="v"`}; !slices.Equal(pairs, want) {
+			t.Errorf("Pairs = %q, want %q", pairs, want)
+		}
+		if len(a.Regions) != 1 || edited[a.Regions[0].NewStart:a.Regions[0].NewEnd] == "" {
+			t.Fatalf("Regions = %+v", a.Regions)
+		}
+		if r := a.Regions[0]; old[r.OldStart:r.OldEnd] != "" || edited[r.NewStart:r.NewEnd] != `B(1,"q");` {
+			t.Errorf("改动区间 = %q → %q", old[r.OldStart:r.OldEnd], edited[r.NewStart:r.NewEnd])
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package literals
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ========================================
+// 文本相似度
+// ========================================
+
+// Similarity 返回两段界面文字的相似度 (0 到 1):
+// 按字符的编辑距离相似度与忽略大小写的单词 Jaccard 相似度中较高的一个。
+// 前者适合标点、大小写、个别字母的改动，后者适合增删或调换一两个单词
+func Similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	// 只差标点或大小写时单词相似度为 1，封顶 0.99 以区别于完全相同
+	return min(max(EditSimilarity(a, b), TokenSimilarity(a, b)), 0.99)
+}
+
+// EditSimilarity 返回 1 - 编辑距离 / 较长文本的字符数
+func EditSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(n)
+}
+
+// TokenSimilarity 返回忽略大小写的单词集合的 Jaccard 相似度 (交集 / 并集)
+func TokenSimilarity(a, b string) float64 {
+	ta, tb := tokenSet(a), tokenSet(b)
+	if len(ta) == 0 && len(tb) == 0 {
+		return 1
+	}
+	inter := 0
+	for t := range ta {
+		if tb[t] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(ta)+len(tb)-inter)
+}
+
+// LengthCompatible 判断两段文字的长度是否可能达到 min 的相似度，用于在计算编辑距离之前快速排除候选
+// (长度相差过大时编辑距离相似度一定不够；单词相似度也要求单词数相近，这里按字符数近似)
+func LengthCompatible(a, b string, min float64) bool {
+	la, lb := len([]rune(a)), len([]rune(b))
+	if la > lb {
+		la, lb = lb, la
+	}
+	return lb == 0 || float64(la)/float64(lb) >= min-0.2
+}
+
+// tokenSet 按字母和数字切分单词 (转为小写)
+func tokenSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) {
+		set[w] = true
+	}
+	return set
+}
+
+// levenshtein 按字符计算编辑距离 (两行滚动数组)
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// HasCJK 判断文本是否包含中日韩文字 (用于跳过已汉化的字面量)
+func HasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// LooksLikeText 粗略判断解码后的字面量是否像界面文字 (而不是标识符、CSS 类名、路径或代码片段):
+// 模板中的 ${...} 不计；单个单词须以大写字母开头 (如 "Accept"、"OK")；多个单词时不能含有代码符号，
+// 全小写且有单词带 "-"、":" 等符号的视为类名列表 (如 "flex items-center gap-2")
+func LooksLikeText(s string) bool {
+	s = strings.TrimSpace(templateExprPattern.ReplaceAllString(s, "0"))
+	if s == "" || !strings.ContainsFunc(s, unicode.IsLetter) || strings.ContainsAny(s, "{};=\\") {
+		return false
+	}
+	if HasCJK(s) {
+		return true
+	}
+	words := strings.Fields(s)
+	if len(words) == 1 {
+		r := []rune(s)
+		return unicode.IsUpper(r[0]) && !strings.ContainsAny(s, "/._")
+	}
+	if s != strings.ToLower(s) {
+		return true
+	}
+	for _, w := range words {
+		if strings.ContainsAny(w, "-:[]/") {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTemplate 把模板字面量中的 ${...} 表达式统一为 ${}，用于比较不同版本的 bundle
+// (压缩器每次生成的变量名不同，`Ran ${n} commands` 和 `Ran ${e} commands` 是同一段文字)
+func NormalizeTemplate(s string) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+	return templateExprPattern.ReplaceAllString(s, "${}")
+}
+
+// templateExprPattern 模板字面量中的 ${...} 表达式 (不含嵌套的花括号)
+var templateExprPattern = regexp.MustCompile(`\$\{[^{}]*\}`)
@@ -2,56 +2,23 @@ package main
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
-	"time"
-)
 
-const (
-	version       = "3.3"
-	backupDirName = "antigravity_backup"
+	"antigravity_translator/targets"
 )
 
-// 文件信息
-type FileInfo struct {
-	RelPath     string // 相对于安装目录的路径
-	Description string // 文件描述
-	Type        string // 文件类型 ("main", "chat", "continue")
-}
-
-// 备份记录
-type BackupRecord struct {
-	Timestamp   string            `json:"timestamp"`
-	InstallPath string            `json:"install_path"`
-	BackupType  string            `json:"backup_type"` // "antigravity" 或 "continue"
-	Files       map[string]string `json:"files"`       // 原始路径 -> 备份文件名
-}
-
-// 需要汉化的文件列表 - Antigravity
-var targetFilesAntigravity = []FileInfo{
-	{
-		RelPath:     `resources\app\out\jetskiAgent\main.js`,
-		Description: "设置页 (主文件)",
-		Type:        "main",
-	},
-	{
-		RelPath:     `resources\app\out\vs\workbench\workbench.desktop.main.js`,
-		Description: "设置页 (工作台)",
-		Type:        "main",
-	},
-	{
-		RelPath:     `resources\app\extensions\antigravity\out\media\chat.js`,
-		Description: "聊天页",
-		Type:        "chat",
-	},
-}
+const version = "3.3"
 
 func main() {
+	// 带参数运行时进入命令行模式
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	printBanner()
 
 	// 显示主菜单
@@ -66,6 +33,8 @@ func main() {
 			runRestore()
 		case "4":
 			showBackupList()
+		case "5":
+			showStatus()
 		case "0", "q", "Q":
 			fmt.Println("\n👋 再见！")
 			return
@@ -89,9 +58,10 @@ func showMainMenu() string {
 	fmt.Println("   2. 🔧 汉化 Continue 扩展")
 	fmt.Println("   3. ♻️  一键还原")
 	fmt.Println("   4. 📂 查看备份列表")
+	fmt.Println("   5. 📊 查看汉化状态")
 	fmt.Println("   0. 🚪 退出")
 	fmt.Println(strings.Repeat("─", 50))
-	fmt.Print("请选择 (1/2/3/4/0): ")
+	fmt.Print("请选择 (1/2/3/4/5/0): ")
 
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
@@ -108,177 +78,107 @@ func runAntigravityTranslation() {
 	fmt.Println(strings.Repeat("═", 50))
 
 	fmt.Println("\n🎯 本工具将自动汉化以下文件:")
-	for _, f := range targetFilesAntigravity {
-		fmt.Printf("   • %s\n", f.Description)
-		fmt.Printf("     %s\n", f.RelPath)
+	for _, t := range targets.InGroup("antigravity") {
+		fmt.Printf("   • %s\n", t.Description)
+		fmt.Printf("     %s\n", t.Locator)
 	}
 
-	// 自动检测 Antigravity 安装路径
-	var installPath string
-	detectedPath := findAntigravityInstallPath()
+	ctx := context.Background()
+	cfg, _ := loadConfig()
 
-	if detectedPath != "" {
+	// 自动检测 Antigravity 安装 (优先使用配置文件中记住的路径)
+	var installPaths []string
+	installs := findAntigravityInstallations(ctx)
+
+	switch {
+	case len(installs) == 1:
 		fmt.Printf("\n✓ 自动检测到 Antigravity 安装路径:\n")
-		fmt.Printf("   %s\n", detectedPath)
-		fmt.Print("\n使用此路径？(Y/n): ")
-		reader := bufio.NewReader(os.Stdin)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(strings.ToLower(input))
-		if input == "" || input == "y" || input == "yes" {
-			installPath = detectedPath
+		fmt.Printf("   %s\n", installationLabel(installs[0]))
+		if confirm("\n使用此路径？(Y/n): ", cfg.Prompts.UseDetectedPath, true) {
+			installPaths = []string{installs[0].Path}
 		}
+	case len(installs) > 1:
+		installPaths = selectInstallations(installs)
 	}
 
 	// 如果自动检测失败或用户拒绝，手动输入
-	if installPath == "" {
-		installPath = getInstallPath("Antigravity")
+	if len(installPaths) == 0 {
+		installPaths = []string{getInstallPath("Antigravity", cfg.InstallPath)}
 	}
 
-	// 验证路径
-	if !validateAntigravityPath(installPath) {
-		fmt.Println("\n❌ 无效的 Antigravity 安装路径！")
-		fmt.Println("   请确保路径中包含 resources\\app 目录")
-		waitForKeypress()
-		return
-	}
+	// 验证路径并检测文件
+	var located [][]targets.File
+	for _, installPath := range installPaths {
+		if !targets.ValidateInstallPath(installPath) {
+			fmt.Printf("\n❌ 无效的 Antigravity 安装路径: %s\n", installPath)
+			fmt.Println("   请确保路径中包含 resources\\app 目录")
+			waitForKeypress()
+			return
+		}
 
-	fmt.Printf("\n✓ 确认安装路径: %s\n", installPath)
+		fmt.Printf("\n✓ 确认安装路径: %s\n", installPath)
 
-	// 检测文件并显示状态
-	foundFiles := detectAntigravityFiles(installPath)
+		foundFiles, _ := targets.Locate(ctx, "antigravity", installPath)
+		if len(foundFiles) == 0 {
+			fmt.Println("\n❌ 未找到任何可汉化的文件！")
+			fmt.Println("   请检查 Antigravity 是否正确安装")
+			waitForKeypress()
+			return
+		}
 
-	if len(foundFiles) == 0 {
-		fmt.Println("\n❌ 未找到任何可汉化的文件！")
-		fmt.Println("   请检查 Antigravity 是否正确安装")
-		waitForKeypress()
-		return
+		fmt.Printf("\n📋 找到 %d 个可汉化的文件:\n", len(foundFiles))
+		for i, f := range foundFiles {
+			fmt.Printf("   %d. %s (%s)\n", i+1, f.Target.Description, f.Path)
+		}
+		located = append(located, foundFiles)
 	}
-
-	fmt.Printf("\n📋 找到 %d 个可汉化的文件:\n", len(foundFiles))
-	for i, f := range foundFiles {
-		fmt.Printf("   %d. %s (%s)\n", i+1, f.Description, f.RelPath)
+	if len(installPaths) == 1 {
+		rememberPath("install_path", installPaths[0])
 	}
 
 	// 询问是否继续
-	fmt.Print("\n是否开始汉化？(Y/n): ")
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
-	if input != "" && input != "y" && input != "yes" {
+	if !confirm("\n是否开始汉化？(Y/n): ", cfg.Prompts.ConfirmApply, true) {
 		fmt.Println("已取消操作")
 		return
 	}
 
-	// 创建备份目录
-	backupDir, err := createBackupDir("antigravity")
-	if err != nil {
-		fmt.Printf("\n❌ 创建备份目录失败: %v\n", err)
-		waitForKeypress()
-		return
-	}
-	fmt.Printf("\n📁 备份目录: %s\n", backupDir)
-
-	// 创建备份记录
-	record := BackupRecord{
-		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
-		InstallPath: installPath,
-		BackupType:  "antigravity",
-		Files:       make(map[string]string),
-	}
-
-	// 开始汉化
-	fmt.Println("\n" + strings.Repeat("─", 50))
-	fmt.Println("🚀 开始汉化...")
-	fmt.Println(strings.Repeat("─", 50))
-
-	successCount := 0
-	for _, f := range foundFiles {
-		fullPath := filepath.Join(installPath, f.RelPath)
-		fmt.Printf("\n📁 处理文件: %s\n", f.Description)
-		fmt.Printf("   路径: %s\n", fullPath)
-
-		// 备份文件
-		backupFileName, err := createBackup(fullPath, backupDir)
-		if err != nil {
-			fmt.Printf("   ❌ 备份失败: %v\n", err)
-			continue
-		}
-		record.Files[fullPath] = backupFileName
-		fmt.Printf("   ✓ 备份已创建: %s\n", backupFileName)
-
-		// 读取文件
-		content, err := os.ReadFile(fullPath)
-		if err != nil {
-			fmt.Printf("   ❌ 读取失败: %v\n", err)
-			continue
-		}
-		originalSize := len(content)
-		fmt.Printf("   📊 文件大小: %.2f MB\n", float64(originalSize)/1024/1024)
-
-		// 应用翻译
-		var translated string
-		var stats TranslateStats
-		if f.Type == "main" {
-			translated, stats = applyMainTranslations(string(content))
-		} else {
-			translated, stats = applyChatTranslations(string(content))
-		}
-
-		// 保存文件
-		err = os.WriteFile(fullPath, []byte(translated), 0644)
-		if err != nil {
-			fmt.Printf("   ❌ 保存失败: %v\n", err)
-			continue
-		}
-
-		sizeDiff := len(translated) - originalSize
-		diffSign := "+"
-		if sizeDiff < 0 {
-			diffSign = ""
+	// 每个安装单独备份、单独记录
+	tr := newTranslator(translatorOptions{})
+	for i, installPath := range installPaths {
+		if len(installPaths) > 1 {
+			fmt.Printf("\n🖥️  [%d/%d] %s\n", i+1, len(installPaths), installPath)
 		}
+		result := tr.Apply(ctx, "antigravity", installPath, located[i])
+		printApplyResult(result)
+	}
 
-		fmt.Printf("   ✓ 翻译完成！\n")
-		fmt.Printf("     - 普通翻译: %d 条\n", stats.NormalCount)
-		fmt.Printf("     - 模板翻译: %d 条\n", stats.TemplateCount)
-		if stats.VariableCount > 0 {
-			fmt.Printf("     - 变量翻译: %d 条\n", stats.VariableCount)
-		}
-		fmt.Printf("     - 文件大小变化: %s%d 字节\n", diffSign, sizeDiff)
+	waitForKeypress()
+}
 
-		successCount++
+// selectInstallations 检测到多个安装时让用户选择一个或多个
+func selectInstallations(installs []targets.Installation) []string {
+	fmt.Printf("\n✓ 检测到 %d 个 Antigravity 安装:\n", len(installs))
+	for i, inst := range installs {
+		fmt.Printf("   %d. %s\n", i+1, installationLabel(inst))
 	}
 
-	// 备份 product.json
-	productJsonPath := filepath.Join(installPath, "resources", "app", "product.json")
-	if _, err := os.Stat(productJsonPath); err == nil {
-		backupFileName, err := createBackup(productJsonPath, backupDir)
-		if err == nil {
-			record.Files[productJsonPath] = backupFileName
-		}
+	fmt.Printf("\n请选择要汉化的安装 (1-%d，多个用逗号分隔，a 全部，直接回车手动输入): ", len(installs))
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	if strings.TrimSpace(input) == "" {
+		return nil
 	}
 
-	// 保存备份记录
-	saveBackupRecord(backupDir, record)
-
-	// 处理 product.json 校验和
-	fmt.Println("\n" + strings.Repeat("─", 50))
-	fmt.Println("🔧 移除 product.json 校验和...")
-	removeProductJsonChecksums(installPath)
-
-	// 显示结果
-	fmt.Println("\n" + strings.Repeat("═", 50))
-	if successCount == len(foundFiles) {
-		fmt.Println("║         ✅ 全部汉化完成！                        ║")
-	} else {
-		fmt.Printf("║  ⚠️ 汉化完成 (%d/%d 成功)                         ║\n", successCount, len(foundFiles))
+	indexes := parseSelection(input, len(installs))
+	if indexes == nil {
+		fmt.Println("\n❌ 无效的选择")
+		return nil
 	}
-	fmt.Println(strings.Repeat("═", 50))
-
-	fmt.Println("\n💡 提示:")
-	fmt.Println("   1. 请完全关闭并重新打开 Antigravity 以应用汉化")
-	fmt.Println("   2. 备份已保存，可随时使用 [3] 一键还原")
-
-	waitForKeypress()
+	var paths []string
+	for _, i := range indexes {
+		paths = append(paths, installs[i].Path)
+	}
+	return paths
 }
 
 // ========================================
@@ -291,176 +191,113 @@ func runContinueTranslation() {
 	fmt.Println(strings.Repeat("═", 50))
 
 	fmt.Println("\n📍 Continue 扩展路径格式:")
-	fmt.Println("   C:\\Users\\{用户名}\\.antigravity\\extensions\\")
-	fmt.Println("   continue.continue-{版本号}-win32-x64\\gui\\assets\\index.js")
+	fmt.Println("   {用户目录}\\.antigravity (.vscode、.cursor、.windsurf ...)\\extensions\\")
+	fmt.Println("   continue.continue-{版本号}[-{平台}]\\gui\\assets\\*.js")
 
-	// 自动查找 Continue 扩展
-	continueDir, indexPath := findContinueExtension()
+	ctx := context.Background()
+	cfg, _ := loadConfig()
 
-	if indexPath != "" {
-		fmt.Printf("\n✓ 自动检测到 Continue 扩展:\n")
-		fmt.Printf("   目录: %s\n", filepath.Base(continueDir))
-		fmt.Printf("   文件: %s\n", indexPath)
+	// 自动查找各编辑器中的 Continue 扩展 (优先使用配置文件中记住的目录)
+	var continueDirs []string
+	exts := findContinueExtensions(ctx)
 
-		fmt.Print("\n使用检测到的路径？(Y/n): ")
-		reader := bufio.NewReader(os.Stdin)
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(strings.ToLower(input))
-		if input != "" && input != "y" && input != "yes" {
-			indexPath = ""
+	switch {
+	case len(exts) == 1:
+		fmt.Printf("\n✓ 自动检测到 Continue 扩展:\n")
+		fmt.Printf("   目录: %s\n", continueExtensionLabel(exts[0]))
+		for _, w := range obsoleteContinueWarnings(exts[0]) {
+			fmt.Printf("   ⚠️ %s\n", w.Message)
+		}
+		if confirm("\n使用检测到的路径？(Y/n): ", cfg.Prompts.UseDetectedPath, true) {
+			continueDirs = []string{exts[0].Path}
 		}
+	case len(exts) > 1:
+		continueDirs = selectContinueExtensions(exts)
 	}
 
-	if indexPath == "" {
-		fmt.Print("\n请输入 index.js 的完整路径: ")
+	if len(continueDirs) == 0 {
+		fmt.Print("\n请输入 Continue 扩展目录 (或其中 index.js 的完整路径): ")
 		reader := bufio.NewReader(os.Stdin)
-		indexPath, _ = reader.ReadString('\n')
-		indexPath = strings.TrimSpace(indexPath)
-		indexPath = strings.Trim(indexPath, "\"'")
-	}
-
-	// 验证文件存在
-	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
-		fmt.Printf("\n❌ 文件不存在: %s\n", indexPath)
-		waitForKeypress()
-		return
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		continueDirs = []string{targets.ContinueRoot(strings.Trim(input, "\"'"))}
 	}
 
-	fmt.Printf("\n✓ 确认文件路径: %s\n", indexPath)
-
-	// 询问是否继续
-	fmt.Print("\n是否开始汉化？(Y/n): ")
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
-	if input != "" && input != "y" && input != "yes" {
-		fmt.Println("已取消操作")
-		return
-	}
+	// 验证目录并定位包含界面文本的资源文件 (index.js 及 Vite 拆分的分块)
+	var located [][]targets.File
+	for _, continueDir := range continueDirs {
+		if _, err := os.Stat(continueDir); os.IsNotExist(err) {
+			fmt.Printf("\n❌ 目录不存在: %s\n", continueDir)
+			waitForKeypress()
+			return
+		}
 
-	// 创建备份目录
-	backupDir, err := createBackupDir("continue")
-	if err != nil {
-		fmt.Printf("\n❌ 创建备份目录失败: %v\n", err)
-		waitForKeypress()
-		return
-	}
-	fmt.Printf("\n📁 备份目录: %s\n", backupDir)
+		fmt.Printf("\n✓ 确认扩展目录: %s\n", continueDir)
 
-	// 创建备份记录
-	record := BackupRecord{
-		Timestamp:   time.Now().Format("2006-01-02 15:04:05"),
-		InstallPath: filepath.Dir(filepath.Dir(filepath.Dir(indexPath))), // 保存扩展根目录
-		BackupType:  "continue",
-		Files:       make(map[string]string),
-	}
+		files, _ := targets.Locate(ctx, "continue", continueDir)
+		if len(files) == 0 {
+			fmt.Println("\n❌ 未找到任何可汉化的文件！")
+			fmt.Println("   gui/assets 下没有包含 Continue 界面文本的 JS 文件")
+			waitForKeypress()
+			return
+		}
 
-	// 备份文件
-	backupFileName, err := createBackup(indexPath, backupDir)
-	if err != nil {
-		fmt.Printf("\n❌ 备份失败: %v\n", err)
-		waitForKeypress()
-		return
+		fmt.Printf("\n📋 找到 %d 个可汉化的文件:\n", len(files))
+		for i, f := range files {
+			fmt.Printf("   %d. %s\n", i+1, filepath.Base(f.Path))
+		}
+		located = append(located, files)
 	}
-	record.Files[indexPath] = backupFileName
-	fmt.Printf("   ✓ 备份已创建: %s\n", backupFileName)
-
-	// 开始汉化
-	fmt.Println("\n" + strings.Repeat("─", 50))
-	fmt.Println("🚀 开始汉化...")
-	fmt.Println(strings.Repeat("─", 50))
-
-	// 读取文件
-	content, err := os.ReadFile(indexPath)
-	if err != nil {
-		fmt.Printf("\n❌ 读取失败: %v\n", err)
-		waitForKeypress()
-		return
+	if len(continueDirs) == 1 {
+		rememberPath("continue_dir", continueDirs[0])
 	}
-	originalSize := len(content)
-	fmt.Printf("   📊 文件大小: %.2f MB\n", float64(originalSize)/1024/1024)
 
-	// 应用翻译
-	translated, stats := applyContinueTranslations(string(content))
-
-	// 保存文件
-	err = os.WriteFile(indexPath, []byte(translated), 0644)
-	if err != nil {
-		fmt.Printf("\n❌ 保存失败: %v\n", err)
-		waitForKeypress()
+	// 询问是否继续
+	if !confirm("\n是否开始汉化？(Y/n): ", cfg.Prompts.ConfirmApply, true) {
+		fmt.Println("已取消操作")
 		return
 	}
 
-	// 保存备份记录
-	saveBackupRecord(backupDir, record)
-
-	sizeDiff := len(translated) - originalSize
-	diffSign := "+"
-	if sizeDiff < 0 {
-		diffSign = ""
+	// 每个扩展单独备份、单独记录
+	tr := newTranslator(translatorOptions{})
+	for i, continueDir := range continueDirs {
+		if len(continueDirs) > 1 {
+			fmt.Printf("\n🧩 [%d/%d] %s\n", i+1, len(continueDirs), continueDir)
+		}
+		result := tr.Apply(ctx, "continue", continueDir, located[i])
+		printApplyResult(result)
 	}
 
-	fmt.Printf("\n   ✓ 翻译完成！\n")
-	fmt.Printf("     - 引号翻译: %d 条\n", stats.NormalCount)
-	fmt.Printf("     - 全局替换: %d 条\n", stats.TemplateCount)
-	fmt.Printf("     - 文件大小变化: %s%d 字节\n", diffSign, sizeDiff)
-
-	// 显示结果
-	fmt.Println("\n" + strings.Repeat("═", 50))
-	fmt.Println("║         ✅ Continue 扩展汉化完成！               ║")
-	fmt.Println(strings.Repeat("═", 50))
-
-	fmt.Println("\n💡 提示:")
-	fmt.Println("   1. 请完全关闭并重新打开 Antigravity 以应用汉化")
-	fmt.Println("   2. 备份已保存，可随时使用 [3] 一键还原")
-
 	waitForKeypress()
 }
 
-// findContinueExtension 自动查找 Continue 扩展
-func findContinueExtension() (string, string) {
-	// 获取用户目录
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", ""
-	}
-
-	// 查找扩展目录
-	extensionsDir := filepath.Join(homeDir, ".antigravity", "extensions")
-	if _, err := os.Stat(extensionsDir); os.IsNotExist(err) {
-		return "", ""
-	}
-
-	// 查找 continue.continue-* 目录
-	entries, err := os.ReadDir(extensionsDir)
-	if err != nil {
-		return "", ""
-	}
-
-	var latestVersion string
-	var latestDir string
-
-	for _, entry := range entries {
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), "continue.continue-") {
-			// 选择最新版本（按字符串排序）
-			if entry.Name() > latestVersion {
-				latestVersion = entry.Name()
-				latestDir = filepath.Join(extensionsDir, entry.Name())
-			}
+// selectContinueExtensions 检测到多个 Continue 扩展时让用户选择一个或多个
+func selectContinueExtensions(exts []*targets.ContinueExtension) []string {
+	fmt.Printf("\n✓ 检测到 %d 个 Continue 扩展:\n", len(exts))
+	for i, ext := range exts {
+		fmt.Printf("   %d. %s\n", i+1, continueExtensionLabel(ext))
+		for _, w := range obsoleteContinueWarnings(ext) {
+			fmt.Printf("      ⚠️ %s\n", w.Message)
 		}
 	}
 
-	if latestDir == "" {
-		return "", ""
+	fmt.Printf("\n请选择要汉化的扩展 (1-%d，多个用逗号分隔，a 全部，直接回车手动输入): ", len(exts))
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	if strings.TrimSpace(input) == "" {
+		return nil
 	}
 
-	// 检查 index.js 是否存在
-	indexPath := filepath.Join(latestDir, "gui", "assets", "index.js")
-	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
-		return latestDir, ""
+	indexes := parseSelection(input, len(exts))
+	if indexes == nil {
+		fmt.Println("\n❌ 无效的选择")
+		return nil
 	}
-
-	return latestDir, indexPath
+	var dirs []string
+	for _, i := range indexes {
+		dirs = append(dirs, exts[i].Path)
+	}
+	return dirs
 }
 
 // ========================================
@@ -472,46 +309,32 @@ func runRestore() {
 	fmt.Println("♻️  一键还原模式")
 	fmt.Println(strings.Repeat("═", 50))
 
-	// 获取备份目录
-	programDir, err := os.Executable()
-	if err != nil {
-		fmt.Printf("\n❌ 获取程序目录失败: %v\n", err)
-		waitForKeypress()
-		return
-	}
-	programDir = filepath.Dir(programDir)
-	backupBaseDir := filepath.Join(programDir, backupDirName)
-
-	// 检查备份目录是否存在
-	if _, err := os.Stat(backupBaseDir); os.IsNotExist(err) {
-		fmt.Println("\n❌ 未找到任何备份！")
-		fmt.Println("   备份目录不存在: " + backupBaseDir)
+	// 列出所有备份
+	ctx := context.Background()
+	tr := newTranslator(translatorOptions{})
+	list, backups := tr.List(ctx)
+	if len(list.Errors) > 0 {
+		printProblems(list.Warnings, list.Errors)
 		waitForKeypress()
 		return
 	}
-
-	// 列出所有备份
-	backups, err := listBackups(backupBaseDir)
-	if err != nil || len(backups) == 0 {
+	if len(backups) == 0 {
 		fmt.Println("\n❌ 未找到任何备份记录！")
+		fmt.Println("   备份目录: " + list.BackupRoot)
 		waitForKeypress()
 		return
 	}
 
 	fmt.Printf("\n📂 找到 %d 个备份:\n\n", len(backups))
 	for i, b := range backups {
-		backupTypeLabel := "Antigravity"
-		if b.record.BackupType == "continue" {
-			backupTypeLabel = "Continue 扩展"
-		}
-		fmt.Printf("   %d. [%s] %s\n", i+1, backupTypeLabel, b.dirName)
-		fmt.Printf("      时间: %s\n", b.record.Timestamp)
-		fmt.Printf("      路径: %s\n", b.record.InstallPath)
-		fmt.Printf("      文件: %d 个\n\n", len(b.record.Files))
+		fmt.Printf("   %d. [%s] %s\n", i+1, backupTypeLabel(b.Record.BackupType), b.ID)
+		fmt.Printf("      时间: %s\n", b.Record.Timestamp)
+		fmt.Printf("      路径: %s\n", b.Record.InstallPath)
+		fmt.Printf("      文件: %d 个\n\n", len(b.Record.Files))
 	}
 
-	// 选择要还原的备份
-	fmt.Printf("请选择要还原的备份 (1-%d，0 取消): ", len(backups))
+	// 选择要还原的备份 (多个安装时可一次选择多个备份)
+	fmt.Printf("请选择要还原的备份 (1-%d，多个用逗号分隔，0 取消): ", len(backups))
 	reader := bufio.NewReader(os.Stdin)
 	input, _ := reader.ReadString('\n')
 	input = strings.TrimSpace(input)
@@ -521,74 +344,32 @@ func runRestore() {
 		return
 	}
 
-	var choice int
-	fmt.Sscanf(input, "%d", &choice)
-	if choice < 1 || choice > len(backups) {
+	// 同一安装的新旧备份不能同时还原，因此不支持 "a" 全选
+	indexes := parseSelection(input, len(backups))
+	if indexes == nil || strings.EqualFold(input, "a") || strings.EqualFold(input, "all") {
 		fmt.Println("\n❌ 无效的选择")
 		waitForKeypress()
 		return
 	}
 
-	selectedBackup := backups[choice-1]
-
 	// 确认还原
-	fmt.Printf("\n⚠️  即将还原备份: %s\n", selectedBackup.dirName)
-	fmt.Printf("   目标路径: %s\n", selectedBackup.record.InstallPath)
-	fmt.Printf("   将还原 %d 个文件\n", len(selectedBackup.record.Files))
-	fmt.Print("\n确认还原？(y/N): ")
-	input, _ = reader.ReadString('\n')
-	input = strings.TrimSpace(strings.ToLower(input))
-	if input != "y" && input != "yes" {
+	for _, i := range indexes {
+		b := backups[i]
+		fmt.Printf("\n⚠️  即将还原备份: %s\n", b.ID)
+		fmt.Printf("   目标路径: %s\n", b.Record.InstallPath)
+		fmt.Printf("   将还原 %d 个文件\n", len(b.Record.Files))
+	}
+	cfg, _ := loadConfig()
+	if !confirm("\n确认还原？(y/N): ", cfg.Prompts.ConfirmRestore, false) {
 		fmt.Println("已取消操作")
 		return
 	}
 
 	// 执行还原
-	fmt.Println("\n" + strings.Repeat("─", 50))
-	fmt.Println("🔄 开始还原...")
-	fmt.Println(strings.Repeat("─", 50))
-
-	successCount := 0
-	for originalPath, backupFileName := range selectedBackup.record.Files {
-		backupFilePath := filepath.Join(selectedBackup.fullPath, backupFileName)
-
-		fmt.Printf("\n📁 还原文件: %s\n", filepath.Base(originalPath))
-
-		// 检查备份文件是否存在
-		if _, err := os.Stat(backupFilePath); os.IsNotExist(err) {
-			fmt.Printf("   ❌ 备份文件不存在: %s\n", backupFileName)
-			continue
-		}
-
-		// 读取备份文件
-		content, err := os.ReadFile(backupFilePath)
-		if err != nil {
-			fmt.Printf("   ❌ 读取备份失败: %v\n", err)
-			continue
-		}
-
-		// 写入原始位置
-		err = os.WriteFile(originalPath, content, 0644)
-		if err != nil {
-			fmt.Printf("   ❌ 还原失败: %v\n", err)
-			continue
-		}
-
-		fmt.Printf("   ✓ 已还原: %s\n", originalPath)
-		successCount++
-	}
-
-	// 显示结果
-	fmt.Println("\n" + strings.Repeat("═", 50))
-	if successCount == len(selectedBackup.record.Files) {
-		fmt.Println("║         ✅ 全部还原完成！                        ║")
-	} else {
-		fmt.Printf("║  ⚠️ 还原完成 (%d/%d 成功)                         ║\n", successCount, len(selectedBackup.record.Files))
+	for _, i := range indexes {
+		result := tr.Restore(ctx, backups[i])
+		printRestoreResult(result)
 	}
-	fmt.Println(strings.Repeat("═", 50))
-
-	fmt.Println("\n💡 提示:")
-	fmt.Println("   请完全关闭并重新打开 Antigravity 以应用还原")
 
 	waitForKeypress()
 }
@@ -602,48 +383,31 @@ func showBackupList() {
 	fmt.Println("📂 备份列表")
 	fmt.Println(strings.Repeat("═", 50))
 
-	// 获取备份目录
-	programDir, err := os.Executable()
-	if err != nil {
-		fmt.Printf("\n❌ 获取程序目录失败: %v\n", err)
-		waitForKeypress()
-		return
-	}
-	programDir = filepath.Dir(programDir)
-	backupBaseDir := filepath.Join(programDir, backupDirName)
+	list, _ := newTranslator(translatorOptions{}).List(context.Background())
+	printListResult(list)
 
-	fmt.Printf("\n📁 备份目录: %s\n", backupBaseDir)
+	waitForKeypress()
+}
 
-	// 检查备份目录是否存在
-	if _, err := os.Stat(backupBaseDir); os.IsNotExist(err) {
-		fmt.Println("\n📭 暂无备份")
-		waitForKeypress()
-		return
-	}
+// ========================================
+// 汉化状态
+// ========================================
 
-	// 列出所有备份
-	backups, err := listBackups(backupBaseDir)
-	if err != nil || len(backups) == 0 {
-		fmt.Println("\n📭 暂无备份记录")
+func showStatus() {
+	ctx := context.Background()
+	installPath := findAntigravityInstallPath(ctx)
+	continueDir := ""
+	if ext := findContinueExtension(ctx); ext != nil {
+		continueDir = ext.Path
+	}
+	if installPath == "" && continueDir == "" {
+		fmt.Println("\n❌ 未检测到 Antigravity 或 Continue 扩展")
 		waitForKeypress()
 		return
 	}
 
-	fmt.Printf("\n找到 %d 个备份:\n\n", len(backups))
-	for i, b := range backups {
-		backupTypeLabel := "Antigravity"
-		if b.record.BackupType == "continue" {
-			backupTypeLabel = "Continue"
-		}
-		fmt.Printf("   %d. 📦 [%s] %s\n", i+1, backupTypeLabel, b.dirName)
-		fmt.Printf("      创建时间: %s\n", b.record.Timestamp)
-		fmt.Printf("      安装路径: %s\n", b.record.InstallPath)
-		fmt.Printf("      备份文件:\n")
-		for origPath, backupName := range b.record.Files {
-			fmt.Printf("         • %s -> %s\n", filepath.Base(origPath), backupName)
-		}
-		fmt.Println()
-	}
+	result := newTranslator(translatorOptions{}).Status(ctx, installPath, continueDir)
+	printStatusResult(result)
 
 	waitForKeypress()
 }
@@ -652,328 +416,38 @@ func showBackupList() {
 // 辅助函数
 // ========================================
 
-type backupInfo struct {
-	dirName  string
-	fullPath string
-	record   BackupRecord
-}
-
-func listBackups(backupBaseDir string) ([]backupInfo, error) {
-	var backups []backupInfo
-
-	entries, err := os.ReadDir(backupBaseDir)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			continue
-		}
-
-		dirPath := filepath.Join(backupBaseDir, entry.Name())
-		recordPath := filepath.Join(dirPath, "backup_record.json")
-
-		// 读取备份记录
-		if _, err := os.Stat(recordPath); os.IsNotExist(err) {
-			continue
-		}
-
-		content, err := os.ReadFile(recordPath)
-		if err != nil {
-			continue
-		}
-
-		var record BackupRecord
-		if err := json.Unmarshal(content, &record); err != nil {
-			continue
-		}
-
-		backups = append(backups, backupInfo{
-			dirName:  entry.Name(),
-			fullPath: dirPath,
-			record:   record,
-		})
-	}
-
-	// 按时间倒序排列
-	sort.Slice(backups, func(i, j int) bool {
-		return backups[i].dirName > backups[j].dirName
-	})
-
-	return backups, nil
-}
-
-func getInstallPath(appName string) string {
+// getInstallPath 提示用户输入安装路径，直接回车时使用配置文件中记住的路径
+func getInstallPath(appName string, remembered string) string {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\n请输入 %s 安装路径: ", appName)
+	if remembered != "" {
+		fmt.Printf("\n请输入 %s 安装路径 [%s]: ", appName, remembered)
+	} else {
+		fmt.Printf("\n请输入 %s 安装路径: ", appName)
+	}
 	path, _ := reader.ReadString('\n')
 	path = strings.TrimSpace(path)
 	// 去掉可能的引号
 	path = strings.Trim(path, "\"'")
-	return path
-}
-
-func validateAntigravityPath(path string) bool {
-	// 检查路径是否存在
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return false
-	}
-
-	// 检查是否包含 resources/app 目录
-	resourcesPath := filepath.Join(path, "resources", "app")
-	if _, err := os.Stat(resourcesPath); os.IsNotExist(err) {
-		return false
+	if path == "" {
+		return remembered
 	}
-
-	return true
-}
-
-// findAntigravityInstallPath 自动检测 Antigravity 安装路径
-func findAntigravityInstallPath() string {
-	// 1. 优先从注册表查询
-	registryPath := findAntigravityFromRegistry()
-	if registryPath != "" && validateAntigravityPath(registryPath) {
-		return registryPath
-	}
-
-	// 2. 获取用户目录
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
-
-	// 3. 检查常见安装位置
-	candidates := []string{
-		// 用户目录安装 (最常见)
-		filepath.Join(homeDir, "AppData", "Local", "Programs", "Antigravity"),
-		filepath.Join(homeDir, "AppData", "Local", "Antigravity"),
-		// 系统目录安装
-		"C:\\Program Files\\Antigravity",
-		"C:\\Program Files (x86)\\Antigravity",
-		// 其他常见位置
-		"D:\\Antigravity",
-		"D:\\Program Files\\Antigravity",
-		"E:\\Antigravity",
-	}
-
-	for _, path := range candidates {
-		if validateAntigravityPath(path) {
-			return path
-		}
-	}
-
-	return ""
-}
-
-// findAntigravityFromRegistry 从 Windows 注册表查询 Antigravity 安装路径
-func findAntigravityFromRegistry() string {
-	// 注册表查询位置
-	registryPaths := []string{
-		// 用户安装的程序
-		`HKCU\Software\Microsoft\Windows\CurrentVersion\Uninstall`,
-		// 系统安装的程序 (64位)
-		`HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`,
-		// 系统安装的程序 (32位 on 64位系统)
-		`HKLM\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`,
-	}
-
-	// 收集所有有效路径
-	var validPaths []string
-
-	for _, regPath := range registryPaths {
-		// 使用 reg query 命令查询注册表
-		cmd := exec.Command("reg", "query", regPath, "/s", "/f", "Antigravity", "/d")
-		output, err := cmd.Output()
-		if err != nil {
-			continue
-		}
-
-		// 解析输出
-		lines := strings.Split(string(output), "\n")
-
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-
-			// 查找 InstallLocation
-			if strings.Contains(line, "InstallLocation") && strings.Contains(line, "REG_SZ") {
-				parts := strings.SplitN(line, "REG_SZ", 2)
-				if len(parts) == 2 {
-					path := cleanRegistryPath(parts[1])
-					if path != "" && validateAntigravityPath(path) {
-						validPaths = append(validPaths, path)
-					}
-				}
-			}
-
-			// 查找 DisplayIcon (通常指向 exe 文件)
-			if strings.Contains(line, "DisplayIcon") && strings.Contains(line, "REG_SZ") {
-				parts := strings.SplitN(line, "REG_SZ", 2)
-				if len(parts) == 2 {
-					iconPath := cleanRegistryPath(parts[1])
-					// 移除可能的逗号和图标索引
-					if idx := strings.Index(iconPath, ","); idx > 0 {
-						iconPath = iconPath[:idx]
-					}
-					// 获取目录路径
-					dir := filepath.Dir(iconPath)
-					if dir != "" && validateAntigravityPath(dir) {
-						validPaths = append(validPaths, dir)
-					}
-				}
-			}
-		}
-	}
-
-	// 从有效路径中选择最佳匹配
-	// 优先选择路径最短的（通常是主程序而不是子工具）
-	if len(validPaths) == 0 {
-		return ""
-	}
-
-	bestPath := validPaths[0]
-	for _, p := range validPaths[1:] {
-		if len(p) < len(bestPath) {
-			bestPath = p
-		}
-	}
-
-	return bestPath
-}
-
-// cleanRegistryPath 清理注册表返回的路径
-func cleanRegistryPath(path string) string {
-	path = strings.TrimSpace(path)
-	// 移除引号
-	path = strings.Trim(path, "\"")
-	// 移除尾部反斜杠
-	path = strings.TrimSuffix(path, "\\")
 	return path
 }
 
-func detectAntigravityFiles(installPath string) []FileInfo {
-	var found []FileInfo
-	for _, f := range targetFilesAntigravity {
-		fullPath := filepath.Join(installPath, f.RelPath)
-		if _, err := os.Stat(fullPath); err == nil {
-			found = append(found, f)
-		}
-	}
-	return found
-}
-
-func createBackupDir(backupType string) (string, error) {
-	// 获取程序运行目录
-	programDir, err := os.Executable()
-	if err != nil {
-		return "", err
-	}
-	programDir = filepath.Dir(programDir)
-
-	// 创建备份根目录
-	backupBaseDir := filepath.Join(programDir, backupDirName)
-	if err := os.MkdirAll(backupBaseDir, 0755); err != nil {
-		return "", err
-	}
-
-	// 创建以时间和类型命名的子目录
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	backupDir := filepath.Join(backupBaseDir, fmt.Sprintf("%s_%s", timestamp, backupType))
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		return "", err
-	}
-
-	return backupDir, nil
-}
-
-func createBackup(filePath string, backupDir string) (string, error) {
-	// 使用原始文件名
-	fileName := filepath.Base(filePath)
-
-	backupPath := filepath.Join(backupDir, fileName)
-
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return "", err
-	}
-
-	err = os.WriteFile(backupPath, content, 0644)
-	if err != nil {
-		return "", err
-	}
-
-	return fileName, nil
-}
-
-func saveBackupRecord(backupDir string, record BackupRecord) {
-	recordPath := filepath.Join(backupDir, "backup_record.json")
-	data, err := json.MarshalIndent(record, "", "  ")
-	if err != nil {
-		fmt.Printf("   ⚠️ 保存备份记录失败: %v\n", err)
-		return
-	}
-	if err := os.WriteFile(recordPath, data, 0644); err != nil {
-		fmt.Printf("   ⚠️ 保存备份记录失败: %v\n", err)
-	}
-}
-
-func removeProductJsonChecksums(installPath string) {
-	productJsonPath := filepath.Join(installPath, "resources", "app", "product.json")
-
-	if _, err := os.Stat(productJsonPath); os.IsNotExist(err) {
-		fmt.Println("   ⚠️ 未找到 product.json，跳过")
-		return
-	}
-
-	content, err := os.ReadFile(productJsonPath)
-	if err != nil {
-		fmt.Printf("   ❌ 读取 product.json 失败: %v\n", err)
-		return
+// confirm 询问是/否，preset 为配置文件中的默认回答 ("yes"/"no" 时不再询问)
+func confirm(question, preset string, defaultYes bool) bool {
+	if preset == "yes" || preset == "no" {
+		fmt.Printf("%s%s (配置默认)\n", question, preset)
+		return preset == "yes"
 	}
-
-	originalContent := string(content)
-	lines := strings.Split(originalContent, "\n")
-
-	// 需要删除的校验和关键词
-	checksumKeys := []string{
-		`"jetskiAgent/main.js"`,
-		`"vs/workbench/workbench.desktop.main.js"`,
-	}
-
-	removedCount := 0
-	var newLines []string
-
-	for _, line := range lines {
-		skip := false
-		for _, key := range checksumKeys {
-			if strings.Contains(line, key) {
-				removedCount++
-				fmt.Printf("   ✓ 移除校验和: %s\n", key)
-				skip = true
-				break
-			}
-		}
-		if !skip {
-			newLines = append(newLines, line)
-		}
-	}
-
-	if removedCount > 0 {
-		// 保存修改后的内容
-		newContent := strings.Join(newLines, "\n")
-		// 修复尾随逗号问题
-		newContent = strings.ReplaceAll(newContent, ",\n}", "\n}")
-		newContent = strings.ReplaceAll(newContent, ",\n]", "\n]")
-
-		err = os.WriteFile(productJsonPath, []byte(newContent), 0644)
-		if err != nil {
-			fmt.Printf("   ❌ 保存 product.json 失败: %v\n", err)
-		} else {
-			fmt.Printf("   ✓ product.json 已更新 (移除 %d 个校验和)\n", removedCount)
-		}
-	} else {
-		fmt.Println("   ✓ 校验和已移除过，无需重复处理")
+	fmt.Print(question)
+	reader := bufio.NewReader(os.Stdin)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.ToLower(input))
+	if input == "" {
+		return defaultYes
 	}
+	return input == "y" || input == "yes"
 }
 
 func waitForKeypress() {
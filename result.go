@@ -0,0 +1,747 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"antigravity_translator/targets"
+	"antigravity_translator/translator"
+)
+
+// BatchResult 对多个目标或多个安装批量执行时的结果
+type BatchResult struct {
+	Operation string        `json:"operation"`
+	Results   []interface{} `json:"results"`
+}
+
+// InstallsResult 检测到的 Antigravity 安装和 Continue 扩展列表
+type InstallsResult struct {
+	Operation          string                       `json:"operation"`
+	Installations      []targets.Installation       `json:"installations"`
+	ContinueExtensions []*targets.ContinueExtension `json:"continue_extensions"`
+	Warnings           []translator.Problem         `json:"warnings"`
+	Errors             []translator.Problem         `json:"errors"`
+}
+
+// BenchFile 单个文件的基准测试结果
+type BenchFile struct {
+	Path       string  `json:"path,omitempty"`
+	Target     string  `json:"target"`
+	Synthetic  bool    `json:"synthetic"` // 是否为合成数据
+	Size       int     `json:"size"`
+	LegacyMs   float64 `json:"legacy_ms"` // 逐条 strings.ReplaceAll 的耗时
+	EngineMs   float64 `json:"engine_ms"` // 单次扫描引擎的耗时
+	Speedup    float64 `json:"speedup"`
+	LegacyHits int     `json:"legacy_hits"`
+	EngineHits int     `json:"engine_hits"`
+	Identical  bool    `json:"identical"` // 两种实现的输出是否一致
+}
+
+// BenchResult 基准测试结果
+type BenchResult struct {
+	Operation  string               `json:"operation"`
+	Iterations int                  `json:"iterations"`
+	Files      []BenchFile          `json:"files"`
+	Warnings   []translator.Problem `json:"warnings"`
+	Errors     []translator.Problem `json:"errors"`
+}
+
+// ConfigResult 配置查看/修改结果
+type ConfigResult struct {
+	Operation string               `json:"operation"`
+	Path      string               `json:"path"`
+	Values    map[string]string    `json:"values"`
+	Warnings  []translator.Problem `json:"warnings"`
+	Errors    []translator.Problem `json:"errors"`
+}
+
+// ========================================
+// 输出渲染
+// ========================================
+
+// printJSON 以 JSON 格式输出结果
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
+
+func printProblems(warnings, errors []translator.Problem) {
+	for _, w := range warnings {
+		fmt.Printf("   ⚠️ %s\n", w.Message)
+	}
+	for _, e := range errors {
+		fmt.Printf("   ❌ %s\n", e.Message)
+	}
+}
+
+// printApplyResult 在控制台输出汉化结果
+func printApplyResult(r *translator.ApplyResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+	if r.BackupDir != "" {
+		fmt.Printf("\n📁 备份目录: %s\n", r.BackupDir)
+	}
+
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Println("🚀 开始汉化...")
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, f := range r.Files {
+		fmt.Printf("\n📁 处理文件: %s\n", f.Description)
+		fmt.Printf("   路径: %s\n", f.Path)
+		if f.Backup != "" {
+			fmt.Printf("   ✓ 备份已创建: %s\n", f.Backup)
+		}
+		if f.SizeBefore > 0 {
+			fmt.Printf("   📊 文件大小: %.2f MB\n", float64(f.SizeBefore)/1024/1024)
+		}
+		if f.Error != nil {
+			fmt.Printf("   ❌ %s\n", f.Error.Message)
+			continue
+		}
+		if !f.Written {
+			fmt.Printf("   ⏭️  未写入 (其他文件失败，已取消本次汉化)\n")
+			continue
+		}
+
+		sizeDiff := f.SizeAfter - f.SizeBefore
+		diffSign := "+"
+		if sizeDiff < 0 {
+			diffSign = ""
+		}
+
+		fmt.Printf("   ✓ 翻译完成！\n")
+		if f.Group == "continue" {
+			fmt.Printf("     - 引号翻译: %d 条\n", f.Stats.NormalCount)
+			fmt.Printf("     - 全局替换: %d 条\n", f.Stats.TemplateCount)
+		} else {
+			fmt.Printf("     - 普通翻译: %d 条\n", f.Stats.NormalCount)
+			fmt.Printf("     - 模板翻译: %d 条\n", f.Stats.TemplateCount)
+			if f.Stats.VariableCount > 0 {
+				fmt.Printf("     - 变量翻译: %d 条\n", f.Stats.VariableCount)
+			}
+		}
+		if f.Stats.RegexCount > 0 {
+			fmt.Printf("     - 正则翻译: %d 条\n", f.Stats.RegexCount)
+		}
+		fmt.Printf("     - 文件大小变化: %s%d 字节\n", diffSign, sizeDiff)
+	}
+
+	if r.Target == "antigravity" && r.Succeeded() > 0 {
+		fmt.Println("\n" + strings.Repeat("─", 50))
+		fmt.Println("🔧 移除 product.json 校验和...")
+		removed := 0
+		for _, c := range r.Checksums {
+			if c.Action == "removed" {
+				fmt.Printf("   ✓ 移除校验和: %s\n", c.Key)
+				removed++
+			}
+		}
+		if removed > 0 {
+			fmt.Printf("   ✓ product.json 已更新 (移除 %d 个校验和)\n", removed)
+		} else if len(r.Checksums) > 0 {
+			fmt.Println("   ✓ 校验和已移除过，无需重复处理")
+		}
+	}
+
+	printProblems(r.Warnings, r.Errors)
+
+	fmt.Println("\n" + strings.Repeat("═", 50))
+	switch {
+	case r.Succeeded() == 0:
+		fmt.Println("║         ❌ 汉化失败                              ║")
+	case r.Succeeded() == len(r.Files) && r.Target == "continue":
+		fmt.Println("║         ✅ Continue 扩展汉化完成！               ║")
+	case r.Succeeded() == len(r.Files):
+		fmt.Println("║         ✅ 全部汉化完成！                        ║")
+	default:
+		fmt.Printf("║  ⚠️ 汉化完成 (%d/%d 成功)                         ║\n", r.Succeeded(), len(r.Files))
+	}
+	fmt.Println(strings.Repeat("═", 50))
+
+	if r.Succeeded() > 0 {
+		fmt.Println("\n💡 提示:")
+		fmt.Println("   1. 请完全关闭并重新打开 Antigravity 以应用汉化")
+		fmt.Println("   2. 备份已保存，可随时使用 [3] 一键还原")
+	}
+}
+
+// printRestoreResult 在控制台输出还原结果
+func printRestoreResult(r *translator.RestoreResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 目标路径: %s (备份 %s)\n", r.InstallPath, r.BackupID)
+	}
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Println("🔄 开始还原...")
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, f := range r.Files {
+		fmt.Printf("\n📁 还原文件: %s\n", filepath.Base(f.Path))
+		if f.Error != nil {
+			fmt.Printf("   ❌ %s\n", f.Error.Message)
+			continue
+		}
+		fmt.Printf("   ✓ 已还原: %s\n", f.Path)
+	}
+
+	printProblems(r.Warnings, r.Errors)
+
+	fmt.Println("\n" + strings.Repeat("═", 50))
+	if len(r.Files) > 0 && r.Succeeded() == len(r.Files) {
+		fmt.Println("║         ✅ 全部还原完成！                        ║")
+	} else {
+		fmt.Printf("║  ⚠️ 还原完成 (%d/%d 成功)                         ║\n", r.Succeeded(), len(r.Files))
+	}
+	fmt.Println(strings.Repeat("═", 50))
+
+	fmt.Println("\n💡 提示:")
+	fmt.Println("   请完全关闭并重新打开 Antigravity 以应用还原")
+}
+
+// printListResult 在控制台输出备份列表
+func printListResult(r *translator.ListResult) {
+	fmt.Printf("\n📁 备份目录: %s\n", r.BackupRoot)
+	printProblems(r.Warnings, r.Errors)
+
+	if len(r.Backups) == 0 {
+		fmt.Println("\n📭 暂无备份记录")
+		return
+	}
+
+	fmt.Printf("\n找到 %d 个备份:\n\n", len(r.Backups))
+	for i, b := range r.Backups {
+		fmt.Printf("   %d. 📦 [%s] %s\n", i+1, backupTypeLabel(b.BackupType), b.ID)
+		fmt.Printf("      创建时间: %s\n", b.Timestamp)
+		fmt.Printf("      安装路径: %s\n", b.InstallPath)
+		fmt.Printf("      备份文件:\n")
+		for origPath, backupName := range b.Files {
+			fmt.Printf("         • %s -> %s\n", filepath.Base(origPath), backupName)
+		}
+		if b.Bilingual != nil && b.Bilingual.Style != "off" {
+			fmt.Printf("      双语显示: %s\n", b.Bilingual.Style)
+		}
+		if len(b.Reverted) > 0 {
+			fmt.Printf("      已撤销的规则:\n")
+			for _, r := range b.Reverted {
+				fmt.Printf("         • [%s] %s → %s (%s，%d 处，%s)\n", r.Rule, r.To, r.From, filepath.Base(r.Path), r.Count, r.Timestamp)
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// printStatusResult 在控制台输出汉化状态
+func printStatusResult(r *translator.StatusResult) {
+	fmt.Println("\n" + strings.Repeat("═", 50))
+	fmt.Println("📊 汉化状态")
+	fmt.Println(strings.Repeat("═", 50))
+
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+
+	for _, f := range r.Files {
+		fmt.Printf("\n📁 %s\n", f.Description)
+		fmt.Printf("   路径: %s\n", f.Path)
+		switch {
+		case !f.Exists:
+			fmt.Println("   ❌ 文件不存在")
+		case f.PendingHits == 0:
+			fmt.Printf("   ✓ 已汉化 (%.2f MB)\n", float64(f.Size)/1024/1024)
+		default:
+			fmt.Printf("   ⚠️ 未汉化，可翻译 %d 处 (%.2f MB)\n", f.PendingHits, float64(f.Size)/1024/1024)
+		}
+	}
+
+	if len(r.Checksums) > 0 {
+		fmt.Println("\n🔧 product.json 校验和:")
+		for _, c := range r.Checksums {
+			if c.Action == "present" {
+				fmt.Printf("   • %s: 存在\n", c.Key)
+			} else {
+				fmt.Printf("   • %s: 已移除\n", c.Key)
+			}
+		}
+	}
+
+	if r.LastBackup != nil {
+		fmt.Printf("\n📦 最近备份: %s (%s)\n", r.LastBackup.ID, r.LastBackup.Timestamp)
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// backupTypeLabel 返回备份类型的显示名称
+func backupTypeLabel(backupType string) string {
+	if backupType == "continue" {
+		return "Continue"
+	}
+	return "Antigravity"
+}
+
+// printConfigResult 在控制台输出配置项
+func printConfigResult(r *ConfigResult) {
+	fmt.Printf("📄 配置文件: %s\n", r.Path)
+	keys := make([]string, 0, len(r.Values))
+	for key := range r.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("   %s = %s\n", key, r.Values[key])
+	}
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printInstallsResult 在控制台输出检测到的安装
+func printInstallsResult(r *InstallsResult) {
+	fmt.Printf("\n🖥️  检测到 %d 个 Antigravity 安装:\n", len(r.Installations))
+	for i, inst := range r.Installations {
+		fmt.Printf("   %d. %s\n", i+1, installationLabel(inst))
+	}
+	if len(r.ContinueExtensions) > 0 {
+		fmt.Printf("\n🧩 检测到 %d 个 Continue 扩展:\n", len(r.ContinueExtensions))
+		for i, ext := range r.ContinueExtensions {
+			fmt.Printf("   %d. %s\n", i+1, continueExtensionLabel(ext))
+		}
+	}
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printBenchResult 在控制台输出基准测试结果
+func printBenchResult(r *BenchResult) {
+	fmt.Printf("\n⏱️  替换引擎基准测试 (每项运行 %d 次，取最短耗时)\n", r.Iterations)
+	for _, f := range r.Files {
+		name := f.Path
+		if f.Synthetic {
+			name = "合成数据"
+		}
+		fmt.Printf("\n📁 %s (%s)\n", f.Target, name)
+		fmt.Printf("   大小: %.1f MB\n", float64(f.Size)/1024/1024)
+		fmt.Printf("   逐条替换: %10.1f ms (%d 处)\n", f.LegacyMs, f.LegacyHits)
+		fmt.Printf("   单次扫描: %10.1f ms (%d 处)\n", f.EngineMs, f.EngineHits)
+		fmt.Printf("   加速比:   %10.1fx\n", f.Speedup)
+		if f.Identical {
+			fmt.Println("   ✓ 输出一致")
+		}
+	}
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printProgressText 在控制台输出一行进度
+func printProgressText(e translator.ProgressEvent) {
+	if e.Done == 1 {
+		fmt.Printf("\n🔄 正在翻译 %d 个文件...\n", e.Total)
+	}
+	if e.Event == "failed" {
+		fmt.Printf("   ❌ [%d/%d] %s: %s\n", e.Done, e.Total, filepath.Base(e.Path), e.Error)
+		return
+	}
+	fmt.Printf("   ⏳ [%d/%d] 已翻译: %s\n", e.Done, e.Total, filepath.Base(e.Path))
+}
+
+// printProgressJSON 以 JSON Lines 格式把进度写到标准错误，不影响标准输出中的结果 JSON
+func printProgressJSON(e translator.ProgressEvent) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// printDryRunResult 在控制台输出 apply --dry-run 的预览: 每个文件命中的规则，
+// 模式规则 (占位符、正则等) 额外列出实际替换的文本
+func printDryRunResult(r *translator.ApplyResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Println("🔍 预览汉化 (不备份、不写回)...")
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, f := range r.Files {
+		fmt.Printf("\n📁 %s\n", f.Description)
+		fmt.Printf("   路径: %s\n", f.Path)
+		if f.Error != nil {
+			fmt.Printf("   ❌ %s\n", f.Error.Message)
+			continue
+		}
+		fmt.Printf("   📊 命中 %d 条规则，共 %d 处替换，文件大小变化 %+d 字节\n", len(f.Stats.Rules), f.Stats.Hits(), f.SizeAfter-f.SizeBefore)
+		for _, hit := range f.Stats.Rules {
+			label := hit.Kind
+			if hit.Anchor != "" {
+				label += " @" + hit.Anchor
+			}
+			if hit.Scope != "" {
+				label += " #" + hit.Scope
+			}
+			fmt.Printf("   [%s] ×%d\n", label, hit.Count)
+			if len(hit.Samples) == 0 {
+				fmt.Printf("     - %s\n     + %s\n", hit.From, hit.To)
+				continue
+			}
+			fmt.Printf("     规则: %s → %s\n", hit.From, hit.To)
+			for _, sample := range hit.Samples {
+				fmt.Printf("     - %s\n     + %s\n", sample.From, sample.To)
+			}
+		}
+		for _, s := range f.Stats.Skipped {
+			fmt.Printf("   ⚠️ [%s] %s: %d 处匹配两端引号不同或不在字符串字面量内，未替换\n", s.Kind, s.From, s.Count)
+		}
+	}
+
+	if len(r.TargetHits) > 0 {
+		fmt.Println("\n📊 按目标汇总:")
+		for _, th := range r.TargetHits {
+			fmt.Printf("   %-24s %d 个文件，命中 %d 条规则，共 %d 处替换\n", th.Target, th.Files, th.Rules, th.Hits)
+		}
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printLintResult 在控制台输出规则检查结果
+func printLintResult(r *translator.LintResult) {
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Println("🔍 检查内置规则...")
+	fmt.Println(strings.Repeat("─", 50))
+	for _, p := range r.Phases {
+		fmt.Printf("   %-10s %-10s %5d 条规则 (模式规则 %d 条)\n", p.Pack, p.Phase, p.Rules, p.Patterns)
+	}
+	printProblems(r.Warnings, r.Errors)
+	if len(r.Errors) == 0 && len(r.Warnings) == 0 {
+		fmt.Println("\n   ✅ 未发现问题")
+	}
+}
+
+// printStaleResult 在控制台输出失效规则和候选更新
+func printStaleResult(r *translator.StaleResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Println("🔍 查找失效的规则...")
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, rule := range r.Rules {
+		fmt.Printf("\n⚠️  %s/%s (%s): %s → %s\n", rule.Pack, rule.Phase, rule.Target, rule.From, rule.To)
+		if len(rule.Suggestions) == 0 {
+			fmt.Println("   未找到相近的字面量")
+			continue
+		}
+		for _, s := range rule.Suggestions {
+			fmt.Printf("   [%d] %.0f%%  %s → %s\n", s.ID, s.Similarity*100, s.From, s.To)
+		}
+	}
+	if len(r.Errors) == 0 && len(r.Rules) == 0 {
+		fmt.Println("\n   ✅ 所有规则均有效")
+	}
+	if len(r.Accepted) > 0 {
+		fmt.Printf("\n✅ 已将 %d 条建议写入用户规则文件: %s\n", len(r.Accepted), r.UserRules)
+	} else if len(r.Rules) > 0 && len(r.Errors) == 0 {
+		fmt.Println("\n💡 使用 --accept <编号,...> 或 --accept all 把建议写入用户规则文件")
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printStringsDiffResult 在控制台输出两个版本之间的界面字符串变化
+func printStringsDiffResult(r *translator.StringsDiffResult) {
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Printf("🔍 比较界面字符串: %s → %s\n", r.Old, r.New)
+	fmt.Println(strings.Repeat("─", 50))
+
+	printRules := func(refs []translator.RuleRef) {
+		for _, ref := range refs {
+			fmt.Printf("       ⚠️  受影响的规则 [%s] %s → %s\n", ref.Kind, ref.From, ref.To)
+		}
+	}
+	for _, d := range r.Targets {
+		fmt.Printf("\n📁 %s: 新增 %d，删除 %d，修改 %d\n", d.Target, len(d.Added), len(d.Removed), len(d.Modified))
+		for _, m := range d.Modified {
+			fmt.Printf("   ~ %s\n     → %s (%.0f%%)\n", m.Old, m.New, m.Similarity*100)
+			printRules(m.Rules)
+		}
+		for _, removed := range d.Removed {
+			fmt.Printf("   - %s\n", removed.Text)
+			printRules(removed.Rules)
+		}
+		for _, added := range d.Added {
+			fmt.Printf("   + %s\n", added)
+		}
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printExtractResult 在控制台输出从手工修改的 bundle 生成的规则 (可直接粘贴到 rules/ 中对应的规则表)
+func printExtractResult(r *translator.ExtractResult) {
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	if r.Target != "" {
+		fmt.Printf("🔍 对比 %s → %s (%s)\n", r.Original, r.Edited, r.Target)
+	} else {
+		fmt.Printf("🔍 对比 %s → %s\n", r.Original, r.Edited)
+	}
+	fmt.Println(strings.Repeat("─", 50))
+
+	if len(r.Errors) > 0 {
+		printProblems(r.Warnings, r.Errors)
+		return
+	}
+	fmt.Printf("\n📊 生成 %d 条规则，%d 处改动已由现有规则产生，%d 处不安全的改动\n", len(r.Rules), r.Existing, len(r.Unsafe))
+	for _, kind := range []string{"normal", "template", "anchored"} {
+		header := false
+		for _, rule := range r.Rules {
+			if rule.Kind != kind {
+				continue
+			}
+			if !header {
+				fmt.Printf("\n// %s\n", kind)
+				header = true
+			}
+			switch kind {
+			case "normal":
+				fmt.Printf("\t%s: %s,\n", goStringLiteral(rule.From), goStringLiteral(rule.To))
+			case "template":
+				fmt.Printf("\t{%s, %s},\n", goStringLiteral(rule.From), goStringLiteral(rule.To))
+			default:
+				anchor := fmt.Sprintf("engine.Props(%q)", rule.Anchor)
+				if callee, ok := strings.CutSuffix(rule.Anchor, "()"); ok {
+					anchor = fmt.Sprintf("engine.Callees(%q)", callee)
+				}
+				fmt.Printf("\t{From: %s, To: %s, Anchor: %s},\n", goStringLiteral(rule.From), goStringLiteral(rule.To), anchor)
+			}
+		}
+	}
+
+	for _, u := range r.Unsafe {
+		reason := u.Reason
+		if reason == "" {
+			reason = "字符串字面量之外的改动"
+		}
+		fmt.Printf("\n⚠️  第 %d 行 (偏移 %d): %s，无法生成规则\n", u.Line, u.Offset, reason)
+		fmt.Printf("   - %s\n   + %s\n", u.Original, u.Edited)
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// goStringLiteral 把规则文本写成 Go 字符串字面量: 优先使用反引号 (与 rules/ 中的规则表一致)
+func goStringLiteral(s string) string {
+	if !strings.Contains(s, "`") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// maxRemainingShown 文本输出中每个文件最多列出的未恢复字面量数
+const maxRemainingShown = 20
+
+// printUntranslateResult 在控制台输出反向翻译结果
+func printUntranslateResult(r *translator.UntranslateResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+	if r.BackupDir != "" {
+		fmt.Printf("\n📁 备份目录: %s (汉化后的文件，可用 restore 撤销本次恢复)\n", r.BackupDir)
+	}
+
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	if r.DryRun {
+		fmt.Println("🔍 预览反向翻译 (不会修改任何文件)")
+	} else {
+		fmt.Println("⏪ 用反向规则恢复原版...")
+	}
+	fmt.Println(strings.Repeat("─", 50))
+
+	for _, f := range r.Files {
+		fmt.Printf("\n📁 %s (%s)\n", f.Path, f.Target)
+		if f.Error != nil {
+			fmt.Printf("   ❌ %s\n", f.Error.Message)
+			continue
+		}
+		fmt.Printf("   ✓ 反向替换 %d 处", f.Restored)
+		if f.Written {
+			fmt.Print("，已写回")
+		}
+		fmt.Println()
+		switch f.Verified {
+		case "match":
+			fmt.Println("   ✅ 与记录的原版哈希一致")
+		case "mismatch":
+			fmt.Println("   ⚠️  与记录的原版哈希不一致")
+		default:
+			fmt.Println("   ❔ 没有原版哈希记录，无法校验")
+		}
+		if len(f.Remaining) > 0 {
+			fmt.Printf("   ⚠️  %d 个字面量仍含中文:\n", len(f.Remaining))
+		}
+		for i, lit := range f.Remaining {
+			if i == maxRemainingShown {
+				fmt.Printf("      ... 还有 %d 个 (使用 --output json 查看全部)\n", len(f.Remaining)-maxRemainingShown)
+				break
+			}
+			fmt.Printf("      第 %d 行: %s", lit.Line, lit.Text)
+			if lit.Count > 1 {
+				fmt.Printf(" (%d 处)", lit.Count)
+			}
+			fmt.Println()
+			if len(lit.Candidates) > 0 {
+				fmt.Printf("         可能的原文: %s\n", strings.Join(lit.Candidates, " / "))
+			}
+		}
+	}
+
+	// 只列出与未恢复的字面量有关的规则，全部规则见 JSON 输出
+	candidates := make(map[string]bool)
+	for _, f := range r.Files {
+		for _, lit := range f.Remaining {
+			for _, c := range lit.Candidates {
+				candidates[c] = true
+			}
+		}
+	}
+	if len(r.Irreversible) > 0 {
+		fmt.Printf("\n⚠️  %d 条规则无法反向，其译文保持不变\n", len(r.Irreversible))
+		for _, ir := range r.Irreversible {
+			if len(ir.From) > 0 && candidates[ir.From[0]] {
+				fmt.Printf("   [%s] %s ← %s: %s\n", ir.Target, ir.To, strings.Join(ir.From, " / "), ir.Reason)
+			}
+		}
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// maxExplainShown 文本输出中最多列出的出现位置数
+const maxExplainShown = 10
+
+// printExplainResult 在控制台输出译文反查结果
+func printExplainResult(r *translator.ExplainResult) {
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	fmt.Printf("🔎 查找: %s\n", r.Text)
+	fmt.Println(strings.Repeat("─", 50))
+
+	if len(r.Matches) == 0 && len(r.Errors) == 0 {
+		fmt.Println("\n   未在目标文件中找到这段文字")
+	}
+	for i, m := range r.Matches {
+		if i == maxExplainShown {
+			fmt.Printf("\n   ... 还有 %d 处 (使用 --output json 查看全部)\n", len(r.Matches)-maxExplainShown)
+			break
+		}
+		fmt.Printf("\n📁 %s (%s) 第 %d 行，偏移 %d\n", m.Path, m.Target, m.Line, m.Offset)
+		fmt.Printf("   上下文: %s\n", strings.NewReplacer("\r", "", "\n", "↵").Replace(m.Context))
+		switch {
+		case len(m.Rules) == 0 && m.Traced:
+			fmt.Println("   ❔ 替换记录中没有覆盖这段文字的替换 (可能是原版内容)")
+		case len(m.Rules) == 0:
+			fmt.Println("   ❔ 没有译文包含这段文字的规则 (可能是原版内容)")
+		case !m.Traced:
+			fmt.Println("   没有替换记录 (使用 apply --trace 汉化后可准确定位)，译文包含这段文字的规则:")
+		}
+		for _, rule := range m.Rules {
+			if rule.Stale {
+				fmt.Printf("   ⚠️ [%s] 规则表在汉化后修改过，当前规则中没有产生这处替换的规则 (重新 apply --trace 后可准确定位)\n", rule.ID)
+				fmt.Printf("      实际替换: %s → %s\n", rule.Source, rule.Produced)
+				continue
+			}
+			fmt.Printf("   ✓ [%s] %s → %s\n", rule.ID, rule.From, rule.To)
+			if rule.Anchor != "" {
+				fmt.Printf("      锚点: %s\n", rule.Anchor)
+			}
+			if m.Traced && rule.Source != rule.From {
+				fmt.Printf("      实际替换: %s → %s\n", rule.Source, rule.Produced)
+			}
+			position := "" // 当前规则表中的位置，随规则表增删规则而变化，只用于显示
+			if rule.Position != "" {
+				position = " (" + rule.Position + ")"
+			}
+			switch {
+			case rule.Line > 0:
+				fmt.Printf("      位置: %s:%d%s\n", rule.File, rule.Line, position)
+			case rule.File != "":
+				fmt.Printf("      位置: %s%s\n", rule.File, position)
+			}
+		}
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
+
+// printRevertResult 在控制台输出撤销规则的结果
+func printRevertResult(r *translator.RevertResult) {
+	if r.InstallPath != "" {
+		fmt.Printf("\n📍 安装路径: %s\n", r.InstallPath)
+	}
+
+	fmt.Println("\n" + strings.Repeat("─", 50))
+	if r.DryRun {
+		fmt.Println("🔍 预览撤销规则 (不会修改任何文件)")
+	} else {
+		fmt.Println("↩️  撤销规则...")
+	}
+	fmt.Println(strings.Repeat("─", 50))
+
+	// 只列出有替换被撤销的规则，全部选中的规则见 JSON 输出
+	counts := make(map[string]int)
+	for _, f := range r.Files {
+		for id, n := range f.Rules {
+			counts[id] += n
+		}
+	}
+	if len(r.Rules) > 0 {
+		fmt.Printf("\n选中 %d 条规则，其中 %d 条有替换被撤销:\n", len(r.Rules), len(counts))
+		for _, rule := range r.Rules {
+			if n := counts[rule.ID]; n > 0 {
+				fmt.Printf("   [%s] %s → %s (%d 处)\n", rule.ID, rule.To, rule.From, n)
+			}
+		}
+	}
+	for _, f := range r.Files {
+		if f.Method == "" && f.Error == nil {
+			continue // 没有对该文件生效的规则
+		}
+		fmt.Printf("\n📁 %s (%s)\n", f.Path, f.Target)
+		if f.Error != nil {
+			fmt.Printf("   ❌ %s\n", f.Error.Message)
+			continue
+		}
+		method := "按替换记录"
+		if f.Method == "inverse" {
+			method = "按反向规则"
+		}
+		fmt.Printf("   ✓ %s撤销 %d 处", method, f.Reverted)
+		if f.Written {
+			fmt.Print("，已写回")
+		}
+		fmt.Println()
+		if f.Pristine {
+			fmt.Println("   ✅ 撤销后与原版一致")
+		}
+	}
+	for _, c := range r.Checksums {
+		switch c.Action {
+		case "restored":
+			fmt.Printf("\n   ✓ 已加回校验和: %s\n", c.Key)
+		case "removed":
+			fmt.Printf("\n   ✓ 已移除校验和: %s\n", c.Key)
+		}
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Printf("\n⚠️  %d 条规则无法撤销，其译文保持不变\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Printf("   [%s] %s ← %s: %s\n", s.Target, s.To, strings.Join(s.From, " / "), s.Reason)
+		}
+	}
+	if r.BackupDir != "" {
+		fmt.Printf("\n📁 备份目录: %s (撤销前的文件，可用 restore 撤销本次撤销)\n", r.BackupDir)
+	}
+
+	printProblems(r.Warnings, r.Errors)
+}
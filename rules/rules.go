@@ -0,0 +1,50 @@
+// Package rules 包含内置的汉化规则包 (main、chat、continue)。
+package rules
+
+import (
+	"sort"
+	"sync"
+
+	"antigravity_translator/engine"
+)
+
+// packs 按名称索引的内置规则包
+var packs = map[string]*engine.RuleSet{
+	Main.Name:     Main,
+	Chat.Name:     Chat,
+	Continue.Name: Continue,
+}
+
+// Lookup 按名称查找规则包，不存在时返回 nil
+func Lookup(name string) *engine.RuleSet {
+	return packs[name]
+}
+
+// Names 返回所有规则包名称 (已排序)
+func Names() []string {
+	names := make([]string, 0, len(packs))
+	for name := range packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	continueMarkersOnce sync.Once
+	continueMarkers     []string
+)
+
+// ContinueMarkers 返回用于识别 Continue 界面分块的标记字符串:
+// quotedTranslationsContinue 中每个原文和译文的 "x"、'x'、`x` 三种形式
+// (包含译文是为了让已汉化的分块仍能被识别出来)
+func ContinueMarkers() []string {
+	continueMarkersOnce.Do(func() {
+		for k, v := range quotedTranslationsContinue {
+			for _, q := range []string{"\"", "'", "`"} {
+				continueMarkers = append(continueMarkers, q+k+q, q+v+q)
+			}
+		}
+	})
+	return continueMarkers
+}
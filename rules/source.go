@@ -0,0 +1,87 @@
+package rules
+
+import (
+	"embed"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ========================================
+// 规则的源码位置
+// ========================================
+//
+// 规则表以 Go 源码的形式编译进程序，规则本身不记录位置。为了从一段译文反查到规则所在的文件和行
+// (explain)，程序内嵌了规则表的源码，按原文和译文的 Go 字符串字面量写法查找所在行。
+
+//go:embed translations_*.go
+var sources embed.FS
+
+// Source 返回规则包中的规则在规则表源码中的文件名和行号 (从 1 开始)，找不到时返回 ok 为 false
+// 先查找规则包自己的规则表 (translations_<规则包>.go)，优先返回原文和译文在同一行的位置
+// (Continue 规则表中的原文不带引号，同样可以找到)
+func Source(pack, from, to string) (file string, line int, ok bool) {
+	entries, err := sources.ReadDir(".")
+	if err != nil {
+		return "", 0, false
+	}
+	own := "translations_" + pack + ".go"
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == own) != (names[j] == own) {
+			return names[i] == own
+		}
+		return names[i] < names[j]
+	})
+
+	froms, tos := goLiterals(from), goLiterals(to)
+	fallbackFile, fallbackLine := "", 0
+	for _, name := range names {
+		content, err := sources.ReadFile(name)
+		if err != nil {
+			continue
+		}
+		for i, text := range strings.Split(string(content), "\n") {
+			if !containsAny(text, froms) {
+				continue
+			}
+			if containsAny(text, tos) {
+				return name, i + 1, true
+			}
+			if fallbackFile == "" {
+				fallbackFile, fallbackLine = name, i+1
+			}
+		}
+	}
+	return fallbackFile, fallbackLine, fallbackFile != ""
+}
+
+// goLiterals 返回文本在规则表中可能的 Go 字符串字面量写法 (反引号和双引号)，
+// 文本是带引号的完整字符串字面量时还包括去掉引号后的写法
+func goLiterals(s string) []string {
+	texts := []string{s}
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'' || s[0] == '`') && s[len(s)-1] == s[0] {
+		texts = append(texts, s[1:len(s)-1])
+	}
+	var literals []string
+	for _, text := range texts {
+		if !strings.Contains(text, "`") {
+			literals = append(literals, "`"+text+"`")
+		}
+		literals = append(literals, strconv.Quote(text))
+	}
+	return literals
+}
+
+// containsAny 判断文本是否包含任意一个子串
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,8 +1,6 @@
-package main
+package rules
 
-import (
-	"strings"
-)
+import "antigravity_translator/engine"
 
 // normalTranslationsChat chat.js 的普通翻译规则
 var normalTranslationsChat = map[string]string{
@@ -36,7 +34,6 @@ var normalTranslationsChat = map[string]string{
 	`"Accessibility"`: `"辅助功能"`,
 	`"Active Browser page"`: `"活动浏览器页面"`,
 	`"Active Browser pages"`: `"活动浏览器页面"`,
-	`"Active"`: `"活动"`,
 	`"Activities cannot be viewed"`: `"无法查看活动"`,
 	`"Activity"`: `"活动"`,
 	`"Add context"`: `"添加上下文"`,
@@ -54,7 +51,6 @@ var normalTranslationsChat = map[string]string{
 	`"Agent execution terminated due to model provider overload. Please try again later."`: `"代理执行因模型提供商过载而终止。请稍后重试。"`,
 	`"Agent terminated due to error"`: `"代理因错误而终止"`,
 	`"Agent will execute tasks directly. Use for simple tasks that can be completed faster"`: `"代理将直接执行任务。适用于可以更快完成的简单任务"`,
-	`"Agent"`: `"代理"`,
 	`"AI may make mistakes. Double-check all generated code."`: `"AI 可能会犯错。请仔细检查所有生成的代码。"`,
 	`"Allow once"`: `"允许一次"`,
 	`"Allow Once"`: `"允许一次"`,
@@ -345,7 +341,6 @@ var normalTranslationsChat = map[string]string{
 	`"Hidden"`: `"已隐藏"`,
 	`"Hide 0s"`: `"隐藏 0 秒"`,
 	`"Hide"`: `"隐藏"`,
-	`"History"`: `"历史"`,
 	`"Hours"`: `"小时"`,
 	`"I did"`: `"已完成"`,
 	`"If you believe this is a bug, please"`: `"如果您认为这是一个 bug，请"`,
@@ -731,10 +726,6 @@ var normalTranslationsChat = map[string]string{
 	`"Your modified files:"`: `"您修改的文件:"`,
 	`"Your recent Browser activity:"`: `"您最近的浏览器活动:"`,
 	`"Your recent terminal commands:"`: `"您最近的终端命令:"`,
-	"\"Unleash failed to resolve \\\"fetch\\\"\"": "\"Unleash 无法解析 \\\"fetch\\\"\"",
-	"\"Unleash failed to resolve \\\"AbortController\\\" factory\"": "\"Unleash 无法解析 \\\"AbortController\\\" 工厂\"",
-	"\"Unleash: You must either provide your own \\\"fetch\\\" implementation or run in an environment where \\\"fetch\\\" is available.\"": "\"Unleash: 您必须提供自己的 \\\"fetch\\\" 实现，或在 \\\"fetch\\\" 可用的环境中运行。\"",
-	"\"Unleash: You must either provide your own \\\"AbortController\\\" implementation or run in an environment where \\\"AbortController\\\" is available.\"": "\"Unleash: 您必须提供自己的 \\\"AbortController\\\" 实现，或在 \\\"AbortController\\\" 可用的环境中运行。\"",
 	`"Unleash SDK has already started, if you want to restart the SDK you should call client.stop() before starting again."`: `"Unleash SDK 已启动，如果要重新启动 SDK，应先调用 client.stop()。"`,
 	`"Unleash: Fetching feature toggles did not have an ok response"`: `"Unleash: 获取功能开关未收到正常响应"`,
 	`"Unleash: unable to fetch feature toggles"`: `"Unleash: 无法获取功能开关"`,
@@ -841,19 +832,17 @@ var normalTranslationsChat = map[string]string{
 	`"for more help."`: `"获取更多帮助。"`,
 }
 
+// escapedTranslationsChat chat.js 中按解码后的文本书写的翻译规则
+// 字符串中的 \" 等转义写法都能匹配，译文按原文的引号重新转义
+var escapedTranslationsChat = map[string]string{
+	`"Unleash failed to resolve "fetch""`: `"Unleash 无法解析 "fetch""`,
+	`"Unleash failed to resolve "AbortController" factory"`: `"Unleash 无法解析 "AbortController" 工厂"`,
+	`"Unleash: You must either provide your own "fetch" implementation or run in an environment where "fetch" is available."`: `"Unleash: 您必须提供自己的 "fetch" 实现，或在 "fetch" 可用的环境中运行。"`,
+	`"Unleash: You must either provide your own "AbortController" implementation or run in an environment where "AbortController" is available."`: `"Unleash: 您必须提供自己的 "AbortController" 实现，或在 "AbortController" 可用的环境中运行。"`,
+}
+
 // templateTranslationsChat chat.js 的模板翻译规则
 var templateTranslationsChat = [][2]string{
-	{`keyString:"in"`, `keyString:"在"`},
-	{`keyString:"with query"`, `keyString:"关键词"`},
-	{`keyString:"including patterns"`, `keyString:"匹配模式"`},
-	{`keyString:"case"`, `keyString:"大小写"`},
-	{`value:"insensitive"`, `value:"忽略"`},
-	{`keyString:"mode"`, `keyString:"模式"`},
-	{`value:"regex"`, `value:"正则"`},
-	{`keyString:"with depth"`, `keyString:"深度"`},
-	{`keyString:"with extensions"`, `keyString:"扩展名"`},
-	{`keyString:"excluding patterns"`, `keyString:"排除"`},
-	{`keyString:"with flags"`, `keyString:"选项"`},
 	{`"Searched`, `"已搜索`},
 	{"`Suggested ${n}`", "`建议 ${n}`"},
 	{"`Ran ${n}`", "`已运行 ${n}`"},
@@ -863,17 +852,11 @@ var templateTranslationsChat = [][2]string{
 	{"`Thought for ${", "`思考了 ${"},
 	{"`Thinking for ${", "`思考中 ${"},
 	{"Ask anything (${r?\"⌘L\":\"Ctrl+L\"}), @ to mention, / for workflows", "你可以问任何问题 (${r?\"⌘L\":\"Ctrl+L\"})，@ 引用，/ 工作流"},
-	{"Ask anything (${r?\"⌘L\":\"Ctrl+L\"}), @ to mention, / for workflows", "你可以问任何问题 (${r?\"⌘L\":\"Ctrl+L\"})，@ 引用，/ 工作流"},
 	{"\"Ask anything - use '@' to mention code blocks\"", "\"你可以问任何问题 - 使用 '@' 引用代码块\""},
-	{"label:\"提及\"", "label:\"引用\""},
-	{"label: \"提及\"", "label: \"引用\""},
 	{"'Workflows are saved prompts that Agent can follow. To trigger a workflow, type \"/\" in Agent.'", "'工作流是代理可以遵循的已保存提示。要触发工作流，请在代理中输入 \"/\" 。'"},
-	{"${a.length} File${1===a.length?\"\":\"s\"} With Changes", "${a.length} 个文件${1===a.length?\"\":\"s\"} 包含更改"},
 	{"Changes Overview (${l})", "更改概览 (${l})"},
-	{"${n} Background Process${1===n?\"\":\"es\"} Running", "${n} 个后台进程${1===n?\"\":\"es\"} 运行中"},
 	{"Artifacts (${n.length} Files for Conversation)", "工件 (${n.length} 个对话文件)"},
 	{"Terminal (${r})", "终端 (${r})"},
-	{"Exit code ${e.exitCode}", "退出码 ${e.exitCode}"},
 	{"Error while reverting step: ${e}", "撤销步骤时出错: ${e}"},
 	{"[Model Select] Invalid model label: ${e}", "[模型选择] 无效的模型标签: ${e}"},
 	{"Failed to fetch available plugins: ${e}", "获取可用插件失败: ${e}"},
@@ -885,26 +868,49 @@ var templateTranslationsChat = [][2]string{
 	{` You can resume using this model at ${new Date(t).toLocaleString()}.`, ` 您可以在 ${new Date(t).toLocaleString()} 继续使用此模型。`},
 }
 
-// applyChatTranslations 应用 chat.js 的翻译规则
-func applyChatTranslations(content string) (string, TranslateStats) {
-	stats := TranslateStats{}
-
-	// 1. 应用普通翻译
-	for from, to := range normalTranslationsChat {
-		if strings.Contains(content, from) {
-			content = strings.ReplaceAll(content, from, to)
-			stats.NormalCount++
-		}
-	}
+// anchoredTranslationsChat chat.js 中只在特定属性或调用位置翻译的规则 (通用字符串、搜索结果摘要的键值)
+var anchoredTranslationsChat = []engine.Rule{
+	{From: `"Active"`, To: `"活动"`, Anchor: engine.Props(engine.UIProps...)},
+	{From: `"Agent"`, To: `"代理"`, Anchor: engine.Props(engine.UIProps...)},
+	{From: `"History"`, To: `"历史"`, Anchor: engine.Props(engine.UIProps...)},
+	{From: `"in"`, To: `"在"`, Anchor: engine.Props("keyString")},
+	{From: `"with query"`, To: `"关键词"`, Anchor: engine.Props("keyString")},
+	{From: `"including patterns"`, To: `"匹配模式"`, Anchor: engine.Props("keyString")},
+	{From: `"case"`, To: `"大小写"`, Anchor: engine.Props("keyString")},
+	{From: `"insensitive"`, To: `"忽略"`, Anchor: engine.Props("value")},
+	{From: `"mode"`, To: `"模式"`, Anchor: engine.Props("keyString")},
+	{From: `"regex"`, To: `"正则"`, Anchor: engine.Props("value")},
+	{From: `"with depth"`, To: `"深度"`, Anchor: engine.Props("keyString")},
+	{From: `"with extensions"`, To: `"扩展名"`, Anchor: engine.Props("keyString")},
+	{From: `"excluding patterns"`, To: `"排除"`, Anchor: engine.Props("keyString")},
+	{From: `"with flags"`, To: `"选项"`, Anchor: engine.Props("keyString")},
+	// 普通翻译把 "Mention" 译为 "提及"，作为菜单项标签时改用 "引用"
+	{From: `"提及"`, To: `"引用"`, Anchor: engine.Props("label")},
+}
 
-	// 2. 应用模板翻译
-	for _, pair := range templateTranslationsChat {
-		from, to := pair[0], pair[1]
-		if strings.Contains(content, from) {
-			content = strings.ReplaceAll(content, from, to)
-			stats.TemplateCount++
-		}
-	}
+// regexTranslationsChat chat.js 的正则翻译规则 (RE2 语法，译文中 "$${" 输出 JS 模板的 "${")
+// 这几处模板字面量每个版本都存在，未命中说明上游改写了文案或模板结构
+var regexTranslationsChat = []engine.Rule{
+	{From: `Exit code \$\{([\w.]+)\}`, To: `退出码 $${$1}`, Expect: engine.AtLeast(1)},
+	// 中文不需要复数后缀，整段 ${1===n?"":"s"} 一并去掉
+	{From: `\$\{(?P<n>[\w.]+)\} File\$\{1===[\w.]+\?"":"s"\} With Changes`, To: `$${${n}} 个文件包含更改`, Expect: engine.AtLeast(1)},
+	{From: `\$\{(?P<n>[\w.]+)\} Background Process\$\{1===[\w.]+\?"":"es"\} Running`, To: `$${${n}} 个后台进程运行中`, Expect: engine.AtLeast(1)},
+}
 
-	return content, stats
+// Chat chat.js 的规则包
+var Chat = &engine.RuleSet{
+	Name: "chat",
+	Phases: []*engine.Phase{
+		// 1. 普通翻译
+		engine.NewPhase("normal", engine.CategoryNormal, func() []engine.Rule {
+			return append(engine.RulesFromMap("normal", normalTranslationsChat),
+				engine.WithMode(engine.MatchEscapes, engine.RulesFromMap("normal", escapedTranslationsChat))...)
+		}),
+		// 2. 模板翻译
+		engine.NewPhase("template", engine.CategoryTemplate, func() []engine.Rule { return engine.RulesFromPairs("template", templateTranslationsChat) }),
+		// 3. 锚定翻译 (只替换特定属性或调用位置的字面量，可匹配普通翻译的译文，如 label:"提及")
+		engine.NewPhase("anchored", engine.CategoryNormal, func() []engine.Rule { return engine.RulesFromTable("anchored", anchoredTranslationsChat) }),
+		// 4. 正则翻译
+		engine.NewPhase("regex", engine.CategoryRegex, func() []engine.Rule { return engine.RulesFromRegexps("regex", regexTranslationsChat) }),
+	},
 }
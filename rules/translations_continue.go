@@ -1,11 +1,10 @@
-package main
+package rules
 
-import (
-	"strings"
-)
+import "antigravity_translator/engine"
 
 // ContinueTranslations Continue 扩展的翻译规则
-// 目标文件: C:\Users\{用户名}\.antigravity\extensions\continue.continue-{版本号}-win32-x64\gui\assets\index.js
+// 目标文件: C:\Users\{用户名}\.antigravity\extensions\continue.continue-{版本号}-win32-x64\gui\assets\*.js
+// (index.js 及 Vite 拆分出的分块，按 quotedTranslationsContinue 中的标记文本识别)
 
 // quotedTranslationsContinue 带引号的翻译规则（会自动匹配 "key", 'key', `key` 三种格式）
 var quotedTranslationsContinue = map[string]string{
@@ -269,44 +268,21 @@ var rawTranslationsContinue = map[string]string{
 	"Error: ${(r==null?void 0:r.title)||\"Model\"} - ${c||\"Unknown error\"}": "Error: ${(r==null?void 0:r.title)||\"Model\"} - ${c||\"未知错误\"}",
 }
 
-// applyContinueTranslations 应用 Continue 扩展的翻译规则
-func applyContinueTranslations(content string) (string, TranslateStats) {
-	stats := TranslateStats{}
-
-	// 1. 带引号的翻译（匹配 "key", 'key', `key` 三种格式）
-	for k, v := range quotedTranslationsContinue {
-		// "key" -> "value"
-		oldDouble := "\"" + k + "\""
-		newDouble := "\"" + v + "\""
-		if strings.Contains(content, oldDouble) {
-			content = strings.ReplaceAll(content, oldDouble, newDouble)
-			stats.NormalCount++
-		}
-
-		// 'key' -> 'value'
-		oldSingle := "'" + k + "'"
-		newSingle := "'" + v + "'"
-		if strings.Contains(content, oldSingle) {
-			content = strings.ReplaceAll(content, oldSingle, newSingle)
-			stats.NormalCount++
-		}
-
-		// `key` -> `value`
-		oldBacktick := "`" + k + "`"
-		newBacktick := "`" + v + "`"
-		if strings.Contains(content, oldBacktick) {
-			content = strings.ReplaceAll(content, oldBacktick, newBacktick)
-			stats.NormalCount++
-		}
-	}
-
-	// 2. 全局替换
-	for k, v := range rawTranslationsContinue {
-		if strings.Contains(content, k) {
-			content = strings.ReplaceAll(content, k, v)
-			stats.TemplateCount++
-		}
-	}
-
-	return content, stats
+// Continue Continue 扩展界面的规则包
+var Continue = &engine.RuleSet{
+	Name: "continue",
+	Phases: []*engine.Phase{
+		// 1. 带引号的翻译（"key", 'key', `key` 三种格式）
+		engine.NewPhase("quoted", engine.CategoryNormal, func() []engine.Rule {
+			var rules []engine.Rule
+			for _, r := range engine.RulesFromMap("quoted", quotedTranslationsContinue) {
+				for _, q := range []string{"\"", "'", "`"} {
+					rules = append(rules, engine.Rule{Kind: r.Kind, From: q + r.From + q, To: q + r.To + q})
+				}
+			}
+			return rules
+		}),
+		// 2. 全局替换
+		engine.NewPhase("raw", engine.CategoryTemplate, func() []engine.Rule { return engine.RulesFromMap("raw", rawTranslationsContinue) }),
+	},
 }
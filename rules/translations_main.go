@@ -1,15 +1,6 @@
-package main
+package rules
 
-import (
-	"strings"
-)
-
-// TranslateStats 翻译统计
-type TranslateStats struct {
-	NormalCount   int
-	TemplateCount int
-	VariableCount int
-}
+import "antigravity_translator/engine"
 
 // normalTranslationsMain main.js 的普通翻译规则
 var normalTranslationsMain = map[string]string{
@@ -28,7 +19,6 @@ var normalTranslationsMain = map[string]string{
 	`"Review Policy"`: `"审核策略"`,
 	`"Auto-Continue"`: `"自动继续"`,
 	`"Conversation History"`: `"会话历史"`,
-	`"Knowledge"`: `"知识库"`,
 	`"Auto-Open Edited Files"`: `"自动打开编辑的文件"`,
 	`"Open Agent on Reload"`: `"重新加载时打开代理"`,
 	`"Enable Sounds for Agent"`: `"启用 Agent 提示音"`,
@@ -49,11 +39,26 @@ var normalTranslationsMain = map[string]string{
 	`"Marketplace Gallery URL"`: `"扩展市场库 URL"`,
 	`"Browser URL Allowlist"`: `"浏览器 URL 允许列表"`,
 	`"[Dev] GCP Project ID"`: `"[开发] GCP 项目 ID"`,
+	`"File Access"`: `"文件访问"`,
+	`"Not signed in"`: `"未登录"`,
+	`"Terms of Service"`: `"服务条款"`,
+	`"Advanced settings"`: `"高级设置"`,
+	`"Editor Settings"`: `"编辑器设置"`,
+	`"Open Editor Settings"`: `"打开编辑器设置"`,
+	`"Notification Settings"`: `"通知设置"`,
+	`"Open System Preferences"`: `"打开系统偏好设置"`,
+	`"Your Plan: "`: `"当前套餐: "`,
+	`return"Always Proceed";default:return"Request Review"`: `return"始终继续";default:return"请求确认"`,
+}
+
+// genericTranslationsMain main 规则包中的通用短词，只对设置页主文件生效:
+// workbench.desktop.main.js 中同样的字符串遍布整个 VS Code 工作台，全局替换会误伤无关界面
+var genericTranslationsMain = map[string]string{
+	`"Knowledge"`: `"知识库"`,
 	`"General"`: `"常规"`,
 	`"Security"`: `"安全性"`,
 	`"Artifact"`: `"工件"`,
 	`"Terminal"`: `"终端"`,
-	`"File Access"`: `"文件访问"`,
 	`"Automation"`: `"自动化"`,
 	`"History"`: `"历史"`,
 	`"Suggestions"`: `"建议"`,
@@ -65,22 +70,23 @@ var normalTranslationsMain = map[string]string{
 	`"Marketplace"`: `"市场"`,
 	`"Email"`: `"邮箱"`,
 	`"Sign out"`: `"退出登录"`,
-	`"Not signed in"`: `"未登录"`,
 	`"Sign in"`: `"登录"`,
-	`"Terms of Service"`: `"服务条款"`,
-	`"Advanced settings"`: `"高级设置"`,
 	`"Add"`: `"添加"`,
-	`"Editor Settings"`: `"编辑器设置"`,
-	`"Open Editor Settings"`: `"打开编辑器设置"`,
-	`"Notification Settings"`: `"通知设置"`,
-	`"Open System Preferences"`: `"打开系统偏好设置"`,
-	`"Your Plan: "`: `"当前套餐: "`,
-	`return"Always Proceed";default:return"Request Review"`: `return"始终继续";default:return"请求确认"`,
 	`"Fast"`: `"快速"`,
 	`"Slow"`: `"慢速"`,
 	`"Disabled"`: `"已禁用"`,
 }
 
+// tabSpeedTranslationsWorkbench workbench.desktop.main.js 中 Tab 补全速度设置的选项:
+// 只替换该设置的说明与下一项设置的说明之间的部分，工作台其他地方的 "Fast"、"Slow" 保持不变
+var tabSpeedTranslationsWorkbench = map[string]string{
+	`"Fast"`: `"快速"`,
+	`"Slow"`: `"慢速"`,
+}
+
+// tabSpeedScope Tab 补全速度设置在 workbench.desktop.main.js 中的范围
+var tabSpeedScope = engine.ForTargets("antigravity.workbench").Between(`"Set the speed of tab suggestions"`, `"Highlight newly inserted text after accepting a Tab completion."`)
+
 // templateTranslationsMain main.js 的模板翻译规则
 var templateTranslationsMain = [][2]string{
 	{`"Always ask for permission"`, `"始终请求权限"`},
@@ -115,56 +121,57 @@ var templateTranslationsMain = [][2]string{
 	{`"Agent auto-executes commands matched by an allow list entry. For Unix shells, an allow list entry matches a command if its space-separated tokens form a prefix of the command's tokens. For PowerShell, the entry tokens may match any contiguous subsequence of the command tokens."`, `"代理会自动执行与允许列表匹配的命令。对于 Unix Shell，允许列表条目通过空格分隔的命令前缀进行匹配；对于 PowerShell，允许列表条目可以匹配命令中任意连续的子序列。"`},
 	{`"Agent asks for permission before executing commands matched by a deny list entry. The deny list follows the same matching rules as the allow list and takes precedence over the allow list."`, `"代理在执行与拒绝列表匹配的命令前会请求您的授权。拒绝列表使用与允许列表相同的匹配规则，且优先级高于允许列表。"`},
 	{`upgradeButtonText||"Upgrade"`, `upgradeButtonText||"升级"`},
-	{"\\u2022 Disabled - Agent will never run Javascript code in the browser.", "\\u2022 已禁用 - 代理永远不会在浏览器中运行 Javascript 代码。"},
-	{"\\u2022 Request Review - Agent will always stop to ask for permission to run Javascript code in the browser.", "\\u2022 请求确认 - 代理在浏览器中运行 Javascript 代码前会始终请求您的许可。"},
-	{"\\u2022 Always Proceed - Agent will not stop to ask for permission to run Javascript in the browser. This provides the Agent with maximum autonomy to perform complex actions and validation in the browser, but also has the highest exposure to security exploits.", "\\u2022 始终继续 - 代理在浏览器中运行 Javascript 时不会请求许可。这为代理提供了在浏览器中执行复杂操作和验证的最大自主权，但也面临最高的安全漏洞风险。"},
 	{`"Path to the Chrome/Chromium executable. Leave empty for auto-detection."`, `"Chrome/Chromium 可执行文件的路径。留空以自动检测。"`},
 	{`"Custom path for the browser user profile directory. Leave empty for default (~/.gemini/antigravity-browser-profile)."`, `"浏览器用户配置文件目录的自定义路径。留空以使用默认值 (~/.gemini/antigravity-browser-profile)。"`},
 	{`"Port number for Chrome DevTools Protocol remote debugging. Leave empty for default (9222)."`, `"Chrome DevTools 协议远程调试的端口号。留空以使用默认值 (9222)。"`},
 }
 
+// escapedTranslationsMain main.js 中按解码后的文本书写的模板翻译规则
+// 打包文件中的 \u2022 等转义写法都能匹配，译文按原文的转义方式重新编码；
+// 只对设置页主文件生效，不必在 30 MB 的 workbench.desktop.main.js 中逐条扫描
+var escapedTranslationsMain = [][2]string{
+	{"• Disabled - Agent will never run Javascript code in the browser.", "• 已禁用 - 代理永远不会在浏览器中运行 Javascript 代码。"},
+	{"• Request Review - Agent will always stop to ask for permission to run Javascript code in the browser.", "• 请求确认 - 代理在浏览器中运行 Javascript 代码前会始终请求您的许可。"},
+	{"• Always Proceed - Agent will not stop to ask for permission to run Javascript in the browser. This provides the Agent with maximum autonomy to perform complex actions and validation in the browser, but also has the highest exposure to security exploits.", "• 始终继续 - 代理在浏览器中运行 Javascript 时不会请求许可。这为代理提供了在浏览器中执行复杂操作和验证的最大自主权，但也面临最高的安全漏洞风险。"},
+}
+
 // variableTranslationsMain main.js 的变量翻译规则 (包含模板字面量)
+// ${$1.name} 等占位符匹配任意表达式，压缩工具每次构建重命名变量后规则仍然有效
 var variableTranslationsMain = [][2]string{
 	{"=`Specifies Agent's behavior when asking for review on artifacts, which are documents it creates to enable a richer conversation experience.\n${", "=`指定 Agent 在请求用户审阅工件时的行为。工件是 Agent 创建的文档，用于提供更丰富的对话体验。\n${"},
-	{"`When toggled on, ${e.product.nameShort} collects usage data to help Google enhance performance and features.`", "`启用后，${e.product.nameShort} 会收集使用数据以帮助 Google 提升性能和功能。`"},
-	{"`When enabled, ${Rje.name} will use the clipboard as context for completions. May increase exposure to security exploits based on unintentional contents in clipboard.`", "`启用后，${Rje.name} 将使用剪贴板内容作为自动补全的上下文。若剪贴板中无意间包含敏感内容，可能会增加遭遇安全利用的风险。`"},
-	{"`When enabled, ${QQe.name} will use the clipboard as context for completions. May increase exposure to security exploits based on unintentional contents in clipboard.`", "`启用后，${QQe.name} 将使用剪贴板内容作为自动补全的上下文。若剪贴板中无意间包含敏感内容，可能会增加遭遇安全利用的风险。`"},
-	{"`Changes the base URL on each extension page. You must restart ${e.nameShort} to use the new marketplace after changing this value.`", "`更改每个扩展页面的基础 URL。修改此值后，您必须重启 ${e.nameShort} 才能使用新的扩展市场。`"},
-	{"`Changes the base URL for marketplace search results. You must restart ${e.nameShort} to use the new marketplace after changing this value.`", "`更改扩展市场搜索结果的基础 URL。修改此值后，您必须重启 ${e.nameShort} 才能使用新的扩展市场。`"},
-	{"`\\u2022 Always Proceed - Agent never asks for confirmation before executing terminal commands (except those in the Deny list). This provides the Agent with the maximum ability to operate over long periods without intervention, but also has the highest risk of an Agent executing an unsafe terminal command.\n        \\u2022 Request Review - Agent always asks for confirmation before executing terminal commands (except those in the Allow list).\n\n        Note: A change to this setting will only apply to new messages sent to Agent. In-progress responses will use the previous setting value.\n        `", "`\\u2022 始终继续 - 代理在执行终端命令之前从不请求确认（拒绝列表中的除外）。这为代理提供了长时间无干预运作的最大能力，但也存在代理执行不安全终端命令的最高风险。\n        \\u2022 请求确认 - 代理在执行终端命令之前始终请求确认（允许列表中的除外）。\n\n        注意：此设置的更改仅适用于发送给代理的新消息。正在进行的响应将使用之前的设置值。\n        `"},
+	{"`When toggled on, ${$1.product.nameShort} collects usage data to help Google enhance performance and features.`", "`启用后，${$1.product.nameShort} 会收集使用数据以帮助 Google 提升性能和功能。`"},
+	{"`When enabled, ${$1.name} will use the clipboard as context for completions. May increase exposure to security exploits based on unintentional contents in clipboard.`", "`启用后，${$1.name} 将使用剪贴板内容作为自动补全的上下文。若剪贴板中无意间包含敏感内容，可能会增加遭遇安全利用的风险。`"},
+	{"`Changes the base URL on each extension page. You must restart ${$1.nameShort} to use the new marketplace after changing this value.`", "`更改每个扩展页面的基础 URL。修改此值后，您必须重启 ${$1.nameShort} 才能使用新的扩展市场。`"},
+	{"`Changes the base URL for marketplace search results. You must restart ${$1.nameShort} to use the new marketplace after changing this value.`", "`更改扩展市场搜索结果的基础 URL。修改此值后，您必须重启 ${$1.nameShort} 才能使用新的扩展市场。`"},
 	{"'When enabled, \"Explain and Fix\" actions will continue in the current conversation instead of starting a new one.'", "'启用后，\"解释并修复\"操作将在当前对话中继续进行，而不会另起新对话。'"},
 	{"'When enabled, your UI will be slightly modified to ensure more consistent demos. This is only recommended for demo purposes. In most cases, you can run \"Antigravity: Start Demo Mode\" and \"Antigravity: Stop Demo Mode\" to control this switch and update your ~/.gemini/antigravity data directory.'", "'启用后，界面将进行微调以确保演示效果更加一致。此选项仅建议在演示场景下使用。通常情况下，你可以运行 \"Antigravity: Start Demo Mode\" 和 \"Antigravity: Stop Demo Mode\" 来控制此开关并更新你的 ~/.gemini/antigravity 数据目录。'"},
 }
 
-// applyMainTranslations 应用 main.js 的翻译规则
-func applyMainTranslations(content string) (string, TranslateStats) {
-	stats := TranslateStats{}
-
-	// 1. 应用普通翻译
-	for from, to := range normalTranslationsMain {
-		if strings.Contains(content, from) {
-			content = strings.ReplaceAll(content, from, to)
-			stats.NormalCount++
-		}
-	}
-
-	// 2. 应用模板翻译
-	for _, pair := range templateTranslationsMain {
-		from, to := pair[0], pair[1]
-		if strings.Contains(content, from) {
-			content = strings.ReplaceAll(content, from, to)
-			stats.TemplateCount++
-		}
-	}
-
-	// 3. 应用变量翻译
-	for _, pair := range variableTranslationsMain {
-		from, to := pair[0], pair[1]
-		if strings.Contains(content, from) {
-			content = strings.ReplaceAll(content, from, to)
-			stats.VariableCount++
-		}
-	}
+// whitespaceTranslationsMain main.js 中忽略空白差异的变量翻译规则 (多行模板字面量)，只对设置页主文件生效
+// 原文的换行和缩进匹配任意空白，译文中的 ${~} 依次保留原文中的换行和缩进；同时按解码后的文本匹配 (• 对应 \u2022)
+var whitespaceTranslationsMain = [][2]string{
+	{"`• Always Proceed - Agent never asks for confirmation before executing terminal commands (except those in the Deny list). This provides the Agent with the maximum ability to operate over long periods without intervention, but also has the highest risk of an Agent executing an unsafe terminal command.\n        • Request Review - Agent always asks for confirmation before executing terminal commands (except those in the Allow list).\n\n        Note: A change to this setting will only apply to new messages sent to Agent. In-progress responses will use the previous setting value.\n        `", "`• 始终继续 - 代理在执行终端命令之前从不请求确认（拒绝列表中的除外）。这为代理提供了长时间无干预运作的最大能力，但也存在代理执行不安全终端命令的最高风险。${~}• 请求确认 - 代理在执行终端命令之前始终请求确认（允许列表中的除外）。${~}注意：此设置的更改仅适用于发送给代理的新消息。正在进行的响应将使用之前的设置值。${~}`"},
+}
 
-	return content, stats
+// Main main.js 与 workbench.desktop.main.js 的规则包
+var Main = &engine.RuleSet{
+	Name: "main",
+	Phases: []*engine.Phase{
+		// 1. 普通翻译
+		engine.NewPhase("normal", engine.CategoryNormal, func() []engine.Rule {
+			rules := append(engine.RulesFromMap("normal", normalTranslationsMain),
+				engine.WithScope(engine.ForTargets("antigravity.main"), engine.RulesFromMap("normal", genericTranslationsMain))...)
+			return append(rules, engine.WithScope(tabSpeedScope, engine.RulesFromMap("normal", tabSpeedTranslationsWorkbench))...)
+		}),
+		// 2. 模板翻译
+		engine.NewPhase("template", engine.CategoryTemplate, func() []engine.Rule {
+			return append(engine.RulesFromPairs("template", templateTranslationsMain),
+				engine.WithScope(engine.ForTargets("antigravity.main"), engine.WithMode(engine.MatchEscapes, engine.RulesFromPairs("template", escapedTranslationsMain)))...)
+		}),
+		// 3. 变量翻译
+		engine.NewPhase("variable", engine.CategoryVariable, func() []engine.Rule {
+			return append(engine.RulesFromPairs("variable", variableTranslationsMain),
+				engine.WithScope(engine.ForTargets("antigravity.main"), engine.WithMode(engine.MatchWhitespace|engine.MatchEscapes, engine.RulesFromPairs("variable", whitespaceTranslationsMain)))...)
+		}),
+	},
 }
@@ -0,0 +1,107 @@
+package rules
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"antigravity_translator/engine"
+)
+
+// ========================================
+// 用户规则文件
+// ========================================
+//
+// 用户规则保存在配置目录下的 JSON 文件中 (如 rules stale --accept 接受的建议)，不需要重新编译程序。
+// 每条规则属于一个规则包，在该规则包之前应用，因此可以覆盖内置规则。
+
+// UserRule 用户规则文件中的一条规则 (原文和译文都是含引号的完整字符串字面量)
+type UserRule struct {
+	Pack     string `json:"pack"`               // 所属规则包，如 "chat"
+	From     string `json:"from"`               // 原文
+	To       string `json:"to"`                 // 译文
+	Replaces string `json:"replaces,omitempty"` // 被取代的 (已失效的) 内置规则原文
+}
+
+// UserRules 用户规则文件
+type UserRules struct {
+	Rules []UserRule `json:"rules"`
+
+	mu   sync.Mutex
+	sets map[string]*engine.RuleSet // 按规则包缓存的规则集
+}
+
+// LoadUserRules 读取用户规则文件，文件不存在时返回空的规则文件
+func LoadUserRules(path string) (*UserRules, error) {
+	u := &UserRules{Rules: []UserRule{}}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return u, nil
+	}
+	if err != nil {
+		return u, err
+	}
+	if err := json.Unmarshal(content, u); err != nil {
+		return &UserRules{Rules: []UserRule{}}, err
+	}
+	return u, nil
+}
+
+// Save 写入用户规则文件
+func (u *UserRules) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Add 添加一条规则，同一规则包中原文相同的规则被替换
+func (u *UserRules) Add(rule UserRule) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.sets = nil
+	for i, r := range u.Rules {
+		if r.Pack == rule.Pack && r.From == rule.From {
+			u.Rules[i] = rule
+			return
+		}
+	}
+	u.Rules = append(u.Rules, rule)
+}
+
+// RuleSet 返回某个规则包的用户规则 (一个 "user" 阶段)，没有用户规则时返回 nil
+func (u *UserRules) RuleSet(pack string) *engine.RuleSet {
+	if u == nil {
+		return nil
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if set, ok := u.sets[pack]; ok {
+		return set
+	}
+	var pairs [][2]string
+	for _, r := range u.Rules {
+		if r.Pack == pack {
+			pairs = append(pairs, [2]string{r.From, r.To})
+		}
+	}
+	var set *engine.RuleSet
+	if len(pairs) > 0 {
+		set = &engine.RuleSet{
+			Name: pack,
+			Phases: []*engine.Phase{
+				engine.NewPhase("user", engine.CategoryNormal, func() []engine.Rule { return engine.RulesFromPairs("user", pairs) }),
+			},
+		}
+	}
+	if u.sets == nil {
+		u.sets = make(map[string]*engine.RuleSet)
+	}
+	u.sets[pack] = set
+	return set
+}
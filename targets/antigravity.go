@@ -0,0 +1,248 @@
+package targets
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// ========================================
+// Antigravity 安装发现
+// ========================================
+
+// Installation 一个 Antigravity 安装
+type Installation struct {
+	Path        string `json:"path"`
+	Version     string `json:"version"`      // resources/app/package.json 中的版本号
+	Channel     string `json:"channel"`      // 发布渠道 ("stable", "preview", ...)
+	InstallType string `json:"install_type"` // 安装类型 ("user", "system", "portable")
+	Source      string `json:"source"`       // 发现来源 ("config", "registry", "common")
+}
+
+// FindInstallations 检测所有 Antigravity 安装
+// 顺序: remembered (记住的路径，为空时跳过) > 注册表 > 常见安装位置
+func FindInstallations(ctx context.Context, remembered string) []Installation {
+	var installs []Installation
+	seen := make(map[string]bool)
+
+	add := func(path, source, installType string) {
+		if path == "" || ctx.Err() != nil || !ValidateInstallPath(path) {
+			return
+		}
+		key := filepath.Clean(path)
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		installs = append(installs, DescribeInstallation(path, source, installType))
+	}
+
+	// 1. 记住的路径
+	add(remembered, "config", "")
+
+	// 2. 注册表
+	for _, r := range findAntigravityFromRegistry() {
+		add(r.path, "registry", r.installType)
+	}
+
+	// 3. 常见安装位置
+	for _, path := range commonInstallLocations() {
+		add(path, "common", "")
+	}
+
+	return installs
+}
+
+// DescribeInstallation 读取安装目录中的版本、渠道信息并判断安装类型
+func DescribeInstallation(path, source, installType string) Installation {
+	inst := Installation{Path: path, Source: source, Channel: "stable", InstallType: installType}
+
+	appDir := filepath.Join(path, "resources", "app")
+
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if content, err := os.ReadFile(filepath.Join(appDir, "package.json")); err == nil {
+		json.Unmarshal(content, &pkg)
+	}
+	inst.Version = pkg.Version
+
+	var product struct {
+		Version   string `json:"version"`
+		Quality   string `json:"quality"`
+		NameShort string `json:"nameShort"`
+	}
+	if content, err := os.ReadFile(filepath.Join(appDir, "product.json")); err == nil {
+		json.Unmarshal(content, &product)
+	}
+	if inst.Version == "" {
+		inst.Version = product.Version
+	}
+	switch {
+	case product.Quality != "":
+		inst.Channel = product.Quality
+	case strings.Contains(strings.ToLower(product.NameShort), "preview"):
+		inst.Channel = "preview"
+	case strings.Contains(strings.ToLower(product.NameShort), "insider"):
+		inst.Channel = "insider"
+	}
+
+	if inst.InstallType == "" {
+		inst.InstallType = classifyInstallType(path)
+	}
+	return inst
+}
+
+// classifyInstallType 根据安装位置判断安装类型
+func classifyInstallType(path string) string {
+	// 便携模式: 安装目录下存在 data 目录
+	if info, err := os.Stat(filepath.Join(path, "data")); err == nil && info.IsDir() {
+		return "portable"
+	}
+
+	lower := strings.ToLower(path)
+	if homeDir, err := os.UserHomeDir(); err == nil && strings.HasPrefix(lower, strings.ToLower(homeDir)) {
+		return "user"
+	}
+	for _, env := range []string{"ProgramFiles", "ProgramFiles(x86)"} {
+		if dir := os.Getenv(env); dir != "" && strings.HasPrefix(lower, strings.ToLower(dir)) {
+			return "system"
+		}
+	}
+	if strings.Contains(lower, "program files") {
+		return "system"
+	}
+	return "portable"
+}
+
+// commonInstallLocations 常见安装位置
+func commonInstallLocations() []string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		// 用户目录安装 (最常见)
+		filepath.Join(homeDir, "AppData", "Local", "Programs", "Antigravity"),
+		filepath.Join(homeDir, "AppData", "Local", "Antigravity"),
+		// 系统目录安装
+		"C:\\Program Files\\Antigravity",
+		"C:\\Program Files (x86)\\Antigravity",
+		// 其他常见位置
+		"D:\\Antigravity",
+		"D:\\Program Files\\Antigravity",
+		"E:\\Antigravity",
+	}
+}
+
+// ValidateInstallPath 检查路径是否为 Antigravity 安装目录 (包含 resources/app 目录)
+func ValidateInstallPath(path string) bool {
+	// 检查路径是否存在
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false
+	}
+
+	// 检查是否包含 resources/app 目录
+	resourcesPath := filepath.Join(path, "resources", "app")
+	if _, err := os.Stat(resourcesPath); os.IsNotExist(err) {
+		return false
+	}
+
+	return true
+}
+
+// registryInstall 注册表中找到的安装路径
+type registryInstall struct {
+	path        string
+	installType string // HKCU 为 "user"，HKLM 为 "system"
+}
+
+// findAntigravityFromRegistry 从 Windows 注册表查询所有 Antigravity 安装路径
+func findAntigravityFromRegistry() []registryInstall {
+	// 注册表查询位置
+	registryPaths := []string{
+		// 用户安装的程序
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Uninstall`,
+		// 系统安装的程序 (64位)
+		`HKLM\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`,
+		// 系统安装的程序 (32位 on 64位系统)
+		`HKLM\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`,
+	}
+
+	// 收集所有有效路径
+	var validPaths []registryInstall
+
+	for _, regPath := range registryPaths {
+		installType := "system"
+		if strings.HasPrefix(regPath, "HKCU") {
+			installType = "user"
+		}
+
+		// 使用 reg query 命令查询注册表
+		cmd := exec.Command("reg", "query", regPath, "/s", "/f", "Antigravity", "/d")
+		output, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+
+		// 解析输出
+		lines := strings.Split(string(output), "\n")
+
+		for _, line := range lines {
+			line = strings.TrimSpace(line)
+
+			// 查找 InstallLocation
+			if strings.Contains(line, "InstallLocation") && strings.Contains(line, "REG_SZ") {
+				parts := strings.SplitN(line, "REG_SZ", 2)
+				if len(parts) == 2 {
+					path := cleanRegistryPath(parts[1])
+					if path != "" && ValidateInstallPath(path) {
+						validPaths = append(validPaths, registryInstall{path, installType})
+					}
+				}
+			}
+
+			// 查找 DisplayIcon (通常指向 exe 文件)
+			if strings.Contains(line, "DisplayIcon") && strings.Contains(line, "REG_SZ") {
+				parts := strings.SplitN(line, "REG_SZ", 2)
+				if len(parts) == 2 {
+					iconPath := cleanRegistryPath(parts[1])
+					// 移除可能的逗号和图标索引
+					if idx := strings.Index(iconPath, ","); idx > 0 {
+						iconPath = iconPath[:idx]
+					}
+					// 获取目录路径
+					dir := filepath.Dir(iconPath)
+					if dir != "" && ValidateInstallPath(dir) {
+						validPaths = append(validPaths, registryInstall{dir, installType})
+					}
+				}
+			}
+		}
+	}
+
+	// 路径较短的排在前面（通常是主程序而不是子工具）
+	sort.SliceStable(validPaths, func(i, j int) bool {
+		return len(validPaths[i].path) < len(validPaths[j].path)
+	})
+
+	return validPaths
+}
+
+// cleanRegistryPath 清理注册表返回的路径
+func cleanRegistryPath(path string) string {
+	path = strings.TrimSpace(path)
+	// 移除引号
+	path = strings.Trim(path, "\"")
+	// 移除尾部反斜杠
+	path = strings.TrimSuffix(path, "\\")
+	return path
+}
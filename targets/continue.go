@@ -0,0 +1,440 @@
+package targets
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"antigravity_translator/rules"
+)
+
+// ========================================
+// Continue 扩展发现
+// ========================================
+
+// continueMarkerThreshold 资源文件至少包含多少个不同的标记字符串才视为 Continue 界面代码，
+// 避免 "Error"、"Home" 这类常见文本把第三方库的分块也选进来
+const continueMarkerThreshold = 3
+
+// IsContinueGUIChunk 判断资源文件内容是否为 Continue 界面代码
+func IsContinueGUIChunk(content string) bool {
+	found := 0
+	for _, marker := range rules.ContinueMarkers() {
+		if strings.Contains(content, marker) {
+			found++
+			if found >= continueMarkerThreshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LocateContinueGUIChunks 枚举 gui/assets 下的所有 JS 资源 (包括 Vite 生成的 index-AbC123.js
+// 和按路由拆分的懒加载分块)，返回包含 Continue 界面文本的文件
+func LocateContinueGUIChunks(ctx context.Context, root string) ([]string, error) {
+	assetsDir := filepath.Join(root, "gui", "assets")
+	if _, err := os.Stat(assetsDir); err != nil {
+		return nil, nil
+	}
+
+	var chunks []string
+	err := filepath.WalkDir(assetsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if ext != ".js" && ext != ".mjs" {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		if IsContinueGUIChunk(string(content)) {
+			chunks = append(chunks, path)
+		}
+		return nil
+	})
+	sort.Strings(chunks)
+	return chunks, err
+}
+
+// continueExtensionPrefix Continue 扩展在编辑器扩展目录下的目录名前缀
+const continueExtensionPrefix = "continue.continue-"
+
+// continuePlatforms Continue 发布的平台相关版本在目录名末尾附加的平台后缀
+var continuePlatforms = []string{
+	"win32-x64", "win32-arm64",
+	"linux-x64", "linux-arm64", "linux-armhf", "alpine-x64", "alpine-arm64",
+	"darwin-x64", "darwin-arm64",
+}
+
+// ContinueExtension 一个已安装的 Continue 扩展
+type ContinueExtension struct {
+	Path     string   `json:"path"`
+	Version  string   `json:"version"`
+	Platform string   `json:"platform,omitempty"` // 平台后缀，通用版本为空
+	Editor   string   `json:"editor,omitempty"`   // 所属编辑器 ("VS Code"、"Cursor"、...)
+	Obsolete []string `json:"obsolete,omitempty"` // 同一扩展目录下残留的旧版本目录
+}
+
+// ParseContinueDirName 把 "continue.continue-1.2.3-win32-x64" 解析为版本号和平台后缀
+func ParseContinueDirName(name string) (version, platform string, ok bool) {
+	if !strings.HasPrefix(name, continueExtensionPrefix) {
+		return "", "", false
+	}
+	rest := strings.TrimPrefix(name, continueExtensionPrefix)
+	for _, p := range continuePlatforms {
+		if strings.HasSuffix(rest, "-"+p) {
+			rest, platform = strings.TrimSuffix(rest, "-"+p), p
+			break
+		}
+	}
+	if _, ok := parseSemver(rest); !ok {
+		return "", "", false
+	}
+	return rest, platform, true
+}
+
+// currentPlatform 返回当前系统对应的扩展平台后缀
+func currentPlatform() string {
+	goos := runtime.GOOS
+	if goos == "windows" {
+		goos = "win32"
+	}
+	arch := map[string]string{"amd64": "x64", "arm64": "arm64", "arm": "armhf"}[runtime.GOARCH]
+	return goos + "-" + arch
+}
+
+// semver 语义化版本号 (只比较 major.minor.patch 和预发布标识)
+type semver struct {
+	nums [3]int
+	pre  string
+}
+
+// parseSemver 解析 "1.2.3" 或 "1.2.3-beta.1" 形式的版本号
+func parseSemver(v string) (semver, bool) {
+	var s semver
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		if v[i] == '-' {
+			s.pre = v[i+1:]
+			if j := strings.IndexByte(s.pre, '+'); j >= 0 {
+				s.pre = s.pre[:j]
+			}
+		}
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return s, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return s, false
+		}
+		s.nums[i] = n
+	}
+	return s, true
+}
+
+// CompareVersions 按语义化版本比较 a 和 b，返回 -1、0 或 1
+func CompareVersions(a, b string) int {
+	va, _ := parseSemver(a)
+	vb, _ := parseSemver(b)
+	for i := range va.nums {
+		if va.nums[i] != vb.nums[i] {
+			if va.nums[i] < vb.nums[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// 正式版本高于同号的预发布版本
+	switch {
+	case va.pre == vb.pre:
+		return 0
+	case va.pre == "":
+		return 1
+	case vb.pre == "":
+		return -1
+	default:
+		return comparePrerelease(va.pre, vb.pre)
+	}
+}
+
+// comparePrerelease 按语义化版本 §11 比较预发布标识: 逐段比较，纯数字的段按数值比较且低于非数字段，
+// 其余按 ASCII 顺序比较，前面各段相同时段数多的版本较高
+func comparePrerelease(a, b string) int {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		if c := compareIdentifier(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(pa) < len(pb):
+		return -1
+	case len(pa) > len(pb):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier 比较预发布标识中的一段
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// installedContinueDir 从编辑器的 extensions.json 中读取当前启用的 Continue 扩展目录名
+func installedContinueDir(extensionsDir string) string {
+	content, err := os.ReadFile(filepath.Join(extensionsDir, "extensions.json"))
+	if err != nil {
+		return ""
+	}
+	var entries []struct {
+		Identifier struct {
+			ID string `json:"id"`
+		} `json:"identifier"`
+		RelativeLocation string `json:"relativeLocation"`
+		Location         struct {
+			Path   string `json:"path"`
+			FsPath string `json:"fsPath"`
+		} `json:"location"`
+	}
+	if json.Unmarshal(content, &entries) != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if !strings.EqualFold(e.Identifier.ID, "continue.continue") {
+			continue
+		}
+		switch {
+		case e.RelativeLocation != "":
+			return e.RelativeLocation
+		case e.Location.FsPath != "":
+			return filepath.Base(e.Location.FsPath)
+		case e.Location.Path != "":
+			return path.Base(e.Location.Path)
+		}
+	}
+	return ""
+}
+
+// obsoleteExtensionDirs 读取编辑器标记为待删除的扩展目录 (.obsolete)
+func obsoleteExtensionDirs(extensionsDir string) map[string]bool {
+	obsolete := make(map[string]bool)
+	if content, err := os.ReadFile(filepath.Join(extensionsDir, ".obsolete")); err == nil {
+		json.Unmarshal(content, &obsolete)
+	}
+	return obsolete
+}
+
+// ScanContinueExtensions 在编辑器扩展目录下选择要汉化的 Continue 扩展
+// 优先使用 extensions.json 中登记的版本，否则选择当前平台 (或通用版本) 中语义化版本最高的一个；
+// 其余版本记入 Obsolete
+func ScanContinueExtensions(extensionsDir string) *ContinueExtension {
+	entries, err := os.ReadDir(extensionsDir)
+	if err != nil {
+		return nil
+	}
+
+	obsolete := obsoleteExtensionDirs(extensionsDir)
+	installed := installedContinueDir(extensionsDir)
+	platform := currentPlatform()
+
+	var candidates []ContinueExtension
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, p, ok := ParseContinueDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, ContinueExtension{
+			Path:     filepath.Join(extensionsDir, entry.Name()),
+			Version:  version,
+			Platform: p,
+			Editor:   editorForRoot(extensionsDir),
+		})
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	best := -1
+	for i, c := range candidates {
+		name := filepath.Base(c.Path)
+		if name == installed {
+			best = i
+			break
+		}
+		if obsolete[name] || (c.Platform != "" && c.Platform != platform) {
+			continue
+		}
+		if best < 0 || CompareVersions(c.Version, candidates[best].Version) > 0 {
+			best = i
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	ext := candidates[best]
+	for i, c := range candidates {
+		if i != best {
+			ext.Obsolete = append(ext.Obsolete, c.Path)
+		}
+	}
+	sort.Strings(ext.Obsolete)
+	return &ext
+}
+
+// editorExtensionDirs 各编辑器扩展目录 (相对用户目录) 及对应的编辑器名称
+var editorExtensionDirs = []struct {
+	Dir    string
+	Editor string
+}{
+	{".antigravity/extensions", "Antigravity"},
+	{".vscode/extensions", "VS Code"},
+	{".vscode-insiders/extensions", "VS Code Insiders"},
+	{".vscode-oss/extensions", "VSCodium"},
+	{".cursor/extensions", "Cursor"},
+	{".windsurf/extensions", "Windsurf"},
+	{".vscode-server/extensions", "VS Code Remote-SSH"},
+	{".vscode-server-insiders/extensions", "VS Code Insiders Remote-SSH"},
+	{".cursor-server/extensions", "Cursor Remote-SSH"},
+	{".windsurf-server/extensions", "Windsurf Remote-SSH"},
+}
+
+// DefaultExtensionRoots 默认扫描的编辑器扩展目录 (以 ~ 开头，使用前需 ExpandHome)
+func DefaultExtensionRoots() []string {
+	var roots []string
+	for _, d := range editorExtensionDirs {
+		roots = append(roots, "~/"+d.Dir)
+	}
+	return roots
+}
+
+// ExpandHome 展开路径开头的 ~
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~\\") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, filepath.FromSlash(strings.TrimPrefix(path[1:], "\\")))
+}
+
+// editorForRoot 根据扩展目录推断所属编辑器，无法识别时返回空
+func editorForRoot(root string) string {
+	slashed := filepath.ToSlash(filepath.Clean(root))
+	for _, d := range editorExtensionDirs {
+		if strings.HasSuffix(slashed, "/"+d.Dir) {
+			return d.Editor
+		}
+	}
+	return ""
+}
+
+// FindContinueExtensions 在编辑器扩展目录中查找 Continue 扩展
+// 顺序: remembered (记住的扩展目录，为空时跳过) > roots 中各目录 (为空时使用 DefaultExtensionRoots)
+func FindContinueExtensions(ctx context.Context, remembered string, roots []string) []*ContinueExtension {
+	var found []*ContinueExtension
+	seen := make(map[string]bool)
+
+	add := func(ext *ContinueExtension) {
+		key := filepath.Clean(ext.Path)
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		found = append(found, ext)
+	}
+
+	// 1. 记住的扩展目录
+	if remembered != "" {
+		if _, err := os.Stat(filepath.Join(remembered, "gui", "assets")); err == nil {
+			ext := &ContinueExtension{Path: remembered, Editor: editorForRoot(filepath.Dir(remembered))}
+			ext.Version, ext.Platform, _ = ParseContinueDirName(filepath.Base(remembered))
+			add(ext)
+		}
+	}
+
+	// 2. 各编辑器的扩展目录
+	if len(roots) == 0 {
+		roots = DefaultExtensionRoots()
+	}
+	for _, root := range roots {
+		if ctx.Err() != nil {
+			break
+		}
+		if ext := ScanContinueExtensions(ExpandHome(root)); ext != nil {
+			add(ext)
+		}
+	}
+	return found
+}
+
+// ObsoleteContinueVersions 返回与 dir 位于同一扩展目录、未被编辑器使用的其他 Continue 版本
+// dir 本身不是被选中的版本时返回空
+func ObsoleteContinueVersions(dir string) []string {
+	ext := ScanContinueExtensions(filepath.Dir(dir))
+	if ext == nil || !SamePath(ext.Path, dir) {
+		return nil
+	}
+	return ext.Obsolete
+}
+
+// SamePath 判断两个路径是否指向同一位置 (Windows 下不区分大小写)
+func SamePath(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// ContinueRoot 解析用户输入的路径: 可以是扩展目录，也可以是 gui/assets 下的某个文件 (如 index.js)
+func ContinueRoot(input string) string {
+	if info, err := os.Stat(input); err == nil && !info.IsDir() {
+		return filepath.Dir(filepath.Dir(filepath.Dir(input)))
+	}
+	return input
+}
@@ -0,0 +1,29 @@
+package targets
+
+import "testing"
+
+// TestCompareVersions 预发布标识按语义化版本 §11 比较
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.0", "1.2.0", 0},
+		{"1.2.0", "1.10.0", -1},
+		{"1.2.0-beta.1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-beta.1", 1},
+		{"1.2.0-beta.9", "1.2.0-beta.10", -1},
+		{"1.2.0-beta.10", "1.2.0-beta.9", 1},
+		{"1.2.0-alpha", "1.2.0-alpha.1", -1},
+		{"1.2.0-alpha.1", "1.2.0-alpha.beta", -1},
+		{"1.2.0-alpha.beta", "1.2.0-beta", -1},
+		{"1.2.0-beta.11", "1.2.0-rc.1", -1},
+		{"1.2.0-rc.1+build.5", "1.2.0-rc.1", 0},
+		{"1.2.0-2", "1.2.0-10", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package targets 定义可汉化的目标文件，并负责发现 Antigravity 安装和 Continue 扩展。
+//
+// 每个目标 (Target) 属于一个分组 ("antigravity" 或 "continue")，通过 Locator 在分组的
+// 根目录 (Antigravity 安装目录或 Continue 扩展目录) 下定位文件，并声明要应用的规则包。
+package targets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+)
+
+// ========================================
+// 汉化目标注册表
+// ========================================
+
+// Target 一个可汉化的目标
+type Target struct {
+	ID          string   // 唯一标识，如 "antigravity.chat"
+	Description string   // 显示名称
+	Group       string   // 备份分组 ("antigravity" 或 "continue")，同组目标共用一个根目录和一条备份记录
+	Locator     Locator  // 在根目录下定位目标文件
+	RulePacks   []string // 依次应用的规则包
+	ChecksumKey string   // product.json 中需要移除的校验和键，为空表示不处理校验和
+}
+
+// Locator 在根目录 (安装目录或扩展目录) 下定位目标文件
+type Locator interface {
+	Locate(ctx context.Context, root string) ([]string, error)
+	String() string
+}
+
+// PathLocator 固定的相对路径 (使用 / 分隔)
+type PathLocator string
+
+func (l PathLocator) Locate(ctx context.Context, root string) ([]string, error) {
+	path := filepath.Join(root, filepath.FromSlash(string(l)))
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil
+	}
+	return []string{path}, nil
+}
+
+func (l PathLocator) String() string { return filepath.FromSlash(string(l)) }
+
+// GlobLocator 相对路径通配符 (filepath.Match 语法，使用 / 分隔)
+type GlobLocator string
+
+func (l GlobLocator) Locate(ctx context.Context, root string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(string(l))))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+func (l GlobLocator) String() string { return filepath.FromSlash(string(l)) }
+
+// FuncLocator 自定义查找函数
+type FuncLocator struct {
+	Name string
+	Fn   func(ctx context.Context, root string) ([]string, error)
+}
+
+func (l FuncLocator) Locate(ctx context.Context, root string) ([]string, error) {
+	return l.Fn(ctx, root)
+}
+
+func (l FuncLocator) String() string { return l.Name }
+
+// registry 已注册的目标，按注册顺序处理
+var registry []*Target
+
+// Register 注册一个汉化目标，ID 重复或使用了未知的规则包时 panic
+func Register(t *Target) {
+	if Lookup(t.ID) != nil {
+		panic("重复注册的汉化目标: " + t.ID)
+	}
+	for _, pack := range t.RulePacks {
+		if rules.Lookup(pack) == nil {
+			panic(fmt.Sprintf("汉化目标 %s 使用了未知的规则包: %s", t.ID, pack))
+		}
+	}
+	registry = append(registry, t)
+}
+
+// RuleSets 返回目标使用的规则包
+func (t *Target) RuleSets() []*engine.RuleSet {
+	sets := make([]*engine.RuleSet, 0, len(t.RulePacks))
+	for _, pack := range t.RulePacks {
+		sets = append(sets, rules.Lookup(pack))
+	}
+	return sets
+}
+
+func init() {
+	Register(&Target{
+		ID:          "antigravity.main",
+		Description: "设置页 (主文件)",
+		Group:       "antigravity",
+		Locator:     PathLocator("resources/app/out/jetskiAgent/main.js"),
+		RulePacks:   []string{"main"},
+		ChecksumKey: "jetskiAgent/main.js",
+	})
+	Register(&Target{
+		ID:          "antigravity.workbench",
+		Description: "设置页 (工作台)",
+		Group:       "antigravity",
+		Locator:     PathLocator("resources/app/out/vs/workbench/workbench.desktop.main.js"),
+		RulePacks:   []string{"main"},
+		ChecksumKey: "vs/workbench/workbench.desktop.main.js",
+	})
+	Register(&Target{
+		ID:          "antigravity.chat",
+		Description: "聊天页",
+		Group:       "antigravity",
+		Locator:     PathLocator("resources/app/extensions/antigravity/out/media/chat.js"),
+		RulePacks:   []string{"chat"},
+	})
+	Register(&Target{
+		ID:          "continue.gui",
+		Description: "Continue 扩展",
+		Group:       "continue",
+		Locator:     FuncLocator{Name: "gui/assets/*.js (含 Continue 界面文本)", Fn: LocateContinueGUIChunks},
+		RulePacks:   []string{"continue"},
+	})
+}
+
+// All 返回所有已注册的目标
+func All() []*Target {
+	return append([]*Target(nil), registry...)
+}
+
+// Lookup 按 ID 查找目标
+func Lookup(id string) *Target {
+	for _, t := range registry {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// InGroup 返回某个分组的所有目标
+func InGroup(group string) []*Target {
+	var targets []*Target
+	for _, t := range registry {
+		if t.Group == group {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// ForFile 根据文件路径 (安装目录下的相对路径后缀，或备份中的同名文件) 和内容推断文件所属的目标，
+// 无法推断时返回 nil。用于比较不在安装目录中的 bundle (如备份或另存的副本)
+func ForFile(path string, content []byte) *Target {
+	slashed := filepath.ToSlash(path)
+	for _, t := range registry {
+		if l, ok := t.Locator.(PathLocator); ok && strings.HasSuffix(slashed, "/"+string(l)) {
+			return t
+		}
+	}
+	for _, t := range registry {
+		if l, ok := t.Locator.(PathLocator); ok && filepath.Base(path) == filepath.Base(l.String()) {
+			return t
+		}
+	}
+	if filepath.Ext(path) == ".js" && IsContinueGUIChunk(string(content)) {
+		return Lookup("continue.gui")
+	}
+	return nil
+}
+
+// File 定位到的目标文件
+type File struct {
+	Target *Target
+	Path   string
+}
+
+// Locate 在根目录下定位某个分组的所有目标文件，单个目标定位失败时跳过
+// ctx 取消时返回 ctx.Err()
+func Locate(ctx context.Context, group, root string) ([]File, error) {
+	var found []File
+	for _, t := range InGroup(group) {
+		paths, err := t.Locator.Locate(ctx, root)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err != nil {
+			continue
+		}
+		for _, p := range paths {
+			found = append(found, File{Target: t, Path: p})
+		}
+	}
+	return found, nil
+}
+
+// ChecksumKeys 返回目标文件对应的 product.json 校验和键 (去重)
+func ChecksumKeys(files []File) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if f.Target.ChecksumKey == "" || seen[f.Target.ChecksumKey] {
+			continue
+		}
+		seen[f.Target.ChecksumKey] = true
+		keys = append(keys, f.Target.ChecksumKey)
+	}
+	return keys
+}
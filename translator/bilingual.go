@@ -0,0 +1,180 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/engine"
+	"antigravity_translator/literals"
+)
+
+// ========================================
+// 双语显示
+// ========================================
+//
+// 设置了 Translator.Bilingual 时，汉化使用的规则集换成双语版本 (见 engine.Rule.Bilingual)，
+// 可以只对某些类别的规则生效。规则的类别包括所在的阶段类型 (normal、template、anchored 等)
+// 和按原文推断的用途:
+//   - error: 错误提示 (含 error、failed、invalid、unable 等词)
+//   - description: 说明文字 (8 个词以上，或以句号、问号、感叹号结尾)
+//   - title: 标题 (如设置项的名称 "Always Proceed"，各个实词首字母大写)
+//   - label: 其他短文本 (按钮、选项等)
+//
+// 汉化时的设置写入替换记录和备份记录。untranslate、revert、explain 和汉化前判断文件是否已经汉化过时，
+// 按文件中译文实际使用的设置匹配 (见 appliedBilingual)，而不是当前的配置项或 --bilingual。
+
+// 按原文推断的规则用途
+const (
+	CategoryError       = "error"
+	CategoryDescription = "description"
+	CategoryTitle       = "title"
+	CategoryLabel       = "label"
+)
+
+// RuleCategories 按原文推断的规则用途
+var RuleCategories = []string{CategoryError, CategoryDescription, CategoryTitle, CategoryLabel}
+
+// DefaultBilingualMaxLength 双语显示时追加原文的最大长度 (字符数)，更长的说明文字只显示译文
+const DefaultBilingualMaxLength = 40
+
+// BilingualOptions 双语显示设置
+type BilingualOptions struct {
+	Style      string   // engine.BilingualParen 或 engine.BilingualSuffix
+	MaxLength  int      // 原文超过该字符数时只显示译文，0 表示不限
+	Categories []string // 只对这些类别 (阶段类型或 RuleCategories) 的规则生效，为空时对所有规则生效
+}
+
+// bilingualOff 记录中表示只显示译文的格式
+const bilingualOff = "off"
+
+// record 返回写入替换记录和备份记录的设置 (未启用时记为 "off")
+func (o *BilingualOptions) record() *backup.Bilingual {
+	if o == nil {
+		return &backup.Bilingual{Style: bilingualOff}
+	}
+	return &backup.Bilingual{Style: o.Style, MaxLength: o.MaxLength, Categories: slices.Clone(o.Categories)}
+}
+
+// bilingualFromRecord 返回记录中的设置，"off" 时返回 nil
+func bilingualFromRecord(r *backup.Bilingual) *BilingualOptions {
+	if r.Style == bilingualOff {
+		return nil
+	}
+	return &BilingualOptions{Style: r.Style, MaxLength: r.MaxLength, Categories: slices.Clone(r.Categories)}
+}
+
+// key 返回设置的比较键
+func (o *BilingualOptions) key() string {
+	if o == nil {
+		return bilingualOff
+	}
+	return fmt.Sprintf("%s\x00%d\x00%s", o.Style, o.MaxLength, strings.Join(o.Categories, ","))
+}
+
+// errorWords 错误提示中常见的词
+var errorWords = regexp.MustCompile(`(?i)\b(error|errored|fail|failed|failure|invalid|unable|cannot|can't|could not|not found|denied|unauthorized|timed out|exceeded|unexpected)\b`)
+
+// ruleCategory 按原文推断规则的用途 (原文不是单个字符串字面量时按整段原文判断)
+func ruleCategory(rule engine.Rule) string {
+	text := rule.From
+	if inner, _, ok := literals.Unquote(text); ok {
+		text = literals.Decode(inner)
+	}
+	text = strings.TrimSpace(text)
+	words := strings.Fields(text)
+	switch {
+	case errorWords.MatchString(text):
+		return CategoryError
+	case len(words) >= 8 || strings.HasSuffix(text, ".") || strings.HasSuffix(text, "?") || strings.HasSuffix(text, "!"):
+		return CategoryDescription
+	case isTitleCase(words):
+		return CategoryTitle
+	default:
+		return CategoryLabel
+	}
+}
+
+// isTitleCase 判断各个实词 (长度大于 3 的词) 是否都以大写字母开头
+func isTitleCase(words []string) bool {
+	if len(words) == 0 || words[0][0] < 'A' || words[0][0] > 'Z' {
+		return false
+	}
+	for _, w := range words[1:] {
+		if len(w) > 3 && (w[0] < 'A' || w[0] > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// applies 判断双语显示是否对规则生效
+func (o *BilingualOptions) applies(phase string, rule engine.Rule) bool {
+	return len(o.Categories) == 0 || slices.Contains(o.Categories, phase) || slices.Contains(o.Categories, ruleCategory(rule))
+}
+
+// withBilingual 返回使用给定双语显示设置的 Translator，与当前设置相同时返回 t 本身 (按设置缓存)
+func (t *Translator) withBilingual(opts *BilingualOptions) *Translator {
+	key := opts.key()
+	if key == t.Bilingual.key() {
+		return t
+	}
+	t.bilingualMu.Lock()
+	defer t.bilingualMu.Unlock()
+	if v, ok := t.variants[key]; ok {
+		return v
+	}
+	v := &Translator{Backups: t.Backups, Jobs: t.Jobs, PackEnabled: t.PackEnabled, Trace: t.Trace, Strict: t.Strict, UserRules: t.UserRules, HashesPath: t.HashesPath, Bilingual: opts}
+	if t.variants == nil {
+		t.variants = make(map[string]*Translator)
+	}
+	t.variants[key] = v
+	return v
+}
+
+// appliedBilingual 返回文件内容中的译文汉化时使用的双语显示设置: 先查与内容一致的替换记录，
+// 再查最近一次含有该文件、记下了设置的备份；都没有时 (旧版本汉化的文件) 使用当前设置
+func (t *Translator) appliedBilingual(ctx context.Context, path string, content []byte) *BilingualOptions {
+	if trace, err := ReadTrace(path); err == nil && trace.Bilingual != nil && trace.SHA256 == backup.HashContent(content) {
+		return bilingualFromRecord(trace.Bilingual)
+	}
+	if t.Backups.Root != "" {
+		backups, _ := t.Backups.List(ctx)
+		for _, b := range backups {
+			if _, ok := b.Record.Files[path]; ok && b.Record.Bilingual != nil {
+				return bilingualFromRecord(b.Record.Bilingual)
+			}
+		}
+	}
+	return t.Bilingual
+}
+
+// forContent 返回按文件内容中译文的双语显示设置 (见 appliedBilingual) 匹配译文的 Translator
+func (t *Translator) forContent(ctx context.Context, path string, content []byte) *Translator {
+	return t.withBilingual(t.appliedBilingual(ctx, path, content))
+}
+
+// bilingualSet 返回规则集的双语版本 (按规则集缓存)
+func (t *Translator) bilingualSet(set *engine.RuleSet) *engine.RuleSet {
+	t.bilingualMu.Lock()
+	defer t.bilingualMu.Unlock()
+	if mapped, ok := t.bilingualSets[set]; ok {
+		return mapped
+	}
+	opts := t.Bilingual
+	mapped := set.MapRules(func(phase *engine.Phase, rule engine.Rule) engine.Rule {
+		if !opts.applies(phase.Kind, rule) {
+			return rule
+		}
+		rule, _ = rule.Bilingual(opts.Style, opts.MaxLength)
+		return rule
+	})
+	if t.bilingualSets == nil {
+		t.bilingualSets = make(map[*engine.RuleSet]*engine.RuleSet)
+	}
+	t.bilingualSets[set] = mapped
+	return mapped
+}
@@ -0,0 +1,234 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"antigravity_translator/literals"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 从手工修改的 bundle 生成规则
+// ========================================
+//
+// 本地化人员直接在 chat.js 的副本里修改文字测试效果后，ExtractRules 把原版和修改后的文件逐个字面量对齐，
+// 为每个改动过的字面量生成一条规则:
+//   - 普通规则 (normal): 原文和译文是完整的字符串字面量
+//   - 模板规则 (template): 含 ${...} 的模板字面量，表达式换成占位符 ${$1}、${$2}...，不受压缩后变量名的影响
+//   - 锚定规则 (anchored): 同样的字面量在原版中还有未修改的地方时，用字面量前的属性名或函数名区分
+//
+// 字符串字面量之外的改动 (包括模板中 ${...} 表达式的改动) 无法表示为规则，报告为不安全的改动。
+
+// extractContext 判断锚点和检查已有规则时向前查看的代码长度
+const extractContext = 64
+
+// 字面量前的属性 (label:、"aria-label":、title=、label={) 和函数调用 (t(、i18n.t()
+var (
+	propBefore   = regexp.MustCompile(`(?:^|[^\w$.-])([\w$-]+|"[\w$-]+"|'[\w$-]+')\s*[:=]\s*(?:\{\s*)?$`)
+	calleeBefore = regexp.MustCompile(`(?:^|[^\w$.])([\w$]+(?:\.[\w$]+)*)\(\s*$`)
+)
+
+// ExtractRules 对齐原版和修改后的内容，为每个改动过的字面量生成规则
+// target 用于检查改动是否已由现有规则产生 (这类改动不重复生成)；originalName、editedName 只用于结果展示
+func (t *Translator) ExtractRules(ctx context.Context, target *targets.Target, originalName, editedName string, original, edited []byte) *ExtractResult {
+	result := NewExtractResult(target.ID, originalName, editedName)
+	a := literals.Align(original, edited)
+
+	for _, r := range a.Regions {
+		result.Unsafe = append(result.Unsafe, UnsafeEdit{
+			Offset:   r.NewStart,
+			Line:     lineAt(edited, r.NewStart),
+			Original: snippet(original[r.OldStart:r.OldEnd]),
+			Edited:   snippet(edited[r.NewStart:r.NewEnd]),
+		})
+	}
+
+	// 每个原文字面量的锚点 (用于判断同一原文的改动能否与未修改的地方区分开)
+	anchors := make(map[int]string) // 原版中的偏移 -> 锚点
+	anchorOf := func(lit literals.Literal) string {
+		if anchor, ok := anchors[lit.Start]; ok {
+			return anchor
+		}
+		anchor := anchorBefore(original[max(0, lit.Start-extractContext):lit.Start])
+		anchors[lit.Start] = anchor
+		return anchor
+	}
+
+	// 1. 收集改动: 原文 -> 按出现顺序的改动
+	type change struct {
+		pair   literals.Pair
+		anchor string
+	}
+	changes := make(map[string][]change)
+	var order []string
+	for _, pair := range a.Pairs {
+		if pair.Old.Raw == pair.New.Raw {
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeCanceled, "", "已取消"))
+			return result
+		}
+		if t.producedByRules(ctx, target, original, pair) {
+			result.Existing++
+			continue
+		}
+		if pair.Old.Quote == '`' && !sameTemplateExprs(pair.Old.Text(), pair.New.Text()) {
+			result.Unsafe = append(result.Unsafe, UnsafeEdit{
+				Offset: pair.New.Start, Line: lineAt(edited, pair.New.Start),
+				Original: snippet([]byte(pair.Old.Raw)), Edited: snippet([]byte(pair.New.Raw)),
+				Reason: "修改了模板字面量中的 ${...} 表达式",
+			})
+			continue
+		}
+		if changes[pair.Old.Raw] == nil {
+			order = append(order, pair.Old.Raw)
+		}
+		changes[pair.Old.Raw] = append(changes[pair.Old.Raw], change{pair: pair, anchor: anchorOf(pair.Old)})
+	}
+
+	// 2. 每个原文生成规则
+	occurrences := make(map[string][]literals.Literal)
+	for _, lit := range a.Old {
+		if changes[lit.Raw] != nil {
+			occurrences[lit.Raw] = append(occurrences[lit.Raw], lit)
+		}
+	}
+	for _, raw := range order {
+		cs := changes[raw]
+		first := cs[0].pair
+		rule := ExtractedRule{Kind: "normal", From: first.Old.Raw, To: first.New.Raw, Offset: first.New.Start, Line: lineAt(edited, first.New.Start), Count: len(cs)}
+		if first.Old.Quote == '`' && strings.Contains(first.Old.Text(), "${") {
+			rule.Kind = "template"
+			rule.From, rule.To = templatePlaceholders(first.Old.Raw, first.New.Raw)
+		}
+		for _, c := range cs[1:] {
+			if c.pair.New.Raw != first.New.Raw {
+				result.Warnings = append(result.Warnings, NewProblem(CodeRuleDuplicate, "", "第 %d 行: %s 在不同位置改成了不同的文字 (%s / %s)，只生成第一处的规则", lineAt(edited, c.pair.New.Start), raw, first.New.Raw, c.pair.New.Raw))
+				break
+			}
+		}
+
+		// 原版中还有未修改的同一原文: 尝试用锚点区分
+		if unchanged := len(occurrences[raw]) - len(cs); unchanged > 0 && rule.Kind == "normal" {
+			anchor := cs[0].anchor
+			for _, c := range cs[1:] {
+				if c.anchor != anchor {
+					anchor = ""
+				}
+			}
+			distinct := anchor != ""
+			changed := make(map[int]bool)
+			for _, c := range cs {
+				changed[c.pair.Old.Start] = true
+			}
+			for _, lit := range occurrences[raw] {
+				if !changed[lit.Start] && anchorOf(lit) == anchor {
+					distinct = false
+				}
+			}
+			if distinct {
+				rule.Kind, rule.Anchor = "anchored", anchor
+			} else {
+				result.Warnings = append(result.Warnings, NewProblem(CodeRuleDuplicate, "", "第 %d 行: %s 在原版中出现 %d 次，只修改了 %d 处，且无法用属性名或函数名区分；生成的规则会替换所有 %d 处", rule.Line, raw, len(occurrences[raw]), len(cs), len(occurrences[raw])))
+			}
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+	return result
+}
+
+// producedByRules 判断改动是否已由现有规则产生: 用字面量及其前面的代码翻译后，结尾是否就是修改后的字面量
+func (t *Translator) producedByRules(ctx context.Context, target *targets.Target, original []byte, pair literals.Pair) bool {
+	before := original[max(0, pair.Old.Start-extractContext):pair.Old.Start]
+	if i := bytes.LastIndexAny(before, "\"'`"); i >= 0 {
+		before = before[i+1:] // 只保留代码，不包含前一个字面量的残片
+	}
+	translated, _, err := t.TranslateContent(ctx, target, string(before)+pair.Old.Raw)
+	return err == nil && strings.HasSuffix(translated, pair.New.Raw)
+}
+
+// anchorBefore 返回字面量之前的属性名 (如 "label") 或函数名 (如 "t()")，没有时返回空字符串
+func anchorBefore(code []byte) string {
+	if m := propBefore.FindSubmatch(code); m != nil {
+		return strings.Trim(string(m[1]), `"'`)
+	}
+	if m := calleeBefore.FindSubmatch(code); m != nil {
+		return string(m[1]) + "()"
+	}
+	return ""
+}
+
+// sameTemplateExprs 判断两个模板字面量中的 ${...} 表达式是否依次相同
+func sameTemplateExprs(a, b string) bool {
+	ea, eb := templateExprs(a), templateExprs(b)
+	if len(ea) != len(eb) {
+		return false
+	}
+	for i := range ea {
+		if ea[i] != eb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// templateExprPattern 模板字面量中的 ${...} 表达式 (花括号最多嵌套一层)
+var templateExprPattern = regexp.MustCompile(`\$\{((?:[^{}]|\{[^{}]*\})*)\}`)
+
+// templateExprs 返回模板字面量中的所有表达式
+func templateExprs(s string) []string {
+	var exprs []string
+	for _, m := range templateExprPattern.FindAllStringSubmatch(s, -1) {
+		exprs = append(exprs, m[1])
+	}
+	return exprs
+}
+
+// templatePlaceholders 把原文和译文中的表达式依次换成占位符 ${$1}、${$2}...
+// (同一个表达式出现多次时使用同一个编号；n.length 这样的属性访问只把变量名换成占位符，写成 ${$1.length})
+func templatePlaceholders(from, to string) (string, string) {
+	numbers := make(map[string]int)
+	replace := func(s string) string {
+		return templateExprPattern.ReplaceAllStringFunc(s, func(m string) string {
+			expr := m[2 : len(m)-1]
+			name, rest := expr, ""
+			if loc := memberExpr.FindStringSubmatchIndex(expr); loc != nil {
+				name, rest = expr[:loc[3]], expr[loc[3]:]
+			}
+			n, ok := numbers[name]
+			if !ok {
+				n = len(numbers) + 1
+				numbers[name] = n
+			}
+			return fmt.Sprintf("${$%d%s}", n, rest)
+		})
+	}
+	return replace(from), replace(to)
+}
+
+// memberExpr 变量的属性访问，如 n.length、e.file.name
+var memberExpr = regexp.MustCompile(`^([A-Za-z_$][\w$]*)(?:\.[\w$]+)+$`)
+
+// lineAt 返回字节偏移所在的行号 (从 1 开始)
+func lineAt(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// snippet 截取改动内容用于展示 (最多 120 字节，按 UTF-8 字符截断)
+func snippet(b []byte) string {
+	const limit = 120
+	s := string(b)
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && (s[cut]&0xC0) == 0x80 {
+		cut--
+	}
+	return s[:cut] + "…"
+}
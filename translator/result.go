@@ -0,0 +1,470 @@
+package translator
+
+import (
+	"fmt"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/checksum"
+	"antigravity_translator/engine"
+)
+
+// 稳定的错误码，供 JSON 输出的调用方判断错误类型
+const (
+	CodeInstallNotFound   = "INSTALL_NOT_FOUND"
+	CodeInvalidPath       = "INVALID_INSTALL_PATH"
+	CodeNoTargetFiles     = "NO_TARGET_FILES"
+	CodeFileNotFound      = "FILE_NOT_FOUND"
+	CodeBackupDirFailed   = "BACKUP_DIR_FAILED"
+	CodeBackupFailed      = "BACKUP_FAILED"
+	CodeBackupRecord      = "BACKUP_RECORD_FAILED"
+	CodeBackupNotFound    = "BACKUP_NOT_FOUND"
+	CodeBackupFileAbsent  = "BACKUP_FILE_MISSING"
+	CodeBackupMigrated    = "BACKUP_MIGRATED"
+	CodeReadFailed        = "READ_FAILED"
+	CodeWriteFailed       = "WRITE_FAILED"
+	CodeProductJSON       = "PRODUCT_JSON_FAILED"
+	CodeNoProductJSON     = "PRODUCT_JSON_MISSING"
+	CodeConfig            = "CONFIG_FAILED"
+	CodeObsoleteExtension = "OBSOLETE_EXTENSION"
+	CodeBenchMismatch     = "BENCH_OUTPUT_MISMATCH"
+	CodeNotApplied        = "NOT_APPLIED"
+	CodeRolledBack        = "ROLLED_BACK"
+	CodeCanceled          = "CANCELED"
+	CodeRuleInvalid       = "RULE_INVALID"
+	CodeRuleDuplicate     = "RULE_DUPLICATE"
+	CodeExpectViolated    = "EXPECT_VIOLATED"
+	CodeHashMismatch      = "HASH_MISMATCH"
+	CodeTraceStale        = "TRACE_STALE"
+	CodeNoRulesMatched    = "NO_RULES_MATCHED"
+	CodeQuoteMismatch     = "QUOTE_MISMATCH"
+)
+
+// Problem 警告或错误
+type Problem struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Path    string `json:"path,omitempty"`
+}
+
+// NewProblem 创建一个带错误码的警告或错误
+func NewProblem(code, path string, format string, args ...interface{}) Problem {
+	return Problem{Code: code, Message: fmt.Sprintf(format, args...), Path: path}
+}
+
+// FileResult 单个文件的汉化结果
+type FileResult struct {
+	Path        string        `json:"path"`
+	Description string        `json:"description"`
+	Target      string        `json:"target"`           // 目标 ID，如 "antigravity.chat"
+	Group       string        `json:"group"`            // 备份分组
+	Backup      string        `json:"backup,omitempty"` // 备份文件名
+	SizeBefore  int           `json:"size_before"`
+	SizeAfter   int           `json:"size_after"`
+	Written     bool          `json:"written"` // 译文是否已写回 (任一文件失败或预览时整组都不写回)
+	Stats       *engine.Stats `json:"stats,omitempty"`
+	Error       *Problem      `json:"error,omitempty"`
+}
+
+// ApplyResult 汉化操作结果
+type ApplyResult struct {
+	Operation   string            `json:"operation"`
+	Target      string            `json:"target"` // "antigravity" 或 "continue"
+	InstallPath string            `json:"install_path"`
+	BackupID    string            `json:"backup_id,omitempty"`
+	BackupDir   string            `json:"backup_dir,omitempty"`
+	DryRun      bool              `json:"dry_run,omitempty"` // 只预览译文，不备份也不写回 (见 Translator.Preview)
+	Files       []FileResult      `json:"files"`
+	TargetHits  []*TargetHits     `json:"target_hits,omitempty"` // 预览时按目标汇总的命中
+	Checksums   []checksum.Action `json:"checksums,omitempty"`
+	Warnings    []Problem         `json:"warnings"`
+	Errors      []Problem         `json:"errors"`
+}
+
+// NewApplyResult 创建一个空的汉化结果
+func NewApplyResult(group, root string) *ApplyResult {
+	return &ApplyResult{
+		Operation:   "apply",
+		Target:      group,
+		InstallPath: root,
+		Files:       []FileResult{},
+		Warnings:    []Problem{},
+		Errors:      []Problem{},
+	}
+}
+
+// Succeeded 返回成功处理的文件数
+func (r *ApplyResult) Succeeded() int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Written {
+			n++
+		}
+	}
+	return n
+}
+
+// TargetHits 预览时某个目标的命中汇总
+type TargetHits struct {
+	Target string `json:"target"`
+	Files  int    `json:"files"`
+	Rules  int    `json:"rules"` // 命中的规则数 (同一规则在多个文件中命中只计一次)
+	Hits   int    `json:"hits"`  // 替换总次数
+}
+
+// RestoredFile 单个文件的还原结果
+type RestoredFile struct {
+	Path   string   `json:"path"`
+	Backup string   `json:"backup"`
+	Error  *Problem `json:"error,omitempty"`
+}
+
+// RestoreResult 还原操作结果
+type RestoreResult struct {
+	Operation   string         `json:"operation"`
+	BackupID    string         `json:"backup_id"`
+	InstallPath string         `json:"install_path"`
+	Files       []RestoredFile `json:"files"`
+	Warnings    []Problem      `json:"warnings"`
+	Errors      []Problem      `json:"errors"`
+}
+
+// Succeeded 返回成功还原的文件数
+func (r *RestoreResult) Succeeded() int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Error == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// BackupSummary 备份列表中的一项
+type BackupSummary struct {
+	ID          string                `json:"id"`
+	Path        string                `json:"path"`
+	Timestamp   string                `json:"timestamp"`
+	BackupType  string                `json:"backup_type"`
+	InstallPath string                `json:"install_path"`
+	Files       map[string]string     `json:"files"`
+	Reverted    []backup.RevertedRule `json:"reverted,omitempty"`  // 汉化后用 revert 撤销的规则
+	Bilingual   *backup.Bilingual     `json:"bilingual,omitempty"` // 文件中译文的双语显示设置
+}
+
+// ListResult 备份列表结果
+type ListResult struct {
+	Operation  string          `json:"operation"`
+	BackupRoot string          `json:"backup_root"`
+	Backups    []BackupSummary `json:"backups"`
+	Warnings   []Problem       `json:"warnings"`
+	Errors     []Problem       `json:"errors"`
+}
+
+// FileStatus 单个目标文件的状态
+type FileStatus struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	Target      string `json:"target"`
+	Exists      bool   `json:"exists"`
+	Size        int    `json:"size"`
+	PendingHits int    `json:"pending_hits"` // 仍可被翻译的匹配数，0 表示已完全汉化
+}
+
+// StatusResult 状态查询结果
+type StatusResult struct {
+	Operation   string            `json:"operation"`
+	InstallPath string            `json:"install_path,omitempty"`
+	Files       []FileStatus      `json:"files"`
+	Checksums   []checksum.Action `json:"checksums,omitempty"` // "present" 或 "absent"
+	LastBackup  *BackupSummary    `json:"last_backup,omitempty"`
+	Warnings    []Problem         `json:"warnings"`
+	Errors      []Problem         `json:"errors"`
+}
+
+// LintPhase 规则包中一个阶段的规则数
+type LintPhase struct {
+	Pack     string `json:"pack"`
+	Phase    string `json:"phase"`
+	Rules    int    `json:"rules"`
+	Patterns int    `json:"patterns"` // 其中的模式规则 (占位符、匹配方式、正则) 数
+}
+
+// LintResult 规则检查结果
+type LintResult struct {
+	Operation string      `json:"operation"`
+	Phases    []LintPhase `json:"phases"`
+	Warnings  []Problem   `json:"warnings"`
+	Errors    []Problem   `json:"errors"`
+}
+
+// Suggestion 失效规则的一个候选更新: 原文换成 bundle 中相近的字面量，沿用旧译文
+type Suggestion struct {
+	ID         int     `json:"id"`   // 编号，供 --accept 选择
+	From       string  `json:"from"` // 新原文 (bundle 中的字符串字面量)
+	To         string  `json:"to"`   // 沿用的旧译文 (换成新原文的引号)
+	Similarity float64 `json:"similarity"`
+}
+
+// StaleRule 一条失效的规则 (对目标生效但没有命中，译文也不在文件中) 及其候选更新
+type StaleRule struct {
+	Target      string       `json:"target"`
+	Pack        string       `json:"pack"`
+	Phase       string       `json:"phase"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// StaleResult 失效规则检查结果
+type StaleResult struct {
+	Operation   string      `json:"operation"`
+	Target      string      `json:"target"` // "antigravity" 或 "continue"
+	InstallPath string      `json:"install_path"`
+	Rules       []StaleRule `json:"rules"`
+	UserRules   string      `json:"user_rules,omitempty"` // 写入了接受的建议的用户规则文件
+	Accepted    []int       `json:"accepted,omitempty"`   // 已接受的建议编号
+	Warnings    []Problem   `json:"warnings"`
+	Errors      []Problem   `json:"errors"`
+}
+
+// NewStaleResult 创建一个空的失效规则检查结果
+func NewStaleResult(group, root string) *StaleResult {
+	return &StaleResult{Operation: "rules stale", Target: group, InstallPath: root, Rules: []StaleRule{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
+
+// Suggestion 按编号查找候选更新及其所属的失效规则
+func (r *StaleResult) Suggestion(id int) (*StaleRule, *Suggestion) {
+	for i := range r.Rules {
+		for j := range r.Rules[i].Suggestions {
+			if r.Rules[i].Suggestions[j].ID == id {
+				return &r.Rules[i], &r.Rules[i].Suggestions[j]
+			}
+		}
+	}
+	return nil, nil
+}
+
+// RuleRef 受影响的规则
+type RuleRef struct {
+	Kind string `json:"kind"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RemovedString 旧版本中有、新版本中没有的字面量，以及原本会翻译它的规则
+type RemovedString struct {
+	Text  string    `json:"text"` // 含引号的原始字面量
+	Rules []RuleRef `json:"rules"`
+}
+
+// ModifiedString 在新版本中被小幅改动的字面量 (删除的字面量与相近的新增字面量配对)
+type ModifiedString struct {
+	Old        string    `json:"old"`
+	New        string    `json:"new"`
+	Similarity float64   `json:"similarity"`
+	Rules      []RuleRef `json:"rules"` // 原本翻译旧字面量的规则
+}
+
+// TargetDiff 一个目标在两个版本之间的界面字符串变化
+type TargetDiff struct {
+	Target   string           `json:"target"`
+	OldFiles []string         `json:"old_files"`
+	NewFiles []string         `json:"new_files"`
+	Added    []string         `json:"added"`
+	Removed  []RemovedString  `json:"removed"`
+	Modified []ModifiedString `json:"modified"`
+}
+
+// StringsDiffResult 两个版本的 bundle 之间字符串字面量的比较结果
+type StringsDiffResult struct {
+	Operation string       `json:"operation"`
+	Old       string       `json:"old"` // 旧版本: 文件路径或备份 ID
+	New       string       `json:"new"`
+	Targets   []TargetDiff `json:"targets"`
+	Warnings  []Problem    `json:"warnings"`
+	Errors    []Problem    `json:"errors"`
+}
+
+// NewStringsDiffResult 创建一个空的比较结果
+func NewStringsDiffResult(oldName, newName string) *StringsDiffResult {
+	return &StringsDiffResult{Operation: "strings diff", Old: oldName, New: newName, Targets: []TargetDiff{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
+
+// ExtractedRule 从手工修改的 bundle 中生成的一条规则
+type ExtractedRule struct {
+	Kind   string `json:"kind"`             // "normal"、"template" 或 "anchored"
+	From   string `json:"from"`             // 原文
+	To     string `json:"to"`               // 译文
+	Anchor string `json:"anchor,omitempty"` // 锚定规则的属性名，或以 "()" 结尾的函数名
+	Offset int    `json:"offset"`           // 第一处改动在修改后的文件中的字节偏移
+	Line   int    `json:"line"`             // 第一处改动所在的行
+	Count  int    `json:"count"`            // 相同改动的处数
+}
+
+// UnsafeEdit 字符串字面量之外的改动，无法表示为规则
+type UnsafeEdit struct {
+	Offset   int    `json:"offset"` // 在修改后的文件中的字节偏移
+	Line     int    `json:"line"`
+	Original string `json:"original"` // 原版中的内容 (过长时截断)
+	Edited   string `json:"edited"`   // 修改后的内容 (过长时截断)
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ExtractResult 从手工修改的 bundle 生成规则的结果
+type ExtractResult struct {
+	Operation string          `json:"operation"`
+	Target    string          `json:"target"` // 目标 ID
+	Original  string          `json:"original"`
+	Edited    string          `json:"edited"`
+	Rules     []ExtractedRule `json:"rules"`
+	Existing  int             `json:"existing"` // 已由现有规则产生、不需要生成规则的改动数
+	Unsafe    []UnsafeEdit    `json:"unsafe"`
+	Warnings  []Problem       `json:"warnings"`
+	Errors    []Problem       `json:"errors"`
+}
+
+// NewExtractResult 创建一个空的规则生成结果
+func NewExtractResult(target, original, edited string) *ExtractResult {
+	return &ExtractResult{Operation: "rules extract", Target: target, Original: original, Edited: edited, Rules: []ExtractedRule{}, Unsafe: []UnsafeEdit{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
+
+// ProgressEvent 并行翻译时每完成一个文件报告一次的进度
+type ProgressEvent struct {
+	Event  string `json:"event"` // "translated" 或 "failed"
+	Target string `json:"target"`
+	Path   string `json:"path"`
+	Done   int    `json:"done"`
+	Total  int    `json:"total"`
+	Error  string `json:"error,omitempty"`
+}
+
+// IrreversibleRule 无法反向的规则: 多条规则译文相同 (反向后无法区分原文)，或规则本身无法反向 (如正则规则)
+type IrreversibleRule struct {
+	Target string   `json:"target"`
+	Kind   string   `json:"kind"`
+	To     string   `json:"to"`
+	From   []string `json:"from"` // 可能的原文 (译文相同时有多个)
+	Reason string   `json:"reason"`
+}
+
+// RemainingLiteral 反向翻译后仍含中文的字符串字面量
+type RemainingLiteral struct {
+	Text       string   `json:"text"`
+	Line       int      `json:"line"`                 // 首次出现的行号
+	Count      int      `json:"count"`                // 出现次数
+	Candidates []string `json:"candidates,omitempty"` // 无法反向的规则中译文出现在字面量里的原文
+}
+
+// UntranslatedFile 单个文件的反向翻译结果
+type UntranslatedFile struct {
+	Path      string             `json:"path"`
+	Target    string             `json:"target"`
+	Backup    string             `json:"backup,omitempty"` // 反向翻译前 (已汉化) 内容的备份文件名
+	Restored  int                `json:"restored"`         // 反向替换的次数
+	Written   bool               `json:"written"`
+	Verified  string             `json:"verified"` // 与记录的原版哈希比较: "match"、"mismatch"，没有记录时为空
+	Remaining []RemainingLiteral `json:"remaining"`
+	Error     *Problem           `json:"error,omitempty"`
+}
+
+// UntranslateResult 反向翻译结果
+type UntranslateResult struct {
+	Operation    string             `json:"operation"`
+	Target       string             `json:"target"` // "antigravity" 或 "continue"
+	InstallPath  string             `json:"install_path"`
+	DryRun       bool               `json:"dry_run,omitempty"`
+	BackupID     string             `json:"backup_id,omitempty"`
+	BackupDir    string             `json:"backup_dir,omitempty"`
+	Files        []UntranslatedFile `json:"files"`
+	Irreversible []IrreversibleRule `json:"irreversible"`
+	Warnings     []Problem          `json:"warnings"`
+	Errors       []Problem          `json:"errors"`
+}
+
+// NewUntranslateResult 创建一个空的反向翻译结果
+func NewUntranslateResult(group, root string) *UntranslateResult {
+	return &UntranslateResult{Operation: "untranslate", Target: group, InstallPath: root, Files: []UntranslatedFile{}, Irreversible: []IrreversibleRule{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
+
+// ExplainRule 产生一段译文的规则
+type ExplainRule struct {
+	ID       string `json:"id"`                 // 规则包/阶段/规则内容的短哈希，如 "chat/normal/3fa2c1d0"
+	Position string `json:"position,omitempty"` // 规则在当前规则表中的位置，如 "chat/normal/12" (只用于显示)
+	Kind     string `json:"kind,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Anchor   string `json:"anchor,omitempty"`
+	Source   string `json:"source,omitempty"`   // 替换记录中被替换的原文
+	Produced string `json:"produced,omitempty"` // 替换记录中写入的译文
+	File     string `json:"file,omitempty"`     // 规则所在的规则表文件 (用户规则为 user_rules.json)
+	Line     int    `json:"line,omitempty"`
+	Stale    bool   `json:"stale,omitempty"` // 当前规则中没有产生这处替换的规则 (规则表在汉化后修改过)
+}
+
+// ExplainMatch 一段译文在目标文件中的一处出现
+type ExplainMatch struct {
+	Target  string        `json:"target"`
+	Path    string        `json:"path"`
+	Offset  int           `json:"offset"`
+	Line    int           `json:"line"`
+	Context string        `json:"context"`
+	Traced  bool          `json:"traced"` // 规则来自替换记录；为 false 时是译文包含这段文字的候选规则
+	Rules   []ExplainRule `json:"rules"`
+}
+
+// ExplainResult 译文反查结果
+type ExplainResult struct {
+	Operation string         `json:"operation"`
+	Text      string         `json:"text"`
+	Matches   []ExplainMatch `json:"matches"`
+	Warnings  []Problem      `json:"warnings"`
+	Errors    []Problem      `json:"errors"`
+}
+
+// NewExplainResult 创建一个空的译文反查结果
+func NewExplainResult(text string) *ExplainResult {
+	return &ExplainResult{Operation: "explain", Text: text, Matches: []ExplainMatch{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
+
+// RevertRule 被选中撤销的规则
+type RevertRule struct {
+	ID       string `json:"id"`
+	Position string `json:"position"` // 规则在当前规则表中的位置 (只用于显示)
+	Kind     string `json:"kind"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// RevertedFile 单个文件的撤销结果
+type RevertedFile struct {
+	Path     string         `json:"path"`
+	Target   string         `json:"target"`
+	Method   string         `json:"method,omitempty"` // "trace" (按替换记录) 或 "inverse" (反向规则)，没有选中的规则时为空
+	Reverted int            `json:"reverted"`         // 撤销的替换次数
+	Rules    map[string]int `json:"rules"`            // 规则 ID -> 撤销次数
+	Stale    map[string]int `json:"stale,omitempty"`  // 规则 ID -> 替换记录中与当前规则不符而未撤销的次数
+	Written  bool           `json:"written"`
+	Pristine bool           `json:"pristine"` // 撤销后与原版完全一致
+	Error    *Problem       `json:"error,omitempty"`
+}
+
+// RevertResult 撤销规则的结果
+type RevertResult struct {
+	Operation   string             `json:"operation"`
+	Target      string             `json:"target"` // "antigravity" 或 "continue"
+	InstallPath string             `json:"install_path"`
+	DryRun      bool               `json:"dry_run,omitempty"`
+	BackupID    string             `json:"backup_id,omitempty"` // 撤销前的文件的备份，撤销的规则记在其中
+	BackupDir   string             `json:"backup_dir,omitempty"`
+	Rules       []RevertRule       `json:"rules"`
+	Files       []RevertedFile     `json:"files"`
+	Skipped     []IrreversibleRule `json:"skipped"` // 没有替换记录时无法撤销的规则
+	Checksums   []checksum.Action  `json:"checksums,omitempty"`
+	Warnings    []Problem          `json:"warnings"`
+	Errors      []Problem          `json:"errors"`
+}
+
+// NewRevertResult 创建一个空的撤销规则结果
+func NewRevertResult(group, root string) *RevertResult {
+	return &RevertResult{Operation: "revert", Target: group, InstallPath: root, Rules: []RevertRule{}, Files: []RevertedFile{}, Skipped: []IrreversibleRule{}, Warnings: []Problem{}, Errors: []Problem{}}
+}
@@ -0,0 +1,514 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/checksum"
+	"antigravity_translator/engine"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 撤销个别规则 (revert)
+// ========================================
+//
+// 某条译文有误 (例如翻错的安全警告) 时，只把这条规则的替换改回原文，其他规则的译文保持不变:
+//   - 文件有替换记录 (apply --trace) 且未被修改时，按记录把选中规则的每处译文换回实际替换的原文，
+//     并更新替换记录；嵌套在其他替换中的替换同样可以撤销
+//   - 否则用选中规则的反向规则 (见 engine.Rule.Inverse) 替换，译文与其他规则相同的规则无法区分，跳过
+//
+// 写回前备份当前 (已汉化) 的文件和 product.json，撤销的规则记入这次备份的记录，可以用 restore 撤销本次撤销；
+// 写回中途失败时用内存中的内容回滚已写入的文件。
+// 文件因此恢复为原版时把 product.json 中的校验和从备份加回，否则确保校验和已移除。
+
+// RevertSelector 要撤销的规则，满足任意一个条件的规则被选中
+type RevertSelector struct {
+	Rules      []string       // 规则 ID，如 "chat/normal/12"
+	Pattern    *regexp.Regexp // 匹配规则的原文或译文
+	Categories []string       // 阶段类型，如 "anchored"、"regex"、"user"
+}
+
+// Empty 判断是否没有指定任何条件
+func (s RevertSelector) Empty() bool {
+	return len(s.Rules) == 0 && s.Pattern == nil && len(s.Categories) == 0
+}
+
+// matches 判断规则是否被选中 (--rule 可以给出规则 ID 或当前规则表中的位置)
+func (s RevertSelector) matches(id, position, phase string, rule engine.Rule) bool {
+	return slices.Contains(s.Rules, id) || slices.Contains(s.Rules, position) || slices.Contains(s.Categories, phase) ||
+		(s.Pattern != nil && (s.Pattern.MatchString(rule.From) || s.Pattern.MatchString(rule.To)))
+}
+
+// selectedRule 选中的一条规则
+type selectedRule struct {
+	id       string
+	position string // 规则在当前规则表中的位置 (见 engine.RulePosition)
+	order    int    // 所在阶段在目标所有阶段中的顺序
+	rule     engine.Rule
+}
+
+// selectRules 返回目标的规则中被选中的规则 (按应用顺序)
+func (t *Translator) selectRules(target *targets.Target, sel RevertSelector) []selectedRule {
+	var selected []selectedRule
+	order := 0
+	for _, set := range t.ruleSets(target) {
+		for _, phase := range set.Phases {
+			keys := phase.Keys()
+			for i, rule := range phase.Rules() {
+				id, position := engine.RuleID(set.Name, phase.Kind, keys[i]), engine.RulePosition(set.Name, phase.Kind, i+1)
+				if rule.Scope.AppliesTo(target.ID) && sel.matches(id, position, phase.Kind, rule) {
+					selected = append(selected, selectedRule{id: id, position: position, order: order, rule: rule})
+				}
+			}
+			order++
+		}
+	}
+	return selected
+}
+
+// Revert 撤销某个分组在根目录下已汉化文件中选中规则的替换
+// dryRun 为 true 时只报告结果；任何文件读取失败时不修改任何文件
+func (t *Translator) Revert(ctx context.Context, group, root string, files []targets.File, sel RevertSelector, dryRun bool) *RevertResult {
+	result := NewRevertResult(group, root)
+	result.DryRun = dryRun
+
+	// 1. 撤销 (只在内存中)
+	var contents []revertedContent
+	listed := make(map[string]bool)
+	for _, f := range files {
+		rf := RevertedFile{Path: f.Path, Target: f.Target.ID, Rules: map[string]int{}}
+		content, err := os.ReadFile(f.Path)
+		ft := t
+		if err == nil {
+			ft = t.forContent(ctx, f.Path, content) // 按汉化时的双语显示设置匹配译文
+		}
+		selected := ft.selectRules(f.Target, sel)
+		for _, s := range selected {
+			if !listed[s.id] {
+				listed[s.id] = true
+				result.Rules = append(result.Rules, RevertRule{ID: s.id, Position: s.position, Kind: s.rule.Kind, From: s.rule.From, To: s.rule.To})
+			}
+		}
+		if err != nil {
+			p := NewProblem(CodeReadFailed, f.Path, "读取失败: %v", err)
+			rf.Error = &p
+			result.Files = append(result.Files, rf)
+			contents = append(contents, revertedContent{})
+			continue
+		}
+		rc := revertedContent{original: content, reverted: string(content), bilingual: ft.Bilingual}
+		if len(selected) == 0 {
+			result.Files = append(result.Files, rf)
+			contents = append(contents, rc)
+			continue
+		}
+
+		trace, err := ReadTrace(f.Path)
+		switch {
+		case err != nil && !os.IsNotExist(err):
+			result.Warnings = append(result.Warnings, NewProblem(CodeReadFailed, TracePath(f.Path), "读取替换记录失败，改用反向规则: %v", err))
+			trace = nil
+		case trace != nil && trace.SHA256 != backup.HashContent(content):
+			result.Warnings = append(result.Warnings, NewProblem(CodeTraceStale, TracePath(f.Path), "文件在 %s 汉化后已被修改，改用反向规则", trace.Timestamp))
+			trace = nil
+		}
+		if trace != nil {
+			rf.Method = "trace"
+			rules := make(map[string]engine.Rule)
+			for _, s := range selected {
+				rules[s.id] = s.rule
+			}
+			var entries []engine.Replacement
+			rf.Stale = map[string]int{}
+			rc.reverted, entries = revertEntries(string(content), trace.Replacements, rules, rf.Rules, rf.Stale)
+			trace.Replacements = entries
+			for id, n := range rf.Stale {
+				result.Warnings = append(result.Warnings, NewProblem(CodeTraceStale, TracePath(f.Path), "替换记录中 %d 处 [%s] 的原文和译文与当前规则不符 (规则的译文在汉化后修改过)，未撤销；重新 apply --trace 后再撤销", n, id))
+			}
+			rc.trace = trace
+		} else {
+			rf.Method = "inverse"
+			var skipped []IrreversibleRule
+			rc.reverted, skipped, err = ft.revertInverse(ctx, f.Target, string(content), selected, rf.Rules)
+			if err != nil {
+				result.Errors = append(result.Errors, NewProblem(CodeCanceled, root, "已取消，未修改任何文件"))
+				return result
+			}
+			for _, ir := range skipped {
+				if !slices.ContainsFunc(result.Skipped, func(s IrreversibleRule) bool { return s.Target == ir.Target && s.To == ir.To }) {
+					result.Skipped = append(result.Skipped, ir)
+				}
+			}
+		}
+		for _, n := range rf.Rules {
+			rf.Reverted += n
+		}
+		result.Files = append(result.Files, rf)
+		contents = append(contents, rc)
+	}
+	if len(result.Rules) == 0 {
+		result.Errors = append(result.Errors, NewProblem(CodeNoRulesMatched, root, "没有符合条件的规则"))
+		return result
+	}
+	for _, rf := range result.Files {
+		if rf.Error != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "部分文件读取失败，未修改任何文件"))
+			return result
+		}
+	}
+
+	// 与记录的原版哈希或备份中的原始文件比较，判断撤销后是否恢复为原版
+	// (重复汉化时备份的是已汉化的文件，这样的备份不算原版)
+	backups := t.installBackups(ctx, root)
+	var hashes *backup.Hashes
+	if t.HashesPath != "" {
+		hashes, _ = backup.LoadHashes(t.HashesPath)
+	}
+	for i, f := range files {
+		rf := &result.Files[i]
+		if rf.Reverted == 0 {
+			continue
+		}
+		reverted := []byte(contents[i].reverted)
+		if fh, ok := hashes.Lookup(rf.Path); ok {
+			rf.Pristine = fh.SHA256 == backup.HashContent(reverted)
+			continue
+		}
+		for _, b := range backups {
+			if original, ok, err := b.OriginalContent(rf.Path); ok && err == nil && bytes.Equal(original, reverted) && t.withBilingual(contents[i].bilingual).isPristine(ctx, f.Target, original) {
+				rf.Pristine = true
+				break
+			}
+		}
+	}
+	if dryRun {
+		return result
+	}
+
+	// 2. 备份当前 (已汉化) 的内容和 product.json，记下撤销的规则 (写回之前保存，中途中断也可以还原)
+	var changed []int
+	var written []targets.File
+	for i, rf := range result.Files {
+		if rf.Reverted > 0 {
+			changed = append(changed, i)
+			written = append(written, files[i])
+		}
+	}
+	if len(changed) == 0 {
+		return result
+	}
+	if t.Backups.Root == "" {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "未设置备份目录"))
+		return result
+	}
+	b, err := t.Backups.Create(group, root)
+	if err != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "创建备份目录失败: %v", err))
+		return result
+	}
+	for _, i := range changed {
+		if _, err := b.AddContent(result.Files[i].Path, contents[i].original); err != nil {
+			b.Discard()
+			p := NewProblem(CodeBackupFailed, result.Files[i].Path, "备份失败: %v", err)
+			result.Files[i].Error = &p
+			result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "备份失败，未修改任何文件"))
+			return result
+		}
+	}
+	productJsonPath := checksum.ProductJSONPath(root)
+	if len(targets.ChecksumKeys(written)) > 0 {
+		if _, err := os.Stat(productJsonPath); err == nil {
+			if _, err := b.AddFile(productJsonPath); err != nil {
+				result.Warnings = append(result.Warnings, NewProblem(CodeBackupFailed, productJsonPath, "备份 product.json 失败: %v", err))
+			}
+		}
+	}
+	now := time.Now().Format("2006-01-02 15:04:05")
+	for _, i := range changed {
+		rf := result.Files[i]
+		ids := make([]string, 0, len(rf.Rules))
+		for id := range rf.Rules {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			k := slices.IndexFunc(result.Rules, func(r RevertRule) bool { return r.ID == id })
+			b.Record.Reverted = append(b.Record.Reverted, backup.RevertedRule{Rule: id, From: result.Rules[k].From, To: result.Rules[k].To, Path: rf.Path, Count: rf.Rules[id], Timestamp: now})
+		}
+	}
+	b.Record.Bilingual = contents[changed[0]].bilingual.record() // 写回后文件中其余译文的设置不变
+	if err := b.Save(); err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupRecord, b.Dir, "保存备份记录失败: %v", err))
+	}
+	result.BackupID, result.BackupDir = b.ID, b.Dir
+
+	// 3. 按顺序写回，失败时回滚已写入的文件
+	for k, i := range changed {
+		if err := WriteFileAtomic(result.Files[i].Path, []byte(contents[i].reverted)); err != nil {
+			p := NewProblem(CodeWriteFailed, result.Files[i].Path, "保存失败: %v", err)
+			result.Files[i].Error = &p
+			rollbackReverts(result, changed[:k], contents, b)
+			return result
+		}
+		result.Files[i].Written = true
+	}
+
+	// 4. 更新替换记录
+	for _, i := range changed {
+		path := result.Files[i].Path
+		if tf := contents[i].trace; tf != nil && !result.Files[i].Pristine {
+			if err := WriteTrace(files[i], []byte(contents[i].reverted), tf.Replacements, tf.Bilingual); err != nil {
+				result.Warnings = append(result.Warnings, NewProblem(CodeWriteFailed, TracePath(path), "保存替换记录失败: %v", err))
+			}
+		} else {
+			RemoveTrace(path)
+		}
+	}
+
+	// 5. product.json 校验和: 恢复为原版的文件加回校验和，仍有译文的文件确保已移除
+	t.revertChecksums(context.WithoutCancel(ctx), result, root, backups, written)
+	return result
+}
+
+// revertedContent 一个文件撤销前后的内容
+type revertedContent struct {
+	original  []byte
+	reverted  string
+	trace     *TraceFile        // 按替换记录撤销时更新后的记录
+	bilingual *BilingualOptions // 汉化时的双语显示设置 (见 appliedBilingual)
+}
+
+// rollbackReverts 写回中途失败时，用撤销前的内容还原已写入的文件 (written 为 result.Files 中的下标)
+// 全部还原成功则删除本次备份，否则保留备份供手动还原
+func rollbackReverts(result *RevertResult, written []int, contents []revertedContent, b *backup.Backup) {
+	restored := true
+	for _, i := range written {
+		if err := WriteFileAtomic(result.Files[i].Path, contents[i].original); err != nil {
+			p := NewProblem(CodeWriteFailed, result.Files[i].Path, "回滚失败: %v", err)
+			result.Files[i].Error = &p
+			restored = false
+			continue
+		}
+		result.Files[i].Written = false
+	}
+	if restored {
+		b.Discard()
+		result.BackupID, result.BackupDir = "", ""
+		result.Errors = append(result.Errors, NewProblem(CodeRolledBack, "", "写回失败，已还原所有文件"))
+		return
+	}
+	result.Errors = append(result.Errors, NewProblem(CodeRolledBack, b.Dir, "写回失败且回滚未完成，请使用备份 %s 还原", b.ID))
+}
+
+// revertEntries 把 content 中被选中规则 (规则 ID -> 规则) 的替换换回原文，返回结果和换算到结果中的其余记录，
+// 每条规则撤销的次数累加到 counts。原文和译文与当前规则不符的记录 (规则的译文在汉化后修改过)
+// 不撤销，次数累加到 stale。嵌套在其他替换中的记录 (位置落在外层替换的译文内) 随外层一起处理:
+// 外层被撤销时在外层原文中重新定位，外层保留时在外层译文内撤销并更新外层的译文
+func revertEntries(content string, entries []engine.Replacement, selected map[string]engine.Rule, counts, stale map[string]int) (string, []engine.Replacement) {
+	if len(entries) == 0 {
+		return content, entries
+	}
+	var b strings.Builder
+	kept := make([]engine.Replacement, 0, len(entries))
+	last := 0
+	for i := 0; i < len(entries); {
+		r := entries[i]
+		end := r.Offset + r.Length
+		j := i + 1
+		for j < len(entries) && entries[j].Offset+entries[j].Length <= end {
+			j++
+		}
+		if r.Offset < last || end > len(content) || content[r.Offset:end] != r.Target {
+			i = j // 与之前的记录部分重叠，或与内容不符
+			continue
+		}
+		children := make([]engine.Replacement, 0, j-i-1)
+		for _, c := range entries[i+1 : j] {
+			c.Offset -= r.Offset
+			children = append(children, c)
+		}
+
+		b.WriteString(content[last:r.Offset])
+		start := b.Len()
+		var text string
+		var moved []engine.Replacement
+		rule, ok := selected[r.Rule]
+		if ok && !rule.Produces(r.Source, r.Target) {
+			stale[r.Rule]++
+			ok = false
+		}
+		if ok {
+			counts[r.Rule]++
+			text, moved = revertEntries(r.Source, locateIn(r.Source, children), selected, counts, stale)
+		} else {
+			text, moved = revertEntries(r.Target, children, selected, counts, stale)
+			// 外层的原文中同样撤销，之后撤销外层时得到的是原文
+			r.Source, _ = revertEntries(r.Source, locateIn(r.Source, children), selected, map[string]int{}, map[string]int{})
+			r.Offset, r.Length, r.Target = start, len(text), text
+			kept = append(kept, r)
+		}
+		b.WriteString(text)
+		for _, c := range moved {
+			c.Offset += start
+			kept = append(kept, c)
+		}
+		last, i = end, j
+	}
+	b.WriteString(content[last:])
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Offset < kept[j].Offset })
+	return b.String(), kept
+}
+
+// locateIn 在外层替换的原文中依次查找嵌套记录的译文，返回找到的记录 (位置换算为原文中的偏移)
+func locateIn(source string, children []engine.Replacement) []engine.Replacement {
+	var located []engine.Replacement
+	from := 0
+	for _, c := range children {
+		k := strings.Index(source[from:], c.Target)
+		if k < 0 {
+			continue
+		}
+		c.Offset = from + k
+		located = append(located, c)
+		from = c.Offset
+	}
+	return located
+}
+
+// revertInverse 用选中规则的反向规则撤销 (按汉化的相反顺序)，返回结果和无法撤销的规则，
+// 每条规则撤销的次数累加到 counts。与目标中其他规则译文相同的规则无法确定原文，不撤销。
+// 其他规则的译文 (不论所在阶段在前在后，都没有被撤销) 作为保护规则原样输出 (见 guardRules)
+func (t *Translator) revertInverse(ctx context.Context, target *targets.Target, content string, selected []selectedRule, counts map[string]int) (string, []IrreversibleRule, error) {
+	froms := make(map[string][]string) // 译文 -> 目标所有规则中的原文
+	for _, set := range t.ruleSets(target) {
+		for _, phase := range set.Phases {
+			for _, rule := range phase.Rules() {
+				if rule.Scope.AppliesTo(target.ID) && !containsText(froms[rule.To], rule.From) {
+					froms[rule.To] = append(froms[rule.To], rule.From)
+				}
+			}
+		}
+	}
+
+	var skipped []IrreversibleRule
+	byOrder := make(map[int][]engine.Rule)
+	ids := make(map[string]string) // 反向规则 -> 规则 ID
+	for _, s := range selected {
+		if len(froms[s.rule.To]) > 1 {
+			skipped = append(skipped, IrreversibleRule{Target: target.ID, Kind: s.rule.Kind, To: s.rule.To, From: froms[s.rule.To], Reason: "多条规则的译文相同，无法确定原文 (使用 apply --trace 汉化后可以准确撤销)"})
+			continue
+		}
+		inv, err := s.rule.Inverse()
+		if err != nil {
+			skipped = append(skipped, IrreversibleRule{Target: target.ID, Kind: s.rule.Kind, To: s.rule.To, From: []string{s.rule.From}, Reason: err.Error()})
+			continue
+		}
+		byOrder[s.order] = append(byOrder[s.order], inv)
+		ids[inverseKey(inv.Kind, inv.From, inv.To)] = s.id
+	}
+	if len(byOrder) == 0 {
+		return content, skipped, nil
+	}
+
+	orders := make([]int, 0, len(byOrder))
+	for order := range byOrder {
+		orders = append(orders, order)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(orders)))
+	phases := t.forwardPhases(target)
+	set := &engine.RuleSet{Name: "revert"}
+	for _, order := range orders {
+		invs := byOrder[order]
+		froms := make(map[string]bool)
+		for _, inv := range invs {
+			froms[inv.From] = true
+		}
+		rules := append(invs, guardRules(phases, froms)...)
+		set.Phases = append(set.Phases, engine.NewPhase(fmt.Sprintf("revert-%d", order), engine.CategoryNormal, func() []engine.Rule { return rules }))
+	}
+	out, stats, err := engine.ApplyTarget(ctx, target.ID, content, set)
+	if err != nil {
+		return content, skipped, err
+	}
+	for _, hit := range stats.Rules {
+		if id, ok := ids[inverseKey(hit.Kind, hit.From, hit.To)]; ok {
+			counts[id] += hit.Count
+		}
+	}
+	return out, skipped, nil
+}
+
+// inverseKey 反向规则的命中统计与规则 ID 对应时使用的键
+func inverseKey(kind, from, to string) string {
+	return kind + "\x00" + from + "\x00" + to
+}
+
+// installBackups 返回安装 (或扩展目录) 的所有备份，按时间倒序排列
+func (t *Translator) installBackups(ctx context.Context, root string) []*backup.Backup {
+	if t.Backups.Root == "" {
+		return nil
+	}
+	all, err := t.Backups.List(ctx)
+	if err != nil {
+		return nil
+	}
+	abs, _ := filepath.Abs(root)
+	var backups []*backup.Backup
+	for _, b := range all {
+		if p, _ := filepath.Abs(b.Record.InstallPath); p == abs {
+			backups = append(backups, b)
+		}
+	}
+	return backups
+}
+
+// revertChecksums 处理写回文件在 product.json 中的校验和 (只有 Antigravity 的目标登记了校验和)
+// 加回的校验和取自最近一次仍含有这些校验和的备份中的 product.json
+func (t *Translator) revertChecksums(ctx context.Context, result *RevertResult, root string, backups []*backup.Backup, written []targets.File) {
+	var restore, remove []targets.File
+	for _, f := range written {
+		i := slices.IndexFunc(result.Files, func(rf RevertedFile) bool { return rf.Path == f.Path })
+		if result.Files[i].Pristine {
+			restore = append(restore, f)
+		} else {
+			remove = append(remove, f)
+		}
+	}
+	productJsonPath := checksum.ProductJSONPath(root)
+	if keys := targets.ChecksumKeys(restore); len(keys) > 0 {
+		var original []byte
+		for _, b := range backups {
+			content, ok, err := b.OriginalContent(productJsonPath)
+			if ok && err == nil && slices.ContainsFunc(keys, func(key string) bool { return bytes.Contains(content, []byte(`"`+key+`"`)) }) {
+				original = content
+				break
+			}
+		}
+		if original == nil {
+			result.Warnings = append(result.Warnings, NewProblem(CodeProductJSON, productJsonPath, "备份中没有含校验和的 product.json，无法加回已恢复为原版的文件的校验和"))
+		} else {
+			actions, err := checksum.Restore(ctx, root, original, keys)
+			result.Checksums = append(result.Checksums, actions...)
+			if err != nil {
+				result.Warnings = append(result.Warnings, NewProblem(CodeProductJSON, productJsonPath, "恢复校验和失败: %v", err))
+			}
+		}
+	}
+	if keys := targets.ChecksumKeys(remove); len(keys) > 0 {
+		actions, err := checksum.Remove(ctx, root, keys)
+		result.Checksums = append(result.Checksums, actions...)
+		if err != nil {
+			result.Warnings = append(result.Warnings, NewProblem(CodeProductJSON, productJsonPath, "处理校验和失败: %v", err))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+)
+
+// TestRevertInverse 没有替换记录时按反向规则撤销: 其他规则的译文受保护，写回前备份当前内容
+func TestRevertInverse(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	user := &rules.UserRules{}
+	user.Add(rules.UserRule{Pack: "main", From: "Open Settings now", To: "立即打开设置"})
+	user.Add(rules.UserRule{Pack: "main", From: "Settings", To: "设置"})
+	tr := &Translator{Backups: backup.Store{Root: filepath.Join(dir, "backups")}, UserRules: user}
+
+	target := targets.Lookup("antigravity.main")
+	translated, _, err := tr.TranslateContent(ctx, target, "a(`Open Settings now`);b(`Settings`);")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a(`立即打开设置`);b(`设置`);"; translated != want {
+		t.Fatalf("汉化结果 %q，want %q", translated, want)
+	}
+	path := filepath.Join(dir, "main.js")
+	if err := os.WriteFile(path, []byte(translated), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []targets.File{{Path: path, Target: target}}
+	result := tr.Revert(ctx, "antigravity", dir, files, RevertSelector{Pattern: regexp.MustCompile(`^设置$`)}, false)
+	if len(result.Errors) > 0 {
+		t.Fatalf("撤销失败: %v", result.Errors)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a(`立即打开设置`);b(`Settings`);"; string(got) != want {
+		t.Errorf("撤销结果 %q，want %q", got, want)
+	}
+
+	// 撤销前的内容在本次备份中，撤销的规则记入本次备份的记录
+	if result.BackupID == "" {
+		t.Fatal("没有创建备份")
+	}
+	b, err := backup.Open(filepath.Join(tr.Backups.Root, result.BackupID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, ok, err := b.OriginalContent(path); !ok || err != nil || string(content) != translated {
+		t.Errorf("备份内容 %q (ok=%v, err=%v)，want %q", content, ok, err, translated)
+	}
+	if len(b.Record.Reverted) != 1 || b.Record.Reverted[0].Count != 1 {
+		t.Errorf("备份记录中撤销的规则: %+v", b.Record.Reverted)
+	}
+}
@@ -0,0 +1,211 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+
+	"antigravity_translator/literals"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 失效规则与候选更新
+// ========================================
+//
+// 上游更新后，普通翻译的原文常常只改了标点、大小写或一两个单词，规则就不再命中。
+// Stale 找出这类规则，在 bundle 的字符串字面量中查找相近的文本，建议沿用旧译文的新规则。
+
+// DefaultMinSimilarity 候选字面量的默认最低相似度
+const DefaultMinSimilarity = 0.8
+
+// maxSuggestions 每条失效规则最多列出的候选数
+const maxSuggestions = 3
+
+// staleTarget 一个目标的文件内容和规则命中
+type staleTarget struct {
+	target   *targets.Target
+	contents [][]byte
+	hits     map[string]int // 阶段类型 + 原文 -> 命中次数
+}
+
+// staleGroup 同一规则包中去掉引号后原文相同的规则 (如 Continue 规则的 "x"、'x'、`x` 三种形式)
+type staleGroup struct {
+	rule  StaleRule
+	text  string
+	quote byte
+	live  bool // 任意一种形式命中、原文或译文仍在文件中
+}
+
+// Stale 检查某个分组在根目录下的目标文件中失效的整字面量规则 (普通翻译中原文是完整字符串字面量、
+// 没有锚点的规则)，并为每条规则列出最多 3 个相似度不低于 minSimilarity 的候选字面量
+// 只读取文件，不修改任何内容
+func (t *Translator) Stale(ctx context.Context, group, root string, files []targets.File, minSimilarity float64) *StaleResult {
+	result := NewStaleResult(group, root)
+	if minSimilarity <= 0 {
+		minSimilarity = DefaultMinSimilarity
+	}
+
+	// 1. 翻译 (只在内存中) 以获得每条规则的命中次数
+	var order []*staleTarget
+	byTarget := make(map[string]*staleTarget)
+	for _, tf := range t.translateFiles(ctx, files) {
+		if tf.err != nil {
+			result.Errors = append(result.Errors, *tf.err)
+			continue
+		}
+		st := byTarget[tf.file.Target.ID]
+		if st == nil {
+			st = &staleTarget{target: tf.file.Target, hits: make(map[string]int)}
+			byTarget[tf.file.Target.ID] = st
+			order = append(order, st)
+		}
+		st.contents = append(st.contents, tf.original)
+		for _, hit := range tf.stats.Rules {
+			st.hits[hit.Kind+"\x00"+hit.From] += hit.Count
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result
+	}
+
+	// 2. 找出失效的规则并在字面量中查找候选
+	id := 0
+	for _, st := range order {
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeCanceled, root, "已取消"))
+			return result
+		}
+		groups, known := t.staleGroups(st)
+		var candidates []literals.Literal
+		if len(groups) > 0 {
+			candidates = staleCandidates(st.contents, known)
+		}
+		for _, g := range groups {
+			if g.live {
+				continue
+			}
+			g.rule.Suggestions = suggest(g, candidates, minSimilarity)
+			for i := range g.rule.Suggestions {
+				id++
+				g.rule.Suggestions[i].ID = id
+			}
+			result.Rules = append(result.Rules, g.rule)
+		}
+	}
+	return result
+}
+
+// staleGroups 按规则包和去掉引号后的原文对目标的整字面量规则分组 (跳过已被用户规则取代的规则)，
+// 同时返回所有规则原文 (去掉引号，包括用户规则) 的集合
+func (t *Translator) staleGroups(st *staleTarget) ([]*staleGroup, map[string]bool) {
+	var groups []*staleGroup
+	byText := make(map[string]*staleGroup)
+	known := make(map[string]bool)
+	replaced := make(map[string]bool) // 已被用户规则取代的内置规则
+	if t.UserRules != nil {
+		for _, r := range t.UserRules.Rules {
+			if text, _, ok := literals.Unquote(r.From); ok {
+				known[text] = true
+			}
+			replaced[r.Pack+"\x00"+r.Replaces] = true
+		}
+	}
+	for _, pack := range st.target.RulePacks {
+		if t.PackEnabled != nil && !t.PackEnabled(pack) {
+			continue
+		}
+		set := rules.Lookup(pack)
+		if set == nil {
+			continue
+		}
+		for _, phase := range set.Phases {
+			for _, rule := range phase.Rules() {
+				text, quote, ok := literals.Unquote(rule.From)
+				if !ok || rule.IsPattern() || rule.Anchor != nil || !rule.Scope.AppliesTo(st.target.ID) {
+					continue
+				}
+				known[text] = true
+				if replaced[pack+"\x00"+rule.From] {
+					continue
+				}
+				key := pack + "\x00" + text
+				g := byText[key]
+				if g == nil {
+					g = &staleGroup{
+						rule:  StaleRule{Target: st.target.ID, Pack: pack, Phase: rule.Kind, From: rule.From, To: rule.To, Suggestions: []Suggestion{}},
+						text:  text,
+						quote: quote,
+					}
+					byText[key] = g
+					groups = append(groups, g)
+				}
+				if st.hits[rule.Kind+"\x00"+rule.From] > 0 {
+					g.live = true
+					continue
+				}
+				for _, content := range st.contents {
+					if bytes.Contains(content, []byte(rule.From)) || bytes.Contains(content, []byte(rule.To)) {
+						g.live = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return groups, known
+}
+
+// staleCandidates 返回可以作为候选的字面量 (去重): 排除已汉化的、已有规则的和带 ${...} 表达式的
+func staleCandidates(contents [][]byte, known map[string]bool) []literals.Literal {
+	var candidates []literals.Literal
+	seen := make(map[string]bool)
+	for _, content := range contents {
+		for _, lit := range literals.Extract(content) {
+			text := lit.Text()
+			if seen[lit.Raw] || strings.TrimSpace(text) == "" || known[text] || literals.HasCJK(text) ||
+				(lit.Quote == '`' && strings.Contains(text, "${")) {
+				continue
+			}
+			seen[lit.Raw] = true
+			candidates = append(candidates, lit)
+		}
+	}
+	return candidates
+}
+
+// suggest 按相似度从高到低返回失效规则的候选更新，译文沿用旧译文并换成候选字面量的引号
+func suggest(g *staleGroup, candidates []literals.Literal, minSimilarity float64) []Suggestion {
+	toText, _, ok := literals.Unquote(g.rule.To)
+	if !ok {
+		return []Suggestion{}
+	}
+	suggestions := []Suggestion{}
+	for _, lit := range candidates {
+		text := lit.Text()
+		if !literals.LengthCompatible(g.text, text, minSimilarity) {
+			continue
+		}
+		if lit.Quote != g.quote && strings.IndexByte(toText, lit.Quote) >= 0 {
+			continue // 旧译文中含有新引号，换引号后不再是合法的字面量
+		}
+		score := literals.Similarity(g.text, text)
+		if score < minSimilarity {
+			continue
+		}
+		q := string(lit.Quote)
+		suggestions = append(suggestions, Suggestion{From: lit.Raw, To: q + toText + q, Similarity: score})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Similarity != suggestions[j].Similarity {
+			return suggestions[i].Similarity > suggestions[j].Similarity
+		}
+		return suggestions[i].From < suggestions[j].From
+	})
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	return suggestions
+}
@@ -0,0 +1,169 @@
+package translator
+
+import (
+	"context"
+	"os"
+
+	"antigravity_translator/literals"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 版本之间的字符串比较
+// ========================================
+//
+// Antigravity 发布新版本时，比较新旧两个原版 bundle 中的字符串字面量 (按解码后的文本，不受引号和
+// 转义写法和模板中压缩后的变量名影响)，列出每个目标新增、删除和被小幅改动的界面文字，以及因删除而失效的规则。
+
+// DefaultModifiedSimilarity 删除的字面量与新增的字面量配对为 "修改" 的默认最低相似度
+const DefaultModifiedSimilarity = 0.6
+
+// BundleFiles 一个目标在某个版本中的文件 (Continue 的界面代码可能分布在多个分块中)
+type BundleFiles struct {
+	Target *targets.Target
+	Paths  []string
+}
+
+// StringsDiffOptions 字符串比较选项
+type StringsDiffOptions struct {
+	MinSimilarity float64 // 配对为修改的最低相似度，0 表示 DefaultModifiedSimilarity
+	All           bool    // 比较所有字面量，默认只比较像界面文字的字面量 (见 literals.LooksLikeText)
+}
+
+// bundleStrings 一个版本中的字面量: 按首次出现的顺序排列的解码文本 (模板表达式统一为 ${})，
+// 以及每个文本首次出现时的原始字面量
+type bundleStrings struct {
+	order []string
+	raw   map[string]string
+}
+
+// DiffStrings 比较同一目标在新旧两个版本中的字符串字面量
+// oldName、newName 只用于结果展示 (文件路径或备份 ID)；只在一个版本中出现的目标报告警告并跳过
+func (t *Translator) DiffStrings(ctx context.Context, oldName, newName string, oldFiles, newFiles []BundleFiles, opts StringsDiffOptions) *StringsDiffResult {
+	result := NewStringsDiffResult(oldName, newName)
+	if opts.MinSimilarity <= 0 {
+		opts.MinSimilarity = DefaultModifiedSimilarity
+	}
+	oldByTarget, newByTarget := bundlesByTarget(oldFiles), bundlesByTarget(newFiles)
+
+	for _, target := range targets.All() {
+		o, n := oldByTarget[target.ID], newByTarget[target.ID]
+		switch {
+		case o == nil && n == nil:
+			continue
+		case o == nil:
+			result.Warnings = append(result.Warnings, NewProblem(CodeFileNotFound, oldName, "旧版本中没有 %s 的文件，跳过", target.ID))
+			continue
+		case n == nil:
+			result.Warnings = append(result.Warnings, NewProblem(CodeFileNotFound, newName, "新版本中没有 %s 的文件，跳过", target.ID))
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeCanceled, "", "已取消"))
+			return result
+		}
+
+		oldStrings, p := readBundleStrings(o.Paths, opts.All)
+		if p == nil {
+			var newStrings bundleStrings
+			newStrings, p = readBundleStrings(n.Paths, opts.All)
+			if p == nil {
+				result.Targets = append(result.Targets, t.diffTarget(ctx, target, o, n, oldStrings, newStrings, opts.MinSimilarity))
+				continue
+			}
+		}
+		result.Errors = append(result.Errors, *p)
+	}
+	return result
+}
+
+// bundlesByTarget 按目标 ID 合并文件列表
+func bundlesByTarget(bundles []BundleFiles) map[string]*BundleFiles {
+	byTarget := make(map[string]*BundleFiles)
+	for _, b := range bundles {
+		if merged := byTarget[b.Target.ID]; merged != nil {
+			merged.Paths = append(merged.Paths, b.Paths...)
+			continue
+		}
+		copied := BundleFiles{Target: b.Target, Paths: append([]string(nil), b.Paths...)}
+		byTarget[b.Target.ID] = &copied
+	}
+	return byTarget
+}
+
+// readBundleStrings 读取文件并提取字面量 (按解码后的文本去重)，all 为 false 时只保留像界面文字的字面量
+func readBundleStrings(paths []string, all bool) (bundleStrings, *Problem) {
+	bs := bundleStrings{raw: make(map[string]string)}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			p := NewProblem(CodeReadFailed, path, "读取失败: %v", err)
+			return bs, &p
+		}
+		for _, lit := range literals.Extract(content) {
+			value := lit.Value()
+			if lit.Quote == '`' {
+				value = literals.NormalizeTemplate(value)
+			}
+			if _, ok := bs.raw[value]; ok || (!all && !literals.LooksLikeText(value)) {
+				continue
+			}
+			bs.raw[value] = lit.Raw
+			bs.order = append(bs.order, value)
+		}
+	}
+	return bs, nil
+}
+
+// diffTarget 比较一个目标的字面量: 删除的字面量与最相近的新增字面量 (相似度不低于 minSimilarity) 配对为修改
+func (t *Translator) diffTarget(ctx context.Context, target *targets.Target, o, n *BundleFiles, before, after bundleStrings, minSimilarity float64) TargetDiff {
+	diff := TargetDiff{Target: target.ID, OldFiles: o.Paths, NewFiles: n.Paths, Added: []string{}, Removed: []RemovedString{}, Modified: []ModifiedString{}}
+
+	var added []string
+	for _, value := range after.order {
+		if _, ok := before.raw[value]; !ok {
+			added = append(added, value)
+		}
+	}
+	paired := make([]bool, len(added))
+	for _, value := range before.order {
+		if _, ok := after.raw[value]; ok {
+			continue
+		}
+		best, bestScore := -1, 0.0
+		for i, candidate := range added {
+			if paired[i] || !literals.LengthCompatible(value, candidate, minSimilarity) {
+				continue
+			}
+			if score := literals.Similarity(value, candidate); score >= minSimilarity && score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		rules := t.rulesFor(ctx, target, before.raw[value])
+		if best < 0 {
+			diff.Removed = append(diff.Removed, RemovedString{Text: before.raw[value], Rules: rules})
+			continue
+		}
+		paired[best] = true
+		diff.Modified = append(diff.Modified, ModifiedString{Old: before.raw[value], New: after.raw[added[best]], Similarity: bestScore, Rules: rules})
+	}
+	for i, value := range added {
+		if !paired[i] {
+			diff.Added = append(diff.Added, after.raw[value])
+		}
+	}
+	return diff
+}
+
+// rulesFor 返回目标的规则中命中这个字面量的规则 (只按字面量本身匹配，依赖属性或调用位置的锚定规则不会列出)
+func (t *Translator) rulesFor(ctx context.Context, target *targets.Target, raw string) []RuleRef {
+	refs := []RuleRef{}
+	_, stats, err := t.TranslateContent(ctx, target, raw)
+	if err != nil {
+		return refs
+	}
+	for _, hit := range stats.Rules {
+		refs = append(refs, RuleRef{Kind: hit.Kind, From: hit.From, To: hit.To})
+	}
+	return refs
+}
@@ -0,0 +1,199 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 替换记录与译文反查 (explain)
+// ========================================
+//
+// apply --trace 在每个汉化的文件旁写入替换记录 (文件名加 TraceSuffix)，记录每处替换的位置、规则 ID、
+// 原文和译文 (见 engine.ApplyTargetTraced)。用户截图报告某段译文有问题时，Explain 在目标文件中查找这段文字，
+// 按记录找出产生它的规则及其在规则表源码中的位置；没有记录 (或文件已被修改) 时列出译文包含这段文字的规则。
+
+// TraceSuffix 替换记录文件名的后缀
+const TraceSuffix = ".trace.json"
+
+// explainContext Explain 结果中译文前后展示的字节数
+const explainContext = 60
+
+// TraceFile 替换记录文件
+type TraceFile struct {
+	Target       string               `json:"target"`
+	SHA256       string               `json:"sha256"` // 汉化后文件内容的哈希，文件被修改后记录中的位置不再可靠
+	Timestamp    string               `json:"timestamp"`
+	Bilingual    *backup.Bilingual    `json:"bilingual,omitempty"` // 汉化时的双语显示设置，旧版本的记录中没有
+	Replacements []engine.Replacement `json:"replacements"`
+}
+
+// TracePath 返回文件的替换记录路径
+func TracePath(path string) string {
+	return path + TraceSuffix
+}
+
+// WriteTrace 写入文件的替换记录，content 为汉化后的文件内容，bilingual 为汉化时的双语显示设置
+func WriteTrace(f targets.File, content []byte, replacements []engine.Replacement, bilingual *backup.Bilingual) error {
+	if replacements == nil {
+		replacements = []engine.Replacement{}
+	}
+	data, err := json.Marshal(TraceFile{
+		Target:       f.Target.ID,
+		SHA256:       backup.HashContent(content),
+		Timestamp:    time.Now().Format("2006-01-02 15:04:05"),
+		Bilingual:    bilingual,
+		Replacements: replacements,
+	})
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(TracePath(f.Path), data)
+}
+
+// ReadTrace 读取文件的替换记录，没有记录时返回 nil 和 os.IsNotExist 可识别的错误
+func ReadTrace(path string) (*TraceFile, error) {
+	data, err := os.ReadFile(TracePath(path))
+	if err != nil {
+		return nil, err
+	}
+	var tf TraceFile
+	if err := json.Unmarshal(data, &tf); err != nil {
+		return nil, err
+	}
+	return &tf, nil
+}
+
+// RemoveTrace 删除文件的替换记录 (文件被还原或重新汉化后记录不再有效)，没有记录时什么也不做
+func RemoveTrace(path string) {
+	os.Remove(TracePath(path))
+}
+
+// Explain 在目标文件中查找一段文字，报告产生它的规则
+func (t *Translator) Explain(ctx context.Context, text string, files []targets.File) *ExplainResult {
+	result := NewExplainResult(text)
+	for _, f := range files {
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeCanceled, "", "已取消"))
+			return result
+		}
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeReadFailed, f.Path, "读取失败: %v", err))
+			continue
+		}
+		var offsets []int
+		for i := 0; ; {
+			k := bytes.Index(content[i:], []byte(text))
+			if k < 0 {
+				break
+			}
+			offsets = append(offsets, i+k)
+			i += k + len(text)
+		}
+		if len(offsets) == 0 {
+			continue
+		}
+
+		trace, err := ReadTrace(f.Path)
+		switch {
+		case err != nil && !os.IsNotExist(err):
+			result.Warnings = append(result.Warnings, NewProblem(CodeReadFailed, TracePath(f.Path), "读取替换记录失败: %v", err))
+			trace = nil
+		case trace != nil && trace.SHA256 != backup.HashContent(content):
+			result.Warnings = append(result.Warnings, NewProblem(CodeTraceStale, TracePath(f.Path), "文件在 %s 汉化后已被修改，替换记录不再可靠", trace.Timestamp))
+			trace = nil
+		}
+
+		ft := t.forContent(ctx, f.Path, content) // 按汉化时的双语显示设置查找规则
+		var candidates []ExplainRule
+		if trace == nil {
+			candidates = ft.rulesProducing(f.Target, text)
+		}
+		for _, offset := range offsets {
+			m := ExplainMatch{Target: f.Target.ID, Path: f.Path, Offset: offset, Line: lineAt(content, offset), Context: contextAround(content, offset, offset+len(text)), Traced: trace != nil, Rules: []ExplainRule{}}
+			if trace == nil {
+				m.Rules = append(m.Rules, candidates...)
+			} else {
+				for _, r := range trace.Replacements {
+					if r.Offset < offset+len(text) && r.Offset+r.Length > offset {
+						m.Rules = append(m.Rules, ft.explainRule(f.Target, r.Rule, r.Source, r.Target))
+					}
+				}
+			}
+			result.Matches = append(result.Matches, m)
+		}
+	}
+	return result
+}
+
+// explainRule 按规则 ID 查找规则及其源码位置
+func (t *Translator) explainRule(target *targets.Target, id, source, produced string) ExplainRule {
+	er := ExplainRule{ID: id, Source: source, Produced: produced, Stale: true}
+	for _, set := range t.ruleSets(target) {
+		rule, index, ok := set.Rule(id)
+		if !ok {
+			continue
+		}
+		if !rule.Produces(source, produced) {
+			break
+		}
+		er.Kind, er.From, er.To, er.Anchor, er.Stale = rule.Kind, rule.From, rule.To, rule.Anchor.String(), false
+		_, phase, _, _ := engine.ParseRuleID(id)
+		er.Position = engine.RulePosition(set.Name, phase, index)
+		er.File, er.Line = ruleSource(set.Name, rule)
+		break
+	}
+	return er
+}
+
+// rulesProducing 返回目标的规则中译文包含这段文字的规则 (没有替换记录时的候选)
+func (t *Translator) rulesProducing(target *targets.Target, text string) []ExplainRule {
+	var found []ExplainRule
+	for _, set := range t.ruleSets(target) {
+		for _, phase := range set.Phases {
+			keys := phase.Keys()
+			for i, rule := range phase.Rules() {
+				if !rule.Scope.AppliesTo(target.ID) || !strings.Contains(rule.To, text) {
+					continue
+				}
+				er := ExplainRule{ID: engine.RuleID(set.Name, phase.Kind, keys[i]), Position: engine.RulePosition(set.Name, phase.Kind, i+1), Kind: rule.Kind, From: rule.From, To: rule.To, Anchor: rule.Anchor.String()}
+				er.File, er.Line = ruleSource(set.Name, rule)
+				found = append(found, er)
+			}
+		}
+	}
+	return found
+}
+
+// ruleSource 返回规则所在的文件和行号；用户规则 (阶段类型为 "user") 在用户规则文件中，不给出行号
+func ruleSource(pack string, rule engine.Rule) (string, int) {
+	if rule.Kind == "user" {
+		return "user_rules.json", 0
+	}
+	if file, line, ok := rules.Source(pack, rule.From, rule.To); ok {
+		return "rules/" + file, line
+	}
+	return "", 0
+}
+
+// contextAround 返回 [start, end) 前后各 explainContext 字节的内容 (按 UTF-8 字符边界截断)
+func contextAround(content []byte, start, end int) string {
+	from, to := max(0, start-explainContext), min(len(content), end+explainContext)
+	for from > 0 && (content[from]&0xC0) == 0x80 {
+		from--
+	}
+	for to < len(content) && (content[to]&0xC0) == 0x80 {
+		to++
+	}
+	return string(content[from:to])
+}
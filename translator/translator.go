@@ -0,0 +1,632 @@
+// Package translator 组合 engine、rules、targets、backup 和 checksum，提供汉化、还原、
+// 备份列表和状态查询等核心操作。
+//
+// 控制台菜单、命令行和其他 Go 程序都通过 Translator 调用这些操作，结果以结构体返回，
+// 由调用方决定如何展示。
+package translator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/checksum"
+	"antigravity_translator/engine"
+	"antigravity_translator/rules"
+	"antigravity_translator/targets"
+)
+
+// Translator 核心操作的入口，零值之外至少需要设置 Backups.Root
+type Translator struct {
+	Backups     backup.Store
+	Jobs        int                    // 并行翻译的最大文件数，0 表示使用 CPU 核数
+	Progress    func(ProgressEvent)    // 每翻译完一个文件调用一次 (在调用 Apply 的协程中串行调用)，可为空
+	PackEnabled func(pack string) bool // 规则包是否启用，为空时全部启用
+	Trace       bool                   // 汉化时在每个文件旁写入替换记录 (见 trace.go)
+	Strict      bool                   // 严格模式: 规则命中次数不符合预期 (Rule.Expect) 时报告错误并且不修改任何文件，否则只报告警告
+	UserRules   *rules.UserRules       // 用户规则文件，在对应的规则包之前应用，可为空
+	HashesPath  string                 // 原版文件哈希记录的路径 (见 backup.Hashes)，汉化时记录、反向翻译时校验；为空时不使用
+	Bilingual   *BilingualOptions      // 双语显示 (译文后带上原文，见 bilingual.go)，为空时只显示译文
+
+	inverseMu sync.Mutex
+	inverse   map[string]*inverseRules // 按目标缓存的反向规则 (见 untranslate.go)
+
+	bilingualMu   sync.Mutex
+	bilingualSets map[*engine.RuleSet]*engine.RuleSet // 规则集 -> 双语版本
+	variants      map[string]*Translator              // 双语显示设置 -> 使用该设置的 Translator (见 withBilingual)
+}
+
+// ========================================
+// 翻译
+// ========================================
+
+// TranslateContent 对目标依次应用其规则包 (每个规则包之前先应用该包的用户规则)，跳过未启用的规则包和只对其他目标生效的规则
+func (t *Translator) TranslateContent(ctx context.Context, target *targets.Target, content string) (string, engine.Stats, error) {
+	return engine.ApplyTarget(ctx, target.ID, content, t.ruleSets(target)...)
+}
+
+// TraceContent 同 TranslateContent，同时返回每处替换的记录 (见 engine.ApplyTargetTraced)
+func (t *Translator) TraceContent(ctx context.Context, target *targets.Target, content string) (string, engine.Stats, []engine.Replacement, error) {
+	return engine.ApplyTargetTraced(ctx, target.ID, content, t.ruleSets(target)...)
+}
+
+// ruleSets 返回目标按顺序应用的规则集 (每个启用的规则包之前是该包的用户规则)，设置了双语显示时为双语版本
+func (t *Translator) ruleSets(target *targets.Target) []*engine.RuleSet {
+	var sets []*engine.RuleSet
+	for _, name := range target.RulePacks {
+		if t.PackEnabled != nil && !t.PackEnabled(name) {
+			continue
+		}
+		if set := t.UserRules.RuleSet(name); set != nil {
+			sets = append(sets, set)
+		}
+		if set := rules.Lookup(name); set != nil {
+			sets = append(sets, set)
+		}
+	}
+	if t.Bilingual != nil {
+		for i, set := range sets {
+			sets[i] = t.bilingualSet(set)
+		}
+	}
+	return sets
+}
+
+// workers 返回翻译 n 个文件时使用的并发数
+func (t *Translator) workers(n int) int {
+	workers := t.Jobs
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	return workers
+}
+
+// translatedFile 已在内存中完成翻译、尚未写回的文件
+type translatedFile struct {
+	file       targets.File
+	original   []byte
+	translated string
+	stats      engine.Stats
+	trace      []engine.Replacement // Translator.Trace 为 true 时的替换记录
+	err        *Problem
+}
+
+// translateFiles 用有界的工作池并行读取并翻译文件 (只读，不修改磁盘)
+// 结果顺序与 files 一致；每完成一个文件通过 Progress 报告一次进度，报告在调用方协程中串行进行
+func (t *Translator) translateFiles(ctx context.Context, files []targets.File) []translatedFile {
+	results := make([]translatedFile, len(files))
+	jobs := make(chan int)
+	done := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < t.workers(len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = t.translateOne(ctx, files[i])
+				done <- i
+			}
+		}()
+	}
+	go func() {
+		for i := range files {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		close(done)
+	}()
+
+	completed := 0
+	for i := range done {
+		completed++
+		if t.Progress == nil {
+			continue
+		}
+		event := ProgressEvent{Event: "translated", Target: files[i].Target.ID, Path: files[i].Path, Done: completed, Total: len(files)}
+		if results[i].err != nil {
+			event.Event, event.Error = "failed", results[i].err.Message
+		}
+		t.Progress(event)
+	}
+	return results
+}
+
+// translateOne 读取并翻译单个文件
+func (t *Translator) translateOne(ctx context.Context, f targets.File) translatedFile {
+	tf := translatedFile{file: f}
+	if err := ctx.Err(); err != nil {
+		p := NewProblem(CodeCanceled, f.Path, "已取消")
+		tf.err = &p
+		return tf
+	}
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		p := NewProblem(CodeReadFailed, f.Path, "读取失败: %v", err)
+		tf.err = &p
+		return tf
+	}
+	tf.original = content
+	if t.Trace {
+		tf.translated, tf.stats, tf.trace, err = t.TraceContent(ctx, f.Target, string(content))
+	} else {
+		tf.translated, tf.stats, err = t.TranslateContent(ctx, f.Target, string(content))
+	}
+	if err != nil {
+		p := NewProblem(CodeCanceled, f.Path, "已取消")
+		tf.err = &p
+	}
+	return tf
+}
+
+// expectProblems 把翻译结果中不符合预期命中次数的规则转换为警告 (严格模式下为错误)，
+// 因引号不配对而跳过的匹配总是作为警告报告。文件已经汉化过时，规则的原文已被替换，
+// 命中次数少于预期是正常的，不报告 (只在出现这种情况时才检查文件是否汉化过)
+func (t *Translator) expectProblems(ctx context.Context, result *ApplyResult, translated []translatedFile) {
+	for _, tf := range translated {
+		if tf.err != nil {
+			continue
+		}
+		checked, before := false, false
+		alreadyTranslated := func() bool {
+			if !checked {
+				checked, before = true, t.translatedBefore(ctx, tf)
+			}
+			return before
+		}
+		for _, v := range tf.stats.Violations {
+			if v.Missing && alreadyTranslated() {
+				continue
+			}
+			p := NewProblem(CodeExpectViolated, tf.file.Path, "%s: %s 规则 %q 预期命中%s，实际 %d 次 (上游可能已改动)", tf.file.Target.ID, v.Kind, v.From, v.Expect, v.Count)
+			if v.Skipped > 0 {
+				p.Message += fmt.Sprintf("，另有 %d 处因引号不配对或不在字符串字面量内未替换", v.Skipped)
+			}
+			if t.Strict {
+				result.Errors = append(result.Errors, p)
+			} else {
+				result.Warnings = append(result.Warnings, p)
+			}
+		}
+		for _, s := range tf.stats.Skipped {
+			result.Warnings = append(result.Warnings, NewProblem(CodeQuoteMismatch, tf.file.Path, "%s: %s 规则 %q 有 %d 处匹配两端引号不同或不在字符串字面量内，未替换", tf.file.Target.ID, s.Kind, s.From, s.Count))
+		}
+	}
+}
+
+// ========================================
+// 汉化
+// ========================================
+
+// Apply 备份并汉化某个分组在根目录下的目标文件，需要时处理 product.json 校验和
+// 文件先并行翻译，再按顺序备份、写回: 任何文件翻译或备份失败 (包括 ctx 被取消) 时不修改任何文件，
+// 写回中途失败时用备份还原已写入的文件。每次调用创建一个独立的备份目录和备份记录
+func (t *Translator) Apply(ctx context.Context, group, root string, files []targets.File) *ApplyResult {
+	result := NewApplyResult(group, root)
+
+	// 1. 并行翻译
+	translated := t.translateFiles(ctx, files)
+	failed := false
+	for _, tf := range translated {
+		fr := FileResult{Path: tf.file.Path, Description: tf.file.Target.Description, Target: tf.file.Target.ID, Group: tf.file.Target.Group, Error: tf.err}
+		if tf.err == nil {
+			stats := tf.stats
+			fr.Stats, fr.SizeBefore = &stats, len(tf.original)
+		} else {
+			failed = true
+		}
+		result.Files = append(result.Files, fr)
+	}
+	if failed {
+		result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "部分文件读取失败，未修改任何文件"))
+		return result
+	}
+	t.expectProblems(ctx, result, translated)
+	if len(result.Errors) > 0 {
+		result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "规则命中次数不符合预期 (严格模式)，未修改任何文件"))
+		return result
+	}
+	if ctx.Err() != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "已取消，未修改任何文件"))
+		return result
+	}
+
+	// 2. 创建备份目录
+	if t.Backups.Root == "" {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "未设置备份目录"))
+		return result
+	}
+	b, err := t.Backups.Create(group, root)
+	if err != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "创建备份目录失败: %v", err))
+		return result
+	}
+	result.BackupDir = b.Dir
+	result.BackupID = b.ID
+	b.Record.Bilingual = t.Bilingual.record()
+
+	// 3. 按顺序备份 (备份内容即翻译时读到的原文)
+	for i, tf := range translated {
+		backupFileName, err := b.AddContent(tf.file.Path, tf.original)
+		if err != nil {
+			p := NewProblem(CodeBackupFailed, tf.file.Path, "备份失败: %v", err)
+			result.Files[i].Error = &p
+			failed = true
+			break
+		}
+		result.Files[i].Backup = backupFileName
+	}
+	if failed {
+		b.Discard()
+		result.BackupDir, result.BackupID = "", ""
+		result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "备份失败，未修改任何文件"))
+		return result
+	}
+
+	// 备份 product.json
+	checksumKeys := targets.ChecksumKeys(files)
+	productJsonPath := checksum.ProductJSONPath(root)
+	if len(checksumKeys) > 0 {
+		if _, err := os.Stat(productJsonPath); err == nil {
+			if _, err := b.AddFile(productJsonPath); err != nil {
+				result.Warnings = append(result.Warnings, NewProblem(CodeBackupFailed, productJsonPath, "备份 product.json 失败: %v", err))
+			}
+		}
+	}
+
+	// 保存备份记录 (写回之前保存，中途中断也可以还原)
+	if err := b.Save(); err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupRecord, b.Dir, "保存备份记录失败: %v", err))
+	}
+
+	// 4. 按顺序写回，失败时回滚已写入的文件
+	for i, tf := range translated {
+		if err := WriteFileAtomic(tf.file.Path, []byte(tf.translated)); err != nil {
+			p := NewProblem(CodeWriteFailed, tf.file.Path, "保存失败: %v", err)
+			result.Files[i].Error = &p
+			rollbackWrites(result, translated[:i], b)
+			return result
+		}
+		result.Files[i].SizeAfter = len(tf.translated)
+		result.Files[i].Written = true
+	}
+	t.recordPristine(context.WithoutCancel(ctx), result, translated)
+	for _, tf := range translated {
+		if !t.Trace {
+			RemoveTrace(tf.file.Path) // 之前的记录已经与文件内容不符
+			continue
+		}
+		if err := WriteTrace(tf.file, []byte(tf.translated), tf.trace, t.Bilingual.record()); err != nil {
+			result.Warnings = append(result.Warnings, NewProblem(CodeWriteFailed, TracePath(tf.file.Path), "保存替换记录失败: %v", err))
+		}
+	}
+
+	// 5. 处理 product.json 校验和
+	if len(checksumKeys) > 0 {
+		actions, err := checksum.Remove(context.WithoutCancel(ctx), root, checksumKeys)
+		result.Checksums = actions
+		if os.IsNotExist(err) {
+			result.Warnings = append(result.Warnings, NewProblem(CodeNoProductJSON, productJsonPath, "未找到 product.json，跳过"))
+		} else if err != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeProductJSON, productJsonPath, "处理 product.json 失败: %v", err))
+		}
+	}
+
+	return result
+}
+
+// Preview 只翻译不写回 (apply --dry-run): 结果中包含每个文件的规则命中、模式规则的替换样例
+// 和按目标汇总的命中数，不创建备份，不修改文件和 product.json
+func (t *Translator) Preview(ctx context.Context, group, root string, files []targets.File) *ApplyResult {
+	result := NewApplyResult(group, root)
+	result.DryRun = true
+	byTarget := make(map[string]*TargetHits)
+	rulesHit := make(map[string]map[string]bool)
+	translated := t.translateFiles(ctx, files)
+	t.expectProblems(ctx, result, translated)
+	for _, tf := range translated {
+		fr := FileResult{Path: tf.file.Path, Description: tf.file.Target.Description, Target: tf.file.Target.ID, Group: tf.file.Target.Group, Error: tf.err}
+		if tf.err == nil {
+			stats := tf.stats
+			fr.Stats, fr.SizeBefore, fr.SizeAfter = &stats, len(tf.original), len(tf.translated)
+
+			th := byTarget[fr.Target]
+			if th == nil {
+				th = &TargetHits{Target: fr.Target}
+				byTarget[fr.Target] = th
+				rulesHit[fr.Target] = make(map[string]bool)
+				result.TargetHits = append(result.TargetHits, th)
+			}
+			th.Files++
+			th.Hits += stats.Hits()
+			for _, hit := range stats.Rules {
+				key := hit.Kind + "\x00" + hit.From + "\x00" + hit.Anchor + "\x00" + hit.Scope
+				if !rulesHit[fr.Target][key] {
+					rulesHit[fr.Target][key] = true
+					th.Rules++
+				}
+			}
+		}
+		result.Files = append(result.Files, fr)
+	}
+	return result
+}
+
+// rollbackWrites 写回中途失败时，用备份还原已写入的文件
+// 全部还原成功则删除本次备份，否则保留备份供手动还原
+func rollbackWrites(result *ApplyResult, written []translatedFile, b *backup.Backup) {
+	restored := true
+	for i, tf := range written {
+		if err := WriteFileAtomic(tf.file.Path, tf.original); err != nil {
+			p := NewProblem(CodeWriteFailed, tf.file.Path, "回滚失败: %v", err)
+			result.Files[i].Error = &p
+			restored = false
+			continue
+		}
+		result.Files[i].SizeAfter, result.Files[i].Written = 0, false
+	}
+	if restored {
+		b.Discard()
+		result.BackupDir, result.BackupID = "", ""
+		result.Errors = append(result.Errors, NewProblem(CodeRolledBack, "", "写回失败，已还原所有文件"))
+		return
+	}
+	result.Errors = append(result.Errors, NewProblem(CodeRolledBack, b.Dir, "写回失败且回滚未完成，请使用备份 %s 还原", b.ID))
+}
+
+// WriteFileAtomic 先写入同目录的临时文件再重命名，避免留下写了一半的文件
+func WriteFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// ========================================
+// 规则检查
+// ========================================
+
+// Lint 编译所有内置规则包，报告无法编译的模式规则 (正则语法错误、可以匹配空字符串、
+// 译文引用了不存在的占位符或子匹配等) 和同一阶段中原文重复 (只有第一条生效) 的规则
+func (t *Translator) Lint(ctx context.Context) *LintResult {
+	result := &LintResult{Operation: "lint", Phases: []LintPhase{}, Warnings: []Problem{}, Errors: []Problem{}}
+	for _, name := range rules.Names() {
+		for _, phase := range rules.Lookup(name).Phases {
+			if ctx.Err() != nil {
+				result.Errors = append(result.Errors, NewProblem(CodeCanceled, "", "已取消"))
+				return result
+			}
+			lp := LintPhase{Pack: name, Phase: phase.Kind}
+			seen := make(map[string]bool)
+			for _, rule := range phase.Rules() {
+				lp.Rules++
+				if rule.IsPattern() {
+					lp.Patterns++
+				}
+				if rule.From == "" {
+					result.Errors = append(result.Errors, NewProblem(CodeRuleInvalid, "", "%s/%s: 原文为空 (译文 %q)", name, phase.Kind, rule.To))
+					continue
+				}
+				key := rule.From
+				if rule.Anchor != nil {
+					key += " @" + rule.Anchor.String() // 锚点或作用范围不同的同一原文不算重复
+				}
+				if rule.Scope != nil {
+					key += " #" + rule.Scope.String()
+				}
+				if seen[key] {
+					result.Warnings = append(result.Warnings, NewProblem(CodeRuleDuplicate, "", "%s/%s: 原文重复，只有第一条生效: %q", name, phase.Kind, key))
+				}
+				seen[key] = true
+				if rule.Expect != nil && !rule.Expect.Valid() {
+					result.Errors = append(result.Errors, NewProblem(CodeRuleInvalid, "", "%s/%s: 规则 %q 的预期命中次数无效 (%d 到 %d 次)", name, phase.Kind, rule.From, rule.Expect.Min, rule.Expect.Max))
+				}
+			}
+			for _, err := range phase.Errors() {
+				result.Errors = append(result.Errors, NewProblem(CodeRuleInvalid, "", "%s/%s: %v", name, phase.Kind, err))
+			}
+			result.Phases = append(result.Phases, lp)
+		}
+	}
+	return result
+}
+
+// ========================================
+// 还原与备份列表
+// ========================================
+
+// Summary 转换为备份列表输出项
+func Summary(b *backup.Backup) BackupSummary {
+	return BackupSummary{
+		ID:          b.ID,
+		Path:        b.Dir,
+		Timestamp:   b.Record.Timestamp,
+		BackupType:  b.Record.BackupType,
+		InstallPath: b.Record.InstallPath,
+		Files:       b.Record.Files,
+		Reverted:    b.Record.Reverted,
+		Bilingual:   b.Record.Bilingual,
+	}
+}
+
+// Restore 将备份中的文件写回原始位置
+func (t *Translator) Restore(ctx context.Context, b *backup.Backup) *RestoreResult {
+	result := &RestoreResult{
+		Operation:   "restore",
+		BackupID:    b.ID,
+		InstallPath: b.Record.InstallPath,
+		Files:       []RestoredFile{},
+		Warnings:    []Problem{},
+		Errors:      []Problem{},
+	}
+
+	restored, err := b.Restore(ctx)
+	for _, r := range restored {
+		rf := RestoredFile{Path: r.Path, Backup: r.Backup}
+		backupFilePath := filepath.Join(b.Dir, r.Backup)
+		var p Problem
+		switch {
+		case r.Err == nil:
+		case errors.Is(r.Err, backup.ErrBackupFileMissing):
+			p = NewProblem(CodeBackupFileAbsent, backupFilePath, "%v", r.Err)
+		case errors.Is(r.Err, backup.ErrReadBackup):
+			p = NewProblem(CodeReadFailed, backupFilePath, "%v", r.Err)
+		default:
+			p = NewProblem(CodeWriteFailed, r.Path, "%v", r.Err)
+		}
+		if r.Err != nil {
+			rf.Error = &p
+		} else {
+			RemoveTrace(r.Path)
+		}
+		result.Files = append(result.Files, rf)
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeCanceled, b.Dir, "已取消，部分文件未还原"))
+	}
+	return result
+}
+
+// List 列出备份目录中的所有备份 (先迁移旧版本程序目录下的备份)
+// 同时返回备份本身，供调用方选择后传给 Restore
+func (t *Translator) List(ctx context.Context) (*ListResult, []*backup.Backup) {
+	result := &ListResult{
+		Operation:  "list",
+		BackupRoot: t.Backups.Root,
+		Backups:    []BackupSummary{},
+		Warnings:   []Problem{},
+		Errors:     []Problem{},
+	}
+	if t.Backups.Root == "" {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupNotFound, "", "未设置备份目录"))
+		return result, nil
+	}
+
+	// 迁移旧版本程序目录下的备份
+	migrated, err := t.Backups.MigrateLegacy()
+	if migrated > 0 {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupMigrated, t.Backups.Root, "已将 %d 个旧备份迁移到 %s", migrated, t.Backups.Root))
+	}
+	if err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupMigrated, t.Backups.Root, "迁移旧备份失败: %v", err))
+	}
+
+	backups, err := t.Backups.List(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeReadFailed, t.Backups.Root, "读取备份目录失败: %v", err))
+		return result, nil
+	}
+	for _, b := range backups {
+		result.Backups = append(result.Backups, Summary(b))
+	}
+	return result, backups
+}
+
+// ========================================
+// 汉化状态
+// ========================================
+
+// Status 检查安装目录和 Continue 扩展目录下各目标文件的汉化状态 (路径为空时跳过对应分组)
+func (t *Translator) Status(ctx context.Context, installPath, continueDir string) *StatusResult {
+	result := &StatusResult{
+		Operation:   "status",
+		InstallPath: installPath,
+		Files:       []FileStatus{},
+		Warnings:    []Problem{},
+		Errors:      []Problem{},
+	}
+
+	roots := map[string]string{"antigravity": installPath, "continue": continueDir}
+	var located []targets.File
+	for _, target := range targets.All() {
+		root := roots[target.Group]
+		if root == "" {
+			continue
+		}
+		paths, _ := target.Locator.Locate(ctx, root)
+		if len(paths) == 0 {
+			result.Files = append(result.Files, FileStatus{
+				Path:        filepath.Join(root, target.Locator.String()),
+				Description: target.Description,
+				Target:      target.ID,
+			})
+			continue
+		}
+		for _, path := range paths {
+			fs := FileStatus{Path: path, Description: target.Description, Target: target.ID}
+			content, err := os.ReadFile(path)
+			if err == nil {
+				fs.Exists = true
+				fs.Size = len(content)
+				_, stats, _ := t.TranslateContent(ctx, target, string(content))
+				fs.PendingHits = stats.Hits()
+				located = append(located, targets.File{Target: target, Path: path})
+			} else {
+				result.Warnings = append(result.Warnings, NewProblem(CodeReadFailed, path, "读取失败: %v", err))
+			}
+			result.Files = append(result.Files, fs)
+		}
+	}
+	if ctx.Err() != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeCanceled, "", "已取消"))
+		return result
+	}
+
+	// product.json 校验和状态
+	if keys := targets.ChecksumKeys(located); installPath != "" && len(keys) > 0 {
+		actions, err := checksum.Status(ctx, installPath, keys)
+		if err == nil {
+			result.Checksums = actions
+		} else {
+			result.Warnings = append(result.Warnings, NewProblem(CodeNoProductJSON, checksum.ProductJSONPath(installPath), "未找到 product.json"))
+		}
+	}
+
+	// 最近一次备份
+	if _, backups := t.List(ctx); len(backups) > 0 {
+		for _, b := range backups {
+			if b.Record.InstallPath == "" {
+				continue
+			}
+			if targets.SamePath(b.Record.InstallPath, installPath) || targets.SamePath(b.Record.InstallPath, continueDir) {
+				s := Summary(b)
+				result.LastBackup = &s
+				break
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,388 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/engine"
+	"antigravity_translator/literals"
+	"antigravity_translator/targets"
+)
+
+// ========================================
+// 反向翻译 (没有备份时恢复原版)
+// ========================================
+//
+// 备份丢失时 (如程序和旁边的备份目录一起被删除)，Untranslate 把目标的每条规则反向 (见 engine.Rule.Inverse)，
+// 按汉化的相反顺序应用到已汉化的文件上:
+//   - 译文相同的规则 (如 "Active" 和 "Activity" 都译为 "活动") 反向后无法确定原文，不做替换
+//   - 每个反向阶段之前的阶段产生的译文作为保护规则 (原样输出)，避免后面阶段的短译文反向替换掉其中的一部分
+//     (如 "设置" 反向时不应改动 "打开设置")
+//   - 结果中仍含中文的字符串字面量逐个列出，并给出可能的原文
+//   - 汉化时记录了原版哈希 (Translator.HashesPath) 的文件，比较恢复结果是否与原版完全一致
+
+// guardKind 反向阶段中保护规则的类型 (命中不计入统计)
+const guardKind = "guard"
+
+// inverseRules 一个目标的反向规则集和无法反向的规则
+type inverseRules struct {
+	sets         []*engine.RuleSet
+	irreversible []IrreversibleRule
+}
+
+// inverseFor 返回目标的反向规则集 (按目标缓存)
+func (t *Translator) inverseFor(target *targets.Target) *inverseRules {
+	t.inverseMu.Lock()
+	defer t.inverseMu.Unlock()
+	if inv, ok := t.inverse[target.ID]; ok {
+		return inv
+	}
+	inv := t.buildInverse(target)
+	if t.inverse == nil {
+		t.inverse = make(map[string]*inverseRules)
+	}
+	t.inverse[target.ID] = inv
+	return inv
+}
+
+// forwardPhase 汉化时对目标生效的一个阶段及其规则
+type forwardPhase struct {
+	phase *engine.Phase
+	rules []engine.Rule
+}
+
+// forwardPhases 返回汉化时对目标依次生效的阶段 (与 TranslateContent 的顺序一致)，
+// 每个阶段只保留对目标生效、实际可能命中 (原文不重复) 且原文和译文不同的规则
+func (t *Translator) forwardPhases(target *targets.Target) []forwardPhase {
+	var phases []forwardPhase
+	for _, set := range t.ruleSets(target) {
+		for _, phase := range set.Phases {
+			fp := forwardPhase{phase: phase}
+			seen := make(map[string]bool)
+			for _, rule := range phase.Rules() {
+				key := rule.From + "\x00" + rule.Anchor.String() + "\x00" + rule.Scope.String()
+				if seen[key] || rule.From == rule.To || !rule.Scope.AppliesTo(target.ID) {
+					continue
+				}
+				seen[key] = true
+				fp.rules = append(fp.rules, rule)
+			}
+			phases = append(phases, fp)
+		}
+	}
+	return phases
+}
+
+// buildInverse 构建目标的反向规则集: 阶段按相反顺序排列，每个阶段包含本阶段规则的反向规则和之前阶段译文的保护规则
+func (t *Translator) buildInverse(target *targets.Target) *inverseRules {
+	inv := &inverseRules{irreversible: []IrreversibleRule{}}
+	phases := t.forwardPhases(target)
+
+	// 1. 译文相同而原文不同的规则
+	sources := make(map[string][]string) // 译文 -> 原文
+	kinds := make(map[string]string)
+	for _, fp := range phases {
+		for _, rule := range fp.rules {
+			if !containsText(sources[rule.To], rule.From) {
+				sources[rule.To] = append(sources[rule.To], rule.From)
+			}
+			if kinds[rule.To] == "" {
+				kinds[rule.To] = rule.Kind
+			}
+		}
+	}
+	var ambiguous []string
+	for to, froms := range sources {
+		if len(froms) > 1 {
+			ambiguous = append(ambiguous, to)
+		}
+	}
+	sort.Strings(ambiguous)
+	for _, to := range ambiguous {
+		inv.irreversible = append(inv.irreversible, IrreversibleRule{Target: target.ID, Kind: kinds[to], To: to, From: sources[to], Reason: "多条规则的译文相同，无法确定原文"})
+	}
+
+	// 2. 反向规则，阶段按相反顺序
+	var inversePhases []*engine.Phase
+	for i := len(phases) - 1; i >= 0; i-- {
+		fp := phases[i]
+		var inverted []engine.Rule
+		froms := make(map[string]bool)
+		for _, rule := range fp.rules {
+			if len(sources[rule.To]) > 1 {
+				continue
+			}
+			r, err := rule.Inverse()
+			if err != nil {
+				inv.irreversible = append(inv.irreversible, IrreversibleRule{Target: target.ID, Kind: rule.Kind, To: rule.To, From: []string{rule.From}, Reason: err.Error()})
+				continue
+			}
+			inverted = append(inverted, r)
+			froms[r.From] = true
+		}
+		if len(inverted) == 0 {
+			continue
+		}
+		rules := append(inverted, guardRules(phases[:i], froms)...)
+		inversePhases = append(inversePhases, engine.NewPhase(fp.phase.Kind, fp.phase.Category, func() []engine.Rule { return rules }))
+	}
+	inv.sets = []*engine.RuleSet{{Name: "inverse", Phases: inversePhases}}
+	return inv
+}
+
+// guardRules 返回 phases 中字面量规则译文的保护规则 (原样输出)，译文是本阶段反向规则的原文 (froms) 时除外
+func guardRules(phases []forwardPhase, froms map[string]bool) []engine.Rule {
+	var guards []engine.Rule
+	for _, fp := range phases {
+		for _, rule := range fp.rules {
+			if !rule.IsPattern() && !froms[rule.To] {
+				guards = append(guards, engine.Rule{Kind: guardKind, From: rule.To, To: rule.To})
+			}
+		}
+	}
+	return guards
+}
+
+// containsText 判断列表中是否有给定的文本
+func containsText(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// UntranslateContent 对已汉化的内容应用目标的反向规则，返回结果和反向替换的次数
+func (t *Translator) UntranslateContent(ctx context.Context, target *targets.Target, content string) (string, int, error) {
+	out, stats, err := engine.ApplyTarget(ctx, target.ID, content, t.inverseFor(target).sets...)
+	restored := 0
+	for _, hit := range stats.Rules {
+		if hit.Kind != guardKind {
+			restored += hit.Count
+		}
+	}
+	return out, restored, err
+}
+
+// isPristine 判断内容是否未经汉化 (没有任何反向规则命中)
+func (t *Translator) isPristine(ctx context.Context, target *targets.Target, content []byte) bool {
+	_, restored, err := t.UntranslateContent(ctx, target, string(content))
+	return err == nil && restored == 0
+}
+
+// translatedBefore 判断文件在本次汉化前是否已经汉化过: 先查原版哈希记录，记录中没有时
+// 按之前汉化时的双语显示设置 (见 appliedBilingual) 用反向规则判断
+func (t *Translator) translatedBefore(ctx context.Context, tf translatedFile) bool {
+	if t.HashesPath != "" {
+		if hashes, err := backup.LoadHashes(t.HashesPath); err == nil {
+			if pristine, ok := hashes.Known(tf.file.Path, backup.HashContent(tf.original)); ok {
+				return !pristine
+			}
+		}
+	}
+	return !t.forContent(ctx, tf.file.Path, tf.original).isPristine(ctx, tf.file.Target, tf.original)
+}
+
+// recordPristine 记录本次汉化前原版文件的哈希 (跳过已经汉化过的文件) 和汉化结果的哈希；
+// 只有哈希不在记录中的文件才需要用反向规则判断是否为原版
+func (t *Translator) recordPristine(ctx context.Context, result *ApplyResult, translated []translatedFile) {
+	if t.HashesPath == "" {
+		return
+	}
+	hashes, err := backup.LoadHashes(t.HashesPath)
+	if err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupRecord, t.HashesPath, "读取原版哈希记录失败: %v", err))
+		return
+	}
+	changed := false
+	for _, tf := range translated {
+		if tf.stats.Hits() == 0 {
+			continue
+		}
+		if _, known := hashes.Known(tf.file.Path, backup.HashContent(tf.original)); !known && t.forContent(ctx, tf.file.Path, tf.original).isPristine(ctx, tf.file.Target, tf.original) {
+			hashes.Record(tf.file.Path, tf.original)
+		}
+		hashes.RecordTranslated(tf.file.Path, []byte(tf.translated))
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := hashes.Save(t.HashesPath); err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupRecord, t.HashesPath, "保存原版哈希记录失败: %v", err))
+	}
+}
+
+// Untranslate 用反向规则恢复某个分组在根目录下已汉化的文件
+// dryRun 为 true 时只报告结果，否则先备份当前 (已汉化) 的文件再写回恢复结果，任何文件读取失败时不修改任何文件
+func (t *Translator) Untranslate(ctx context.Context, group, root string, files []targets.File, dryRun bool) *UntranslateResult {
+	result := NewUntranslateResult(group, root)
+	result.DryRun = dryRun
+
+	var hashes *backup.Hashes
+	if t.HashesPath != "" {
+		var err error
+		if hashes, err = backup.LoadHashes(t.HashesPath); err != nil {
+			result.Warnings = append(result.Warnings, NewProblem(CodeReadFailed, t.HashesPath, "读取原版哈希记录失败: %v", err))
+		}
+	}
+
+	// 1. 反向翻译 (只在内存中)
+	type restoredFile struct {
+		original  []byte
+		restored  string
+		bilingual *BilingualOptions // 汉化时的双语显示设置 (见 appliedBilingual)
+	}
+	var contents []restoredFile
+	reported := make(map[string]bool)
+	for _, f := range files {
+		uf := UntranslatedFile{Path: f.Path, Target: f.Target.ID, Remaining: []RemainingLiteral{}}
+		content, err := os.ReadFile(f.Path)
+		if err != nil {
+			p := NewProblem(CodeReadFailed, f.Path, "读取失败: %v", err)
+			uf.Error = &p
+			result.Files = append(result.Files, uf)
+			contents = append(contents, restoredFile{})
+			continue
+		}
+		ft := t.forContent(ctx, f.Path, content)
+		inv := ft.inverseFor(f.Target)
+		if !reported[f.Target.ID] {
+			reported[f.Target.ID] = true
+			result.Irreversible = append(result.Irreversible, inv.irreversible...)
+		}
+		restored, n, err := ft.UntranslateContent(ctx, f.Target, string(content))
+		if err != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeCanceled, root, "已取消，未修改任何文件"))
+			return result
+		}
+		uf.Restored = n
+		uf.Remaining = remainingLiterals([]byte(restored), inv.irreversible)
+		if fh, ok := hashes.Lookup(f.Path); ok {
+			uf.Verified = "match"
+			if backup.HashContent([]byte(restored)) != fh.SHA256 {
+				uf.Verified = "mismatch"
+				result.Warnings = append(result.Warnings, NewProblem(CodeHashMismatch, f.Path, "恢复结果与 %s 记录的原版哈希不一致 (可能有无法反向的译文，或原版已经更新)", fh.Timestamp))
+			}
+		}
+		result.Files = append(result.Files, uf)
+		contents = append(contents, restoredFile{original: content, restored: restored, bilingual: ft.Bilingual})
+	}
+	for _, uf := range result.Files {
+		if uf.Error != nil {
+			result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "部分文件读取失败，未修改任何文件"))
+			return result
+		}
+	}
+	if dryRun {
+		return result
+	}
+
+	// 2. 备份当前内容 (可用 restore 撤销本次恢复)
+	var changed []int
+	for i, uf := range result.Files {
+		if uf.Restored > 0 {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return result
+	}
+	if t.Backups.Root == "" {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "未设置备份目录"))
+		return result
+	}
+	b, err := t.Backups.Create(group, root)
+	if err != nil {
+		result.Errors = append(result.Errors, NewProblem(CodeBackupDirFailed, "", "创建备份目录失败: %v", err))
+		return result
+	}
+	for _, i := range changed {
+		name, err := b.AddContent(result.Files[i].Path, contents[i].original)
+		if err != nil {
+			b.Discard()
+			p := NewProblem(CodeBackupFailed, result.Files[i].Path, "备份失败: %v", err)
+			result.Files[i].Error = &p
+			result.Errors = append(result.Errors, NewProblem(CodeNotApplied, root, "备份失败，未修改任何文件"))
+			return result
+		}
+		result.Files[i].Backup = name
+	}
+	b.Record.Bilingual = contents[changed[0]].bilingual.record() // 用 restore 撤销本次恢复后仍按这个设置匹配译文
+	if err := b.Save(); err != nil {
+		result.Warnings = append(result.Warnings, NewProblem(CodeBackupRecord, b.Dir, "保存备份记录失败: %v", err))
+	}
+	result.BackupID, result.BackupDir = b.ID, b.Dir
+
+	// 3. 写回
+	for _, i := range changed {
+		if err := WriteFileAtomic(result.Files[i].Path, []byte(contents[i].restored)); err != nil {
+			p := NewProblem(CodeWriteFailed, result.Files[i].Path, "保存失败: %v", err)
+			result.Files[i].Error = &p
+			result.Errors = append(result.Errors, NewProblem(CodeWriteFailed, b.Dir, "写回失败，可使用备份 %s 还原已写入的文件", b.ID))
+			return result
+		}
+		result.Files[i].Written = true
+		RemoveTrace(result.Files[i].Path)
+	}
+	return result
+}
+
+// remainingLiterals 返回仍含中文的字符串字面量 (按首次出现的顺序去重)，
+// 并从无法反向的规则中找出译文出现在字面量里的原文作为候选
+func remainingLiterals(content []byte, irreversible []IrreversibleRule) []RemainingLiteral {
+	remaining := []RemainingLiteral{}
+	index := make(map[string]int)
+	line, lineOffset := 1, 0
+	for _, lit := range literals.Extract(content) {
+		if !literals.HasCJK(lit.Text()) {
+			continue
+		}
+		line += bytes.Count(content[lineOffset:lit.Start], []byte("\n"))
+		lineOffset = lit.Start
+		if i, ok := index[lit.Raw]; ok {
+			remaining[i].Count++
+			continue
+		}
+		r := RemainingLiteral{Text: lit.Raw, Line: line, Count: 1}
+		for _, ir := range irreversible {
+			if !producedBy(lit, ir.To) {
+				continue
+			}
+			for _, from := range ir.From {
+				if !containsText(r.Candidates, from) {
+					r.Candidates = append(r.Candidates, from)
+				}
+			}
+		}
+		index[lit.Raw] = len(remaining)
+		remaining = append(remaining, r)
+	}
+	return remaining
+}
+
+// toSegments 分隔译文中占位符 (${$1、${*)、正则展开 ($1、${name}、$$) 的模式，分隔出的各段是译文中原样输出的文本
+var toSegments = regexp.MustCompile(`\$\{\$\d+|\$\{\*|\$\$|\$\{\w+\}|\$\w+`)
+
+// producedBy 判断字面量是否可能含有规则的译文: 整个字面量的译文要求字面量文本相同，
+// 其他译文要求原样输出的各段依次出现在字面量中
+func producedBy(lit literals.Literal, to string) bool {
+	if text, _, ok := literals.Unquote(to); ok && !strings.Contains(text, "${") {
+		return lit.Text() == text
+	}
+	raw := lit.Raw
+	for _, seg := range toSegments.Split(to, -1) {
+		i := strings.Index(raw, seg)
+		if i < 0 {
+			return false
+		}
+		raw = raw[i+len(seg):]
+	}
+	return true
+}
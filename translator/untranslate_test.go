@@ -0,0 +1,129 @@
+package translator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"antigravity_translator/backup"
+	"antigravity_translator/engine"
+	"antigravity_translator/literals"
+	"antigravity_translator/targets"
+)
+
+// roundTripSample 从目标的规则包中取 n 条原文和译文都是完整字符串字面量、译文不与其他规则重复的规则，拼成一段 JS
+func roundTripSample(target *targets.Target, n int) string {
+	owners := make(map[string]int)
+	var candidates []engine.Rule
+	for _, set := range target.RuleSets() {
+		for _, phase := range set.Phases {
+			for _, rule := range phase.Rules() {
+				owners[rule.To]++
+				if phase.Kind != "normal" || rule.From == rule.To || !rule.Scope.AppliesTo(target.ID) {
+					continue
+				}
+				if _, _, ok := literals.Unquote(rule.From); !ok {
+					continue
+				}
+				if _, _, ok := literals.Unquote(rule.To); !ok {
+					continue
+				}
+				candidates = append(candidates, rule)
+			}
+		}
+	}
+	var b strings.Builder
+	for _, rule := range candidates {
+		if n == 0 {
+			break
+		}
+		if owners[rule.To] != 1 {
+			continue
+		}
+		b.WriteString("var s=" + rule.From + ";\n")
+		n--
+	}
+	return b.String()
+}
+
+// TestUntranslateBilingual 双语显示汉化后的内容可以用相同的设置完整恢复
+func TestUntranslateBilingual(t *testing.T) {
+	ctx := context.Background()
+	target := targets.Lookup("antigravity.main")
+	original := roundTripSample(target, 20)
+	for _, style := range engine.BilingualStyles {
+		t.Run(style, func(t *testing.T) {
+			tr := &Translator{Bilingual: &BilingualOptions{Style: style}}
+			translated, stats, err := tr.TranslateContent(ctx, target, original)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stats.Hits() == 0 {
+				t.Fatal("样例没有命中任何规则")
+			}
+			restored, _, err := tr.UntranslateContent(ctx, target, translated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if restored != original {
+				t.Errorf("恢复结果与原文不同:\n%s\nwant:\n%s", restored, original)
+			}
+			if !tr.isPristine(ctx, target, []byte(original)) {
+				t.Error("原文被判断为已经汉化")
+			}
+			if tr.isPristine(ctx, target, []byte(translated)) {
+				t.Error("双语译文被判断为原版")
+			}
+		})
+	}
+}
+
+// TestBilingualRecorded 汉化时的双语显示设置记入替换记录和备份记录，之后的命令不使用当前设置也能匹配译文
+func TestBilingualRecorded(t *testing.T) {
+	ctx := context.Background()
+	target := targets.Lookup("antigravity.main")
+	original := roundTripSample(target, 20)
+	for _, tt := range []struct {
+		name  string
+		trace bool // 按替换记录 (否则按备份记录) 取回设置
+	}{{"替换记录", true}, {"备份记录", false}} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "main.js")
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+			files := []targets.File{{Path: path, Target: target}}
+			applied := &Translator{Backups: backup.Store{Root: filepath.Join(dir, "apply")}, Trace: tt.trace, Bilingual: &BilingualOptions{Style: engine.BilingualParen}}
+			if result := applied.Apply(ctx, "antigravity", dir, files); len(result.Errors) > 0 {
+				t.Fatalf("汉化失败: %v", result.Errors)
+			}
+			translated, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// 当前设置只显示译文；按替换记录取回时不提供备份
+			root := applied.Backups.Root
+			if tt.trace {
+				root = filepath.Join(dir, "other")
+			}
+			tr := &Translator{Backups: backup.Store{Root: root}}
+			if !tr.translatedBefore(ctx, translatedFile{file: files[0], original: translated}) {
+				t.Error("双语汉化过的文件没有被判断为已经汉化")
+			}
+			if result := tr.Untranslate(ctx, "antigravity", dir, files, false); len(result.Errors) > 0 {
+				t.Fatalf("恢复失败: %v", result.Errors)
+			}
+			restored, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(restored) != original {
+				t.Errorf("恢复结果与原文不同:\n%s\nwant:\n%s", restored, original)
+			}
+		})
+	}
+}